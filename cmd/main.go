@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"forge/internal/config"
 	"forge/internal/database"
+	"forge/internal/doctor"
 	"forge/internal/hooks"
 	"forge/internal/migrations"
 	"forge/internal/plugins"
@@ -82,30 +83,7 @@ Use "forge --help" to see available commands.`,
 		},
 	})
 
-	rootCmd.AddCommand(&cobra.Command{
-		Use:   "env",
-		Short: "Display Forge config environment",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			settings, err := config.CurrentSettings()
-			if err != nil {
-				return err
-			}
-
-			content, err := os.ReadFile(settings.EnvFile)
-			if err != nil {
-				if os.IsNotExist(err) {
-					fmt.Printf("# %s\n", settings.EnvFile)
-					fmt.Printf("%s=%s\n", config.ForgeDBDSNKey, settings.DBDSN)
-					fmt.Printf("%s=%s\n", config.ForgePluginsDirKey, settings.PluginsDir)
-					return nil
-				}
-				return fmt.Errorf("unable to read %s: %v", settings.EnvFile, err)
-			}
-			fmt.Printf("# %s\n", settings.EnvFile)
-			fmt.Println(string(content))
-			return nil
-		},
-	})
+	config.RegisterEnvCommands(rootCmd)
 
 	configCmd := &cobra.Command{
 		Use:   "config",
@@ -155,6 +133,7 @@ Use "forge --help" to see available commands.`,
 	}
 
 	plugins.RegisterManagementCommands(rootCmd, projectDir)
+	doctor.RegisterCommands(rootCmd, projectDir)
 
 	pm, err := plugins.NewManager(projectDir)
 	if err != nil {
@@ -165,7 +144,7 @@ Use "forge --help" to see available commands.`,
 	}
 
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
@@ -3,7 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/joho/godotenv"
@@ -20,6 +22,17 @@ const (
 	ForgePluginsDirKey    = "FORGE_PLUGINS_DIR"
 	ForgeModelsDirKey     = "FORGE_MODELS_DIR"
 	ForgeModelsPackageKey = "FORGE_MODELS_PACKAGE"
+
+	ForgeEnvKey           = "FORGE_ENV"
+	ForgeProtectedKey     = "FORGE_PROTECTED"
+	ForgeProtectedEnvsKey = "FORGE_PROTECTED_ENVS"
+	ForgeProtectedDSNsKey = "FORGE_PROTECTED_DSNS"
+	ForgeBackupKey        = "FORGE_BACKUP_BEFORE_DESTRUCTIVE"
+	ForgeBackupDirKey     = "FORGE_BACKUP_DIR"
+	ForgeDBSchemasKey     = "FORGE_DB_SCHEMAS"
+	ForgeDBSearchPathKey  = "FORGE_DB_SEARCH_PATH"
+	DefaultProtectedEnvs  = "production,prod"
+	DefaultBackupDir      = "database/backups"
 )
 
 type Settings struct {
@@ -28,6 +41,25 @@ type Settings struct {
 	PluginsDir    string
 	ModelsDir     string
 	ModelsPackage string
+
+	// Env is the profile name (FORGE_ENV), e.g. "dev" or "production".
+	Env string
+	// Protected is set when destructive commands must not run unattended
+	// against this database; ProtectedReason says why.
+	Protected       bool
+	ProtectedReason string
+	// BackupBeforeDestructive makes `db fresh/reset/refresh` write a backup
+	// into BackupDir first.
+	BackupBeforeDestructive bool
+	BackupDir               string
+
+	// Schemas are the postgres schemas or mysql databases the schema:*
+	// commands introspect by default (FORGE_DB_SCHEMAS); empty means the
+	// current one.
+	Schemas []string
+	// SearchPath is the postgres search_path of every connection
+	// (FORGE_DB_SEARCH_PATH), so migrations can target another schema.
+	SearchPath string
 }
 
 func LoadEnv() error {
@@ -88,13 +120,102 @@ func CurrentSettings() (Settings, error) {
 		modelsPackage = DefaultModelsPackage
 	}
 
-	return Settings{
-		EnvFile:       envFile,
-		DBDSN:         dsn,
-		PluginsDir:    pluginsDir,
-		ModelsDir:     modelsDir,
-		ModelsPackage: modelsPackage,
-	}, nil
+	backupDir := strings.TrimSpace(os.Getenv(ForgeBackupDirKey))
+	if backupDir == "" {
+		backupDir = DefaultBackupDir
+	}
+
+	s := Settings{
+		EnvFile:                 envFile,
+		DBDSN:                   dsn,
+		PluginsDir:              pluginsDir,
+		ModelsDir:               modelsDir,
+		ModelsPackage:           modelsPackage,
+		Env:                     strings.TrimSpace(os.Getenv(ForgeEnvKey)),
+		BackupBeforeDestructive: envBool(ForgeBackupKey),
+		BackupDir:               backupDir,
+		Schemas:                 splitList(os.Getenv(ForgeDBSchemasKey)),
+		SearchPath:              strings.TrimSpace(os.Getenv(ForgeDBSearchPathKey)),
+	}
+	s.Protected, s.ProtectedReason = protection(s)
+	return s, nil
+}
+
+// WithDSN returns the settings for another database, e.g. the target of
+// `db copy`. Protection is re-evaluated: only FORGE_PROTECTED_DSNS applies to a
+// DSN other than the configured one, since FORGE_ENV and FORGE_PROTECTED
+// describe the configured database.
+func (s Settings) WithDSN(dsn string) Settings {
+	if dsn == s.DBDSN {
+		return s
+	}
+	s.DBDSN = dsn
+	s.Protected, s.ProtectedReason = dsnProtection(dsn)
+	return s
+}
+
+// protection decides whether the settings point at a protected database:
+// FORGE_PROTECTED=true, a FORGE_ENV listed in FORGE_PROTECTED_ENVS, or a DSN
+// matching one of the FORGE_PROTECTED_DSNS patterns.
+func protection(s Settings) (bool, string) {
+	if envBool(ForgeProtectedKey) {
+		return true, ForgeProtectedKey + " is set"
+	}
+
+	envs, ok := os.LookupEnv(ForgeProtectedEnvsKey)
+	if !ok {
+		envs = DefaultProtectedEnvs
+	}
+	if s.Env != "" {
+		for _, e := range splitList(envs) {
+			if strings.EqualFold(e, s.Env) {
+				return true, fmt.Sprintf("%s=%s is listed in %s", ForgeEnvKey, s.Env, ForgeProtectedEnvsKey)
+			}
+		}
+	}
+
+	return dsnProtection(s.DBDSN)
+}
+
+func dsnProtection(dsn string) (bool, string) {
+	for _, pattern := range splitList(os.Getenv(ForgeProtectedDSNsKey)) {
+		if MatchDSN(pattern, dsn) {
+			return true, fmt.Sprintf("DSN matches %s pattern %q", ForgeProtectedDSNsKey, pattern)
+		}
+	}
+	return false, ""
+}
+
+// MatchDSN reports whether a protection pattern matches a DSN. The pattern is
+// a shell glob (path.Match) tried against the DSN with its password removed,
+// the host and the database name, so "*.prod.internal", "app_production" and
+// "postgres://*@db.prod:5432/*" all work.
+func MatchDSN(pattern, dsn string) bool {
+	candidates := []string{RedactDSN(dsn), ParseDSN(dsn).Host, DatabaseName(dsn)}
+	for _, c := range candidates {
+		if c == "" {
+			continue
+		}
+		if ok, _ := path.Match(pattern, c); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func envBool(key string) bool {
+	v, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(key)))
+	return err == nil && v
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
 }
 
 func DefaultEnvLines() []string {
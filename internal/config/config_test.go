@@ -77,3 +77,35 @@ func TestResolvePluginsDir(t *testing.T) {
 		t.Fatalf("plugins dir = %q, want %q", got, want)
 	}
 }
+
+func TestProtection(t *testing.T) {
+	t.Setenv(ForgeProtectedKey, "")
+	t.Setenv(ForgeProtectedDSNsKey, "")
+	t.Setenv(ForgeEnvKey, "")
+
+	cases := []struct {
+		name, env, dsns, dsn string
+		want                 bool
+	}{
+		{"dev", "dev", "", "sqlite://dev.db", false},
+		{"production env", "production", "", "sqlite://dev.db", true},
+		{"host glob", "", "*.prod.internal", "postgres://u:p@db.prod.internal:5432/app", true},
+		{"db name", "", "staging, app_production", "mysql://u:p@db:3306/app_production", true},
+		{"sqlite path", "", "/srv/*.db", "sqlite:///srv/live.db", true},
+		{"no match", "", "*.prod.internal", "postgres://u:p@localhost:5432/app", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(ForgeProtectedDSNsKey, tc.dsns)
+			got, reason := protection(Settings{Env: tc.env, DBDSN: tc.dsn})
+			if got != tc.want {
+				t.Fatalf("protected = %v (%s), want %v", got, reason, tc.want)
+			}
+		})
+	}
+
+	t.Setenv(ForgeProtectedKey, "true")
+	if ok, _ := protection(Settings{DBDSN: "sqlite://dev.db"}); !ok {
+		t.Fatalf("expected %s to protect", ForgeProtectedKey)
+	}
+}
@@ -62,6 +62,28 @@ func TestUpdateEnvFile(t *testing.T) {
 	if strings.Contains(s, "FORGE_DB_DSN=old") {
 		t.Fatalf("old value not removed:\n%s", s)
 	}
+
+	// export lines are updated in place, and values read back as written.
+	if err := os.WriteFile(path, []byte("export APP_NAME=old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]string{"APP_NAME": "My App # 1", "APP_PASS": `it's $HOME\n`, "APP_PLAIN": "x"}
+	if err := UpdateEnvFile(path, values); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	out, _ = os.ReadFile(path)
+	if n := strings.Count(string(out), "APP_NAME="); n != 1 || !strings.HasPrefix(string(out), "export APP_NAME=") {
+		t.Fatalf("export line not updated in place:\n%s", out)
+	}
+	entries, err := ReadEnvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Value != values[e.Key] {
+			t.Fatalf("%s read back as %q, want %q:\n%s", e.Key, e.Value, values[e.Key], out)
+		}
+	}
 }
 
 func TestRunWizardPiped(t *testing.T) {
@@ -107,3 +107,30 @@ func ParseDSN(dsn string) DSNParts {
 		return DSNParts{Driver: "sqlite", SQLitePath: DefaultSQLiteDBPath}
 	}
 }
+
+// DatabaseName returns the name users know a database by: the database name for
+// postgres/mysql and the file path for sqlite.
+func DatabaseName(dsn string) string {
+	if !strings.Contains(dsn, "://") {
+		// A bare path is a sqlite file.
+		return strings.TrimSpace(dsn)
+	}
+	p := ParseDSN(dsn)
+	if p.Driver == "sqlite" {
+		return p.SQLitePath
+	}
+	return p.DBName
+}
+
+// RedactDSN masks the password of a URL-style DSN so it can be printed.
+func RedactDSN(dsn string) string {
+	u, err := url.Parse(strings.TrimSpace(dsn))
+	if err != nil || u.User == nil {
+		return dsn
+	}
+	if _, ok := u.User.Password(); !ok {
+		return dsn
+	}
+	u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	return u.String()
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultEnvExampleFile is the committed schema that `forge env check`
+// validates the real env file against.
+const DefaultEnvExampleFile = ".env.forge.example"
+
+// knownKeyTypes are the value types of Forge's own keys, used when the example
+// file does not annotate them explicitly.
+var knownKeyTypes = map[string]string{
+	ForgeDBDSNKey:      "dsn",
+	ForgePluginsDirKey: "dir",
+	ForgeModelsDirKey:  "dir",
+}
+
+// EnvKeySpec describes one key declared in the example file.
+type EnvKeySpec struct {
+	Key      string
+	Type     string // string | int | bool | url | dsn | dir | file
+	Optional bool
+}
+
+// EnvIssue is a single finding of CheckEnvFile.
+type EnvIssue struct {
+	Key     string
+	Level   string // error | warning
+	Message string
+}
+
+// ParseEnvExample reads the key specs of an example env file. A key's type and
+// optionality come from the comment lines directly above it:
+//
+//	# type: int
+//	# optional
+//	APP_PORT=8080
+func ParseEnvExample(path string) ([]EnvKeySpec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var specs []EnvKeySpec
+	pending := EnvKeySpec{}
+	for _, line := range strings.Split(string(b), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			pending = EnvKeySpec{}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			note := strings.TrimSpace(strings.TrimPrefix(trimmed, "#"))
+			switch {
+			case strings.HasPrefix(strings.ToLower(note), "type:"):
+				pending.Type = strings.ToLower(strings.TrimSpace(note[len("type:"):]))
+			case strings.EqualFold(note, "optional"):
+				pending.Optional = true
+			}
+			continue
+		}
+		key, ok := envLineKey(line)
+		if !ok {
+			continue
+		}
+		spec := pending
+		spec.Key = key
+		if spec.Type == "" {
+			spec.Type = knownKeyTypes[key]
+		}
+		if spec.Type == "" {
+			spec.Type = "string"
+		}
+		if !validEnvType(spec.Type) {
+			return nil, fmt.Errorf("%s: key %s has unknown type %q", path, key, spec.Type)
+		}
+		specs = append(specs, spec)
+		pending = EnvKeySpec{}
+	}
+	return specs, nil
+}
+
+// CheckEnvFile compares envPath against the example file: keys missing from the
+// env file are errors, keys the example does not declare are warnings, and
+// every present value is validated against its declared type.
+func CheckEnvFile(envPath, examplePath string) ([]EnvIssue, error) {
+	specs, err := ParseEnvExample(examplePath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ReadEnvFile(envPath)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for _, e := range entries {
+		values[e.Key] = e.Value
+	}
+
+	var issues []EnvIssue
+	declared := map[string]bool{}
+	for _, spec := range specs {
+		declared[spec.Key] = true
+		v, ok := values[spec.Key]
+		if !ok || strings.TrimSpace(v) == "" {
+			if !spec.Optional {
+				issues = append(issues, EnvIssue{spec.Key, "error", "missing (declared in " + examplePath + ")"})
+			}
+			continue
+		}
+		if level, msg := validateEnvValue(spec.Type, v); msg != "" {
+			issues = append(issues, EnvIssue{spec.Key, level, msg})
+		}
+	}
+	for _, e := range entries {
+		if !declared[e.Key] {
+			issues = append(issues, EnvIssue{e.Key, "warning", "not declared in " + examplePath})
+		}
+	}
+	return issues, nil
+}
+
+// EnvDiffEntry is one key that differs between two env files.
+type EnvDiffEntry struct {
+	Key    string
+	Change string // added | removed | changed
+	Old    string
+	New    string
+}
+
+// DiffEnvFiles compares two env files key by key (a -> b), sorted by key.
+func DiffEnvFiles(a, b string) ([]EnvDiffEntry, error) {
+	left, err := ReadEnvFile(a)
+	if err != nil {
+		return nil, err
+	}
+	right, err := ReadEnvFile(b)
+	if err != nil {
+		return nil, err
+	}
+	lv := map[string]string{}
+	for _, e := range left {
+		lv[e.Key] = e.Value
+	}
+	rv := map[string]string{}
+	for _, e := range right {
+		rv[e.Key] = e.Value
+	}
+
+	var out []EnvDiffEntry
+	for k, nv := range rv {
+		ov, ok := lv[k]
+		switch {
+		case !ok:
+			out = append(out, EnvDiffEntry{Key: k, Change: "added", New: nv})
+		case ov != nv:
+			out = append(out, EnvDiffEntry{Key: k, Change: "changed", Old: ov, New: nv})
+		}
+	}
+	for k, ov := range lv {
+		if _, ok := rv[k]; !ok {
+			out = append(out, EnvDiffEntry{Key: k, Change: "removed", Old: ov})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+// ValidateDSN reports whether a Forge DSN can be opened, without connecting.
+// It follows the connection code (database.Connect): postgres URLs go to the
+// driver as written, so socket and default-host DSNs such as postgres:///app
+// pass; mysql URLs need a host, a database name and a username; anything else
+// is a sqlite path.
+func ValidateDSN(dsn string) error {
+	dsn = strings.TrimSpace(dsn)
+	lower := strings.ToLower(dsn)
+	switch {
+	case dsn == "":
+		return fmt.Errorf("DSN is empty")
+	case strings.HasPrefix(lower, "sqlite://"):
+		if strings.TrimSpace(dsn[len("sqlite://"):]) == "" {
+			return fmt.Errorf("sqlite DSN has an empty path")
+		}
+	case strings.HasPrefix(lower, "postgres://"), strings.HasPrefix(lower, "postgresql://"):
+		if _, err := url.Parse(dsn); err != nil {
+			return fmt.Errorf("invalid DSN: %w", err)
+		}
+	case strings.HasPrefix(lower, "mysql://"):
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return fmt.Errorf("invalid DSN: %w", err)
+		}
+		if strings.TrimSpace(u.Host) == "" || strings.TrimPrefix(u.EscapedPath(), "/") == "" {
+			return fmt.Errorf("mysql DSN must include a host and a database name")
+		}
+		if u.User == nil || u.User.Username() == "" {
+			return fmt.Errorf("mysql DSN must include a username")
+		}
+	}
+	return nil
+}
+
+func validEnvType(t string) bool {
+	switch t {
+	case "string", "int", "bool", "url", "dsn", "dir", "file":
+		return true
+	}
+	return false
+}
+
+// validateEnvValue checks a value against its type. It returns an empty
+// message when the value is fine; a path that does not exist yet is only a
+// warning since Forge creates most directories on demand.
+func validateEnvValue(typ, v string) (string, string) {
+	v = strings.TrimSpace(v)
+	switch typ {
+	case "int":
+		if _, err := strconv.Atoi(v); err != nil {
+			return "error", fmt.Sprintf("%q is not an integer", v)
+		}
+	case "bool":
+		if _, err := strconv.ParseBool(v); err != nil {
+			return "error", fmt.Sprintf("%q is not a boolean", v)
+		}
+	case "url":
+		u, err := url.Parse(v)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "error", fmt.Sprintf("%q is not an absolute URL", v)
+		}
+	case "dsn":
+		if err := ValidateDSN(v); err != nil {
+			return "error", err.Error()
+		}
+	case "dir":
+		info, err := os.Stat(v)
+		if err != nil {
+			return "warning", fmt.Sprintf("directory %s does not exist", v)
+		}
+		if !info.IsDir() {
+			return "error", fmt.Sprintf("%s is not a directory", v)
+		}
+	case "file":
+		info, err := os.Stat(v)
+		if err != nil {
+			return "warning", fmt.Sprintf("file %s does not exist", v)
+		}
+		if info.IsDir() {
+			return "error", fmt.Sprintf("%s is a directory", v)
+		}
+	}
+	return "", ""
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"sort"
 	"strings"
+
+	"github.com/joho/godotenv"
 )
 
 // UpdateEnvFile writes the given key/value pairs into the env file, replacing
@@ -24,17 +26,16 @@ func UpdateEnvFile(path string, kv map[string]string) error {
 
 	seen := map[string]bool{}
 	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
-			continue
-		}
-		eq := strings.IndexByte(trimmed, '=')
-		if eq < 0 {
+		key, ok := envLineKey(line)
+		if !ok {
 			continue
 		}
-		key := strings.TrimSpace(trimmed[:eq])
 		if val, ok := kv[key]; ok {
-			lines[i] = key + "=" + val
+			prefix := ""
+			if strings.HasPrefix(strings.TrimSpace(line), "export ") {
+				prefix = "export "
+			}
+			lines[i] = prefix + key + "=" + quoteEnvValue(val)
 			seen[key] = true
 		}
 	}
@@ -48,7 +49,7 @@ func UpdateEnvFile(path string, kv map[string]string) error {
 	}
 	sort.Strings(missing)
 	for _, k := range missing {
-		lines = append(lines, k+"="+kv[k])
+		lines = append(lines, k+"="+quoteEnvValue(kv[k]))
 	}
 
 	out := strings.Join(lines, "\n") + "\n"
@@ -58,6 +59,21 @@ func UpdateEnvFile(path string, kv map[string]string) error {
 	return nil
 }
 
+// quoteEnvValue quotes v when LoadEnv would not read it back as written:
+// blanks, "#", quotes, "$" (variable expansion) or backslashes. Single quotes
+// keep it literal; a value with a single quote or a line break gets double
+// quotes and escapes.
+func quoteEnvValue(v string) string {
+	if !strings.ContainsAny(v, " \t#'\"$\\\n\r") {
+		return v
+	}
+	if !strings.ContainsAny(v, "'\n\r") {
+		return "'" + v + "'"
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`)
+	return `"` + r.Replace(v) + `"`
+}
+
 // ReadEnvFileValue reads a single key directly from an env file without
 // touching the process environment (used for wizard pre-fill).
 func ReadEnvFileValue(path, key string) string {
@@ -80,3 +96,93 @@ func ReadEnvFileValue(path, key string) string {
 	}
 	return ""
 }
+
+// RemoveEnvFileKeys deletes the given keys from the env file, preserving
+// comments, blank lines and every other key. It reports which keys were
+// actually present.
+func RemoveEnvFileKeys(path string, keys ...string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	drop := map[string]bool{}
+	for _, k := range keys {
+		drop[strings.TrimSpace(k)] = true
+	}
+
+	lines := strings.Split(string(b), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var kept []string
+	var removed []string
+	for _, line := range lines {
+		if key, ok := envLineKey(line); ok && drop[key] {
+			removed = append(removed, key)
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	out := strings.Join(kept, "\n")
+	if len(kept) > 0 {
+		out += "\n"
+	}
+	if err := os.WriteFile(path, []byte(out), 0o644); err != nil {
+		return nil, fmt.Errorf("write %s: %w", path, err)
+	}
+	return removed, nil
+}
+
+// EnvEntry is one KEY=VALUE assignment read from an env file.
+type EnvEntry struct {
+	Key   string
+	Value string
+}
+
+// ReadEnvFile returns the assignments of an env file in file order. Values are
+// unquoted the same way LoadEnv sees them.
+func ReadEnvFile(path string) ([]EnvEntry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values, err := godotenv.Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	seen := map[string]bool{}
+	var entries []EnvEntry
+	for _, line := range strings.Split(string(b), "\n") {
+		key, ok := envLineKey(line)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		entries = append(entries, EnvEntry{Key: key, Value: values[key]})
+	}
+	return entries, nil
+}
+
+// envLineKey extracts the key of a KEY=VALUE line (an optional `export `
+// prefix is allowed). Comments and blank lines yield ok=false.
+func envLineKey(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return "", false
+	}
+	eq := strings.IndexByte(trimmed, '=')
+	if eq < 0 {
+		return "", false
+	}
+	key := strings.TrimSpace(strings.TrimPrefix(trimmed[:eq], "export "))
+	if key == "" {
+		return "", false
+	}
+	return key, true
+}
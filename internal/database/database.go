@@ -49,13 +49,31 @@ func Connect(forgeDSN string) (*gorm.DB, error) {
 	return db, nil
 }
 
+// SettingsDSN is the Forge DSN of the configured database, with
+// FORGE_DB_SEARCH_PATH applied.
+func SettingsDSN(s config.Settings) (string, error) {
+	if s.SearchPath == "" {
+		return s.DBDSN, nil
+	}
+	return WithSearchPath(s.DBDSN, s.SearchPath)
+}
+
+// ConnectSettings opens the configured database without running migrations.
+func ConnectSettings(s config.Settings) (*gorm.DB, error) {
+	dsn, err := SettingsDSN(s)
+	if err != nil {
+		return nil, err
+	}
+	return Connect(dsn)
+}
+
 func InitDB() (*gorm.DB, error) {
 	settings, err := config.CurrentSettings()
 	if err != nil {
 		return nil, err
 	}
 
-	DB, err = Connect(settings.DBDSN)
+	DB, err = ConnectSettings(settings)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +90,52 @@ func InitDB() (*gorm.DB, error) {
 	return DB, nil
 }
 
+// WithSearchPath sets the search_path of a postgres Forge DSN, for every
+// connection of the pool. Tables created without a schema, Forge's migrations
+// table included, go to the first schema of path.
+func WithSearchPath(forgeDSN, path string) (string, error) {
+	driver, _, err := parseForgeDBDSN(forgeDSN)
+	if err != nil {
+		return "", err
+	}
+	if driver != "postgres" {
+		return "", fmt.Errorf("search_path is only supported on postgres, not %s", driver)
+	}
+	u, err := url.Parse(strings.TrimSpace(forgeDSN))
+	if err != nil {
+		return "", fmt.Errorf("invalid postgres FORGE_DB_DSN: %w", err)
+	}
+	query := u.Query()
+	query.Set("search_path", path)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// WithDatabase points a postgres or mysql Forge DSN at another database on
+// the same server.
+func WithDatabase(forgeDSN, name string) (string, error) {
+	driver, _, err := parseForgeDBDSN(forgeDSN)
+	if err != nil {
+		return "", err
+	}
+	if driver == "sqlite" {
+		return "", fmt.Errorf("sqlite has no databases to switch to")
+	}
+	u, err := url.Parse(strings.TrimSpace(forgeDSN))
+	if err != nil {
+		return "", fmt.Errorf("invalid %s FORGE_DB_DSN: %w", driver, err)
+	}
+	u.Path = "/" + name
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// DriverDSN splits a Forge DSN into the driver name (sqlite, mysql, postgres)
+// and the DSN that driver expects.
+func DriverDSN(forgeDSN string) (string, string, error) {
+	return parseForgeDBDSN(forgeDSN)
+}
+
 func parseForgeDBDSN(raw string) (string, string, error) {
 	dsn := strings.TrimSpace(raw)
 	if dsn == "" {
@@ -0,0 +1,157 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"forge/internal/config"
+	"forge/internal/migrations"
+	"forge/internal/plugins"
+	"forge/internal/seeders"
+)
+
+func init() {
+	Register(Check{Name: "config", Run: checkConfig})
+	Register(Check{Name: "database", Run: checkDatabase})
+	Register(Check{Name: "migrations", Run: checkMigrations})
+	Register(Check{Name: "stubs", Run: checkStubs})
+	Register(Check{Name: "seeds", Run: checkSeeds})
+	Register(Check{Name: "plugins", Run: checkPlugins})
+	Register(Check{Name: "tools", Run: checkTools})
+}
+
+func checkConfig(env *Env) []Result {
+	if env.SettingsErr != nil {
+		return []Result{fail("cannot load settings: "+oneLine(env.SettingsErr), "fix the syntax of .env.forge / .env")}
+	}
+	var out []Result
+	if _, err := os.Stat(env.Settings.EnvFile); err != nil {
+		out = append(out, warn(env.Settings.EnvFile+" not found, using defaults", "run `forge init` or `forge config`"))
+	} else {
+		out = append(out, pass("settings loaded from "+env.Settings.EnvFile))
+	}
+	if err := config.ValidateDSN(env.Settings.DBDSN); err != nil {
+		out = append(out, fail(config.ForgeDBDSNKey+": "+err.Error(), "run `forge config` to rebuild the DSN"))
+	}
+	if _, err := os.Stat(config.DefaultEnvExampleFile); err == nil {
+		issues, err := config.CheckEnvFile(env.Settings.EnvFile, config.DefaultEnvExampleFile)
+		switch {
+		case err != nil:
+			out = append(out, warn("cannot check against "+config.DefaultEnvExampleFile+": "+oneLine(err), ""))
+		case len(issues) > 0:
+			out = append(out, warn(fmt.Sprintf("%d issue(s) against %s", len(issues), config.DefaultEnvExampleFile), "run `forge env check` for details"))
+		}
+	}
+	return out
+}
+
+func checkDatabase(env *Env) []Result {
+	db, err := env.DB()
+	if err != nil {
+		return []Result{fail("cannot connect: "+oneLine(err), "check "+config.ForgeDBDSNKey+" and that the database server is running")}
+	}
+	return []Result{pass("connected (" + db.Dialector.Name() + ")")}
+}
+
+func checkMigrations(env *Env) []Result {
+	db, err := env.DB()
+	if err != nil {
+		return []Result{warn("skipped: database unreachable", "")}
+	}
+	if !db.Migrator().HasTable(&migrations.Migration{}) {
+		return []Result{warn("migrations table does not exist yet", "run `forge db migrate`")}
+	}
+	rows, err := migrations.GetStatus(db)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Result{warn("no migrations directory", "create one with `forge db make:sql <name>`")}
+		}
+		return []Result{fail(oneLine(err), "")}
+	}
+	pending := 0
+	for _, r := range rows {
+		if !r.Applied {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return []Result{warn(fmt.Sprintf("%d pending migration(s)", pending), "run `forge db migrate`")}
+	}
+	return []Result{pass(fmt.Sprintf("%d migration(s), all applied", len(rows)))}
+}
+
+func checkStubs(env *Env) []Result {
+	problems, err := migrations.CheckStubs()
+	if err != nil {
+		return []Result{fail("cannot read stubs: "+oneLine(err), "")}
+	}
+	if len(problems) == 0 {
+		return []Result{pass("stubs OK")}
+	}
+	var out []Result
+	for _, p := range problems {
+		out = append(out, warn(p, "see `forge db make:sql --help` for the stub format"))
+	}
+	return out
+}
+
+func checkSeeds(env *Env) []Result {
+	count, errs := seeders.CheckFiles()
+	if len(errs) == 0 {
+		return []Result{pass(fmt.Sprintf("%d seed(s) parsed", count))}
+	}
+	var out []Result
+	for _, err := range errs {
+		out = append(out, fail(oneLine(err), "fix the YAML syntax or the seed fields"))
+	}
+	return out
+}
+
+func checkPlugins(env *Env) []Result {
+	found, err := plugins.NewLoader(env.ProjectDir).ScanPlugins()
+	if err != nil {
+		return []Result{fail("cannot scan plugins: "+oneLine(err), "")}
+	}
+	if len(found) == 0 {
+		return []Result{pass("no plugins installed")}
+	}
+	var out []Result
+	for _, p := range found {
+		slug := p.Manifest.Vendor + "/" + p.Manifest.Name
+		stale, err := plugins.NeedsBuild(p)
+		switch {
+		case err != nil:
+			out = append(out, fail(slug+": "+oneLine(err), "check the plugin's \"source\" directory"))
+		case stale:
+			out = append(out, warn(slug+": binary missing or older than its sources", "run `forge plugins build "+slug+"`"))
+		default:
+			if _, err := os.Stat(p.EntryPath()); err != nil {
+				out = append(out, fail(slug+": entry "+p.EntryPath()+" not found", "check \"entry\" in plugin.json"))
+				continue
+			}
+			out = append(out, pass(slug+": ready"))
+		}
+	}
+	return out
+}
+
+// externalTools are binaries Forge shells out to, with the features needing them.
+var externalTools = []struct{ name, usedFor string }{
+	{"go", "building Go plugins and `project create --lang go`"},
+	{"git", "`project create --from` and `project git:add`"},
+	{"node", "node plugins and `project create --lang node|ts`"},
+}
+
+func checkTools(env *Env) []Result {
+	var out []Result
+	for _, t := range externalTools {
+		path, err := exec.LookPath(t.name)
+		if err != nil {
+			out = append(out, warn(t.name+" not found on PATH", "install it if you need "+t.usedFor))
+			continue
+		}
+		out = append(out, pass(t.name+" → "+path))
+	}
+	return out
+}
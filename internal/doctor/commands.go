@@ -0,0 +1,47 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// RegisterCommands attaches `forge doctor` to the root command.
+func RegisterCommands(rootCmd *cobra.Command, projectDir string) {
+	var asJSON, strict bool
+	c := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the project's Forge setup (config, database, migrations, seeds, plugins, tools)",
+		Long: `Run health checks over the Forge setup and print pass/warn/fail with hints.
+
+Exits with code 1 when any check fails (or warns, with --strict), so it can
+guard CI pipelines. Use --json for machine-readable output.`,
+		// The report already explains the failures; the error only sets the
+		// exit code.
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rep := Run(projectDir)
+			var err error
+			if asJSON {
+				err = RenderJSON(os.Stdout, rep)
+			} else {
+				err = RenderText(os.Stdout, rep)
+			}
+			if err != nil {
+				return err
+			}
+			if rep.Fail > 0 {
+				return fmt.Errorf("doctor: %d check(s) failed", rep.Fail)
+			}
+			if strict && rep.Warn > 0 {
+				return fmt.Errorf("doctor: %d check(s) warned (--strict)", rep.Warn)
+			}
+			return nil
+		},
+	}
+	c.Flags().BoolVar(&asJSON, "json", false, "print the report as JSON")
+	c.Flags().BoolVar(&strict, "strict", false, "treat warnings as failures for the exit code")
+	rootCmd.AddCommand(c)
+}
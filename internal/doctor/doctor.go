@@ -0,0 +1,182 @@
+// Package doctor implements `forge doctor`: a pluggable set of health checks
+// over the Forge configuration, the database, migrations, seeds, plugins and
+// the external tools Forge shells out to.
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"forge/internal/config"
+	"forge/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// Status is the outcome of a single check result.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Result is one line of the doctor report. A check may yield several results
+// (e.g. one per plugin).
+type Result struct {
+	Check   string `json:"check"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+// Check is a named health check. Run receives a shared Env so expensive state
+// (settings, the database connection) is resolved once per doctor run.
+type Check struct {
+	Name string
+	Run  func(env *Env) []Result
+}
+
+// Env is the state shared between checks.
+type Env struct {
+	ProjectDir  string
+	Settings    config.Settings
+	SettingsErr error
+
+	dbOnce sync.Once
+	db     *gorm.DB
+	dbErr  error
+}
+
+// DB lazily opens (and pings) the configured database. It does not create
+// Forge's bookkeeping tables, so doctor never modifies the database.
+func (e *Env) DB() (*gorm.DB, error) {
+	e.dbOnce.Do(func() {
+		if e.SettingsErr != nil {
+			e.dbErr = e.SettingsErr
+			return
+		}
+		db, err := database.ConnectSettings(e.Settings)
+		if err != nil {
+			e.dbErr = err
+			return
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			e.dbErr = err
+			return
+		}
+		if err := sqlDB.Ping(); err != nil {
+			e.dbErr = err
+			return
+		}
+		e.db = db
+	})
+	return e.db, e.dbErr
+}
+
+var (
+	mu     sync.RWMutex
+	checks []Check
+)
+
+// Register adds a check to the doctor run. Checks run in registration order.
+func Register(c Check) {
+	if c.Run == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	checks = append(checks, c)
+}
+
+// Report is the outcome of a doctor run.
+type Report struct {
+	Results []Result `json:"results"`
+	Pass    int      `json:"pass"`
+	Warn    int      `json:"warn"`
+	Fail    int      `json:"fail"`
+}
+
+// Run executes every registered check.
+func Run(projectDir string) Report {
+	mu.RLock()
+	cs := append([]Check(nil), checks...)
+	mu.RUnlock()
+
+	env := &Env{ProjectDir: projectDir}
+	env.Settings, env.SettingsErr = config.CurrentSettings()
+
+	var rep Report
+	for _, c := range cs {
+		for _, r := range c.Run(env) {
+			if r.Check == "" {
+				r.Check = c.Name
+			}
+			switch r.Status {
+			case StatusPass:
+				rep.Pass++
+			case StatusWarn:
+				rep.Warn++
+			default:
+				r.Status = StatusFail
+				rep.Fail++
+			}
+			rep.Results = append(rep.Results, r)
+		}
+	}
+	return rep
+}
+
+// RenderText writes a human-readable report.
+func RenderText(w io.Writer, rep Report) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, r := range rep.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", statusMark(r.Status), r.Check, r.Message)
+		if r.Hint != "" && r.Status != StatusPass {
+			fmt.Fprintf(tw, "\t\t→ %s\n", r.Hint)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d passed, %d warning(s), %d failed\n", rep.Pass, rep.Warn, rep.Fail)
+	return err
+}
+
+// RenderJSON writes the report as indented JSON (for CI).
+func RenderJSON(w io.Writer, rep Report) error {
+	if rep.Results == nil {
+		rep.Results = []Result{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
+
+func statusMark(s Status) string {
+	switch s {
+	case StatusPass:
+		return "✓"
+	case StatusWarn:
+		return "!"
+	default:
+		return "✗"
+	}
+}
+
+func pass(msg string) Result { return Result{Status: StatusPass, Message: msg} }
+
+func warn(msg, hint string) Result { return Result{Status: StatusWarn, Message: msg, Hint: hint} }
+
+func fail(msg, hint string) Result { return Result{Status: StatusFail, Message: msg, Hint: hint} }
+
+// oneLine keeps multi-line driver/build errors readable in the table.
+func oneLine(err error) string {
+	return strings.Join(strings.Fields(err.Error()), " ")
+}
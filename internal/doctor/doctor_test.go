@@ -0,0 +1,72 @@
+package doctor
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"forge/internal/config"
+)
+
+func TestRunReportsDatabaseAndPendingMigrations(t *testing.T) {
+	originalWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	tempDir := t.TempDir()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	defer func() {
+		_ = os.Chdir(originalWD)
+	}()
+
+	t.Setenv(config.ForgeDBDSNKey, "sqlite://"+filepath.Join(tempDir, "app.db"))
+	t.Setenv(config.ForgePluginsDirKey, "")
+
+	if err := os.MkdirAll(filepath.Join("database", "migrations"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("database", "migrations", "001_users.sql"),
+		[]byte("-- UP\nCREATE TABLE users (id INTEGER PRIMARY KEY);\n-- DOWN\nDROP TABLE users;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join("database", "stubs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("database", "stubs", "broken.stub.sql"), []byte("CREATE TABLE x ();\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rep := Run(tempDir)
+	byCheck := map[string]Status{}
+	for _, r := range rep.Results {
+		if cur, ok := byCheck[r.Check]; !ok || cur == StatusPass {
+			byCheck[r.Check] = r.Status
+		}
+	}
+	if byCheck["database"] != StatusPass {
+		t.Fatalf("database = %q, results: %+v", byCheck["database"], rep.Results)
+	}
+	// The migrations table does not exist yet — doctor must not create it.
+	if byCheck["migrations"] != StatusWarn {
+		t.Fatalf("migrations = %q, results: %+v", byCheck["migrations"], rep.Results)
+	}
+	if byCheck["stubs"] != StatusWarn {
+		t.Fatalf("stubs = %q, results: %+v", byCheck["stubs"], rep.Results)
+	}
+
+	var buf bytes.Buffer
+	if err := RenderJSON(&buf, rep); err != nil {
+		t.Fatalf("render json: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("json output does not round-trip: %v\n%s", err, buf.String())
+	}
+	if len(decoded.Results) != len(rep.Results) || decoded.Warn != rep.Warn {
+		t.Fatalf("json report mismatch: %+v", decoded)
+	}
+}
@@ -0,0 +1,79 @@
+// Package guard protects destructive commands (`db fresh/reset/refresh`,
+// `seed reset`, ...) from running by accident, especially against databases
+// marked as protected in the Forge settings.
+package guard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"forge/internal/config"
+
+	"golang.org/x/term"
+	"gorm.io/gorm"
+)
+
+// BackupToDir writes a backup of db into dir before a destructive command and
+// returns what it wrote, e.g. the file name. The package implementing backups
+// sets it; while it is nil, destructive commands cannot back up first.
+var BackupToDir func(db *gorm.DB, dir string) (string, error)
+
+// Options configure a confirmation.
+type Options struct {
+	// Force skips the yes/no prompt. It does NOT bypass protection.
+	Force bool
+	// ConfirmDB must equal the database name to run against a protected
+	// database without an interactive prompt (--confirm-db).
+	ConfirmDB string
+}
+
+// Confirm asks before a destructive action and reports whether to proceed.
+//
+// For unprotected databases it is a plain yes/no prompt skipped by Force. For
+// protected ones the user has to type the database name (or pass it as
+// ConfirmDB); without a terminal and without ConfirmDB it refuses with an
+// error.
+func Confirm(s config.Settings, message string, opts Options) (bool, error) {
+	return confirm(os.Stdin, os.Stdout, term.IsTerminal(int(os.Stdin.Fd())), s, message, opts)
+}
+
+func confirm(in io.Reader, out io.Writer, interactive bool, s config.Settings, message string, opts Options) (bool, error) {
+	if !s.Protected {
+		if opts.Force {
+			return true, nil
+		}
+		fmt.Fprintf(out, "%s\nContinue? [y/N]: ", message)
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer == "y" || answer == "yes" {
+			return true, nil
+		}
+		fmt.Fprintln(out, "Aborted.")
+		return false, nil
+	}
+
+	name := config.DatabaseName(s.DBDSN)
+	if opts.ConfirmDB != "" {
+		if opts.ConfirmDB == name {
+			return true, nil
+		}
+		return false, fmt.Errorf("--confirm-db %q does not match the protected database %q", opts.ConfirmDB, name)
+	}
+	if !interactive {
+		return false, fmt.Errorf("refusing to run against protected database %q (%s); pass --confirm-db %s to proceed",
+			name, s.ProtectedReason, name)
+	}
+
+	fmt.Fprintf(out, "%s\n", message)
+	fmt.Fprintf(out, "⚠ %q is a PROTECTED database (%s).\n", name, s.ProtectedReason)
+	fmt.Fprintf(out, "Type the database name to continue: ")
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	if strings.TrimSpace(answer) != name {
+		fmt.Fprintln(out, "Aborted.")
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,46 @@
+package guard
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"forge/internal/config"
+)
+
+func TestConfirmUnprotected(t *testing.T) {
+	s := config.Settings{DBDSN: "sqlite://dev.db"}
+	var out bytes.Buffer
+
+	if ok, err := confirm(strings.NewReader(""), &out, false, s, "drop?", Options{Force: true}); err != nil || !ok {
+		t.Fatalf("force: ok=%v err=%v", ok, err)
+	}
+	if ok, _ := confirm(strings.NewReader("y\n"), &out, true, s, "drop?", Options{}); !ok {
+		t.Fatal("expected yes to confirm")
+	}
+	if ok, _ := confirm(strings.NewReader("\n"), &out, true, s, "drop?", Options{}); ok {
+		t.Fatal("expected empty answer to abort")
+	}
+}
+
+func TestConfirmProtected(t *testing.T) {
+	s := config.Settings{DBDSN: "postgres://u:p@db:5432/app_prod", Protected: true, ProtectedReason: "test"}
+	var out bytes.Buffer
+
+	// --force alone is not enough, and a non-interactive run must refuse.
+	if ok, err := confirm(strings.NewReader("y\n"), &out, false, s, "drop?", Options{Force: true}); err == nil || ok {
+		t.Fatalf("expected refusal, got ok=%v err=%v", ok, err)
+	}
+	if _, err := confirm(strings.NewReader(""), &out, false, s, "drop?", Options{ConfirmDB: "other"}); err == nil {
+		t.Fatal("expected mismatch error")
+	}
+	if ok, err := confirm(strings.NewReader(""), &out, false, s, "drop?", Options{ConfirmDB: "app_prod"}); err != nil || !ok {
+		t.Fatalf("confirm-db: ok=%v err=%v", ok, err)
+	}
+	if ok, _ := confirm(strings.NewReader("y\n"), &out, true, s, "drop?", Options{}); ok {
+		t.Fatal("typing y must not confirm a protected database")
+	}
+	if ok, _ := confirm(strings.NewReader("app_prod\n"), &out, true, s, "drop?", Options{}); !ok {
+		t.Fatal("typing the database name should confirm")
+	}
+}
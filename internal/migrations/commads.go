@@ -8,15 +8,31 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"forge/internal/config"
 	"forge/internal/database"
+	"forge/internal/guard"
 	"forge/internal/schema"
+	"forge/internal/transfer"
 
 	"github.com/spf13/cobra"
+	"gorm.io/gorm"
 )
 
 func RegisterCommands(rootCmd *cobra.Command) {
 
-	migCmd := &cobra.Command{Use: "db", Short: "Database migration & schema commands"}
+	var searchPath string
+	migCmd := &cobra.Command{
+		Use:   "db",
+		Short: "Database migration & schema commands",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Overrides FORGE_DB_SEARCH_PATH for every db command.
+			if cmd.Flags().Changed("search-path") {
+				return os.Setenv(config.ForgeDBSearchPathKey, searchPath)
+			}
+			return nil
+		},
+	}
+	migCmd.PersistentFlags().StringVar(&searchPath, "search-path", "", "postgres search_path for this run, e.g. billing,public (overrides FORGE_DB_SEARCH_PATH)")
 
 	migCmd.AddCommand(
 		makeSQLCmd(),
@@ -29,8 +45,12 @@ func RegisterCommands(rootCmd *cobra.Command) {
 		execCmd(),
 	)
 
-	// schema:dump / schema:show / schema:erd
+	// schema:dump / schema:show / schema:erd; schema:diff builds migration
+	// versions from this package.
+	schema.MigrationSQL = UpSQLThrough
 	schema.Register(migCmd)
+	// backup / restore
+	transfer.Register(migCmd)
 
 	rootCmd.AddCommand(migCmd)
 }
@@ -128,37 +148,31 @@ func rollbackCmd() *cobra.Command {
 }
 
 func resetCmd() *cobra.Command {
-	var force bool
+	var d destructiveFlags
 	c := &cobra.Command{
 		Use:   "reset",
 		Short: "Roll back ALL migrations",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if !confirmDestructive("This will roll back ALL migrations.", force) {
-				return nil
-			}
-			db, err := database.InitDB()
-			if err != nil {
-				return fmt.Errorf("failed to initialize database: %v", err)
+			db, ok, err := d.prepare(cmd, "This will roll back ALL migrations.")
+			if err != nil || !ok {
+				return err
 			}
 			return ResetMigrations(db)
 		},
 	}
-	c.Flags().BoolVar(&force, "force", false, "skip confirmation prompt")
+	d.bind(c)
 	return c
 }
 
 func refreshCmd() *cobra.Command {
-	var force bool
+	var d destructiveFlags
 	c := &cobra.Command{
 		Use:   "refresh",
 		Short: "Roll back ALL migrations and run them again",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if !confirmDestructive("This will roll back ALL migrations and re-apply them.", force) {
-				return nil
-			}
-			db, err := database.InitDB()
-			if err != nil {
-				return fmt.Errorf("failed to initialize database: %v", err)
+			db, ok, err := d.prepare(cmd, "This will roll back ALL migrations and re-apply them.")
+			if err != nil || !ok {
+				return err
 			}
 			if err := ResetMigrations(db); err != nil {
 				return err
@@ -166,22 +180,19 @@ func refreshCmd() *cobra.Command {
 			return RunMigrations(db)
 		},
 	}
-	c.Flags().BoolVar(&force, "force", false, "skip confirmation prompt")
+	d.bind(c)
 	return c
 }
 
 func freshCmd() *cobra.Command {
-	var force bool
+	var d destructiveFlags
 	c := &cobra.Command{
 		Use:   "fresh",
 		Short: "Drop ALL tables and run every migration from scratch",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if !confirmDestructive("This will DROP ALL TABLES and re-run every migration.", force) {
-				return nil
-			}
-			db, err := database.InitDB()
-			if err != nil {
-				return fmt.Errorf("failed to initialize database: %v", err)
+			db, ok, err := d.prepare(cmd, "This will DROP ALL TABLES and re-run every migration.")
+			if err != nil || !ok {
+				return err
 			}
 			if err := schema.DropAllTables(db); err != nil {
 				return fmt.Errorf("drop all tables failed: %w", err)
@@ -190,7 +201,7 @@ func freshCmd() *cobra.Command {
 			return RunMigrations(db)
 		},
 	}
-	c.Flags().BoolVar(&force, "force", false, "skip confirmation prompt")
+	d.bind(c)
 	return c
 }
 
@@ -283,18 +294,48 @@ func readQuery(args []string, file string) (string, error) {
 	return strings.Join(args, " "), nil
 }
 
-// confirmDestructive prompts for a yes/no confirmation unless force is set.
-func confirmDestructive(message string, force bool) bool {
-	if force {
-		return true
+// destructiveFlags are the safety flags shared by reset, refresh and fresh.
+type destructiveFlags struct {
+	force     bool
+	confirmDB string
+	backup    bool
+}
+
+func (d *destructiveFlags) bind(c *cobra.Command) {
+	c.Flags().BoolVar(&d.force, "force", false, "skip confirmation prompt (does not bypass protected databases)")
+	c.Flags().StringVar(&d.confirmDB, "confirm-db", "", "database name, required to run against a protected database non-interactively")
+	c.Flags().BoolVar(&d.backup, "backup", false, "write a backup before changing anything (default from "+config.ForgeBackupKey+")")
+}
+
+// prepare confirms the destructive action, opens the database and writes the
+// backup when requested. ok is false when the user aborted.
+func (d *destructiveFlags) prepare(cmd *cobra.Command, message string) (*gorm.DB, bool, error) {
+	settings, err := config.CurrentSettings()
+	if err != nil {
+		return nil, false, err
+	}
+	ok, err := guard.Confirm(settings, message, guard.Options{Force: d.force, ConfirmDB: d.confirmDB})
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	db, err := database.InitDB()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to initialize database: %v", err)
+	}
+
+	backup := settings.BackupBeforeDestructive
+	if cmd.Flags().Changed("backup") {
+		backup = d.backup
 	}
-	fmt.Printf("%s\nContinue? [y/N]: ", message)
-	reader := bufio.NewReader(os.Stdin)
-	answer, _ := reader.ReadString('\n')
-	answer = strings.ToLower(strings.TrimSpace(answer))
-	if answer == "y" || answer == "yes" {
-		return true
+	if backup {
+		if guard.BackupToDir == nil {
+			return nil, false, fmt.Errorf("backups are not available, nothing was changed")
+		}
+		written, err := guard.BackupToDir(db, settings.BackupDir)
+		if err != nil {
+			return nil, false, fmt.Errorf("backup failed, nothing was changed: %w", err)
+		}
+		fmt.Printf("Backup written to %s.\n", written)
 	}
-	fmt.Println("Aborted.")
-	return false
+	return db, true, nil
 }
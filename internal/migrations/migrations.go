@@ -276,13 +276,57 @@ func PendingUpSQL(db *gorm.DB) ([]string, []string, error) {
 		if err != nil {
 			return nil, nil, fmt.Errorf("unable to read file: %s, error: %v", r.FileName, err)
 		}
-		up := strings.TrimPrefix(strings.Split(string(content), "-- DOWN")[0], "-- UP\n")
 		names = append(names, r.FileName)
-		sqls = append(sqls, strings.TrimSpace(up))
+		sqls = append(sqls, upSQL(string(content)))
 	}
 	return names, sqls, nil
 }
 
+// UpSQLThrough returns the UP SQL of every migration file up to and including
+// version, in order, whether applied or not. version is a file name, with or
+// without .sql, or its timestamp prefix; "latest" means all of them. Used by
+// `schema:diff` to build the schema of a migration version in a scratch
+// database.
+func UpSQLThrough(version string) ([]string, []string, error) {
+	files, err := os.ReadDir(migrationPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read migration directory: %v", err)
+	}
+	var all []string
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".sql") {
+			all = append(all, f.Name())
+		}
+	}
+	sort.Strings(all)
+
+	last := -1
+	for i, name := range all {
+		if version == "latest" || name == version || name == version+".sql" || strings.HasPrefix(name, version+"_") {
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil, nil, fmt.Errorf("no migration matches version %q in %s", version, migrationPath)
+	}
+
+	var names, sqls []string
+	for _, name := range all[:last+1] {
+		content, err := os.ReadFile(filepath.Join(migrationPath, name))
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read file: %s, error: %v", name, err)
+		}
+		names = append(names, name)
+		sqls = append(sqls, upSQL(string(content)))
+	}
+	return names, sqls, nil
+}
+
+// upSQL is the UP section of a migration file.
+func upSQL(content string) string {
+	return strings.TrimSpace(strings.TrimPrefix(strings.Split(content, "-- DOWN")[0], "-- UP\n"))
+}
+
 func createMigrationFile(path string, tableName string) error {
 	f, err := os.Create(path)
 	if err != nil {
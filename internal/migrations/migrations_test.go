@@ -117,3 +117,55 @@ func TestResolveStubAndPlaceholder(t *testing.T) {
 		})
 	}
 }
+
+func TestUpSQLThrough(t *testing.T) {
+	originalWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	tempDir := t.TempDir()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("chdir temp dir: %v", err)
+	}
+	defer func() {
+		if err := os.Chdir(originalWD); err != nil {
+			t.Fatalf("restore cwd: %v", err)
+		}
+	}()
+
+	migrationsDir := filepath.Join(tempDir, "database", "migrations")
+	if err := os.MkdirAll(migrationsDir, 0o755); err != nil {
+		t.Fatalf("mkdir migrations dir: %v", err)
+	}
+	for name, up := range map[string]string{
+		"1700000001_create_users.sql": "CREATE TABLE users (id INTEGER PRIMARY KEY);",
+		"1700000002_create_posts.sql": "CREATE TABLE posts (id INTEGER PRIMARY KEY);",
+		"1700000003_create_tags.sql":  "CREATE TABLE tags (id INTEGER PRIMARY KEY);",
+	} {
+		content := "-- UP\n" + up + "\n-- DOWN\nSELECT 1;\n"
+		if err := os.WriteFile(filepath.Join(migrationsDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write migration: %v", err)
+		}
+	}
+
+	for version, want := range map[string]int{
+		"1700000002":                 2,
+		"1700000001_create_users":    1,
+		"1700000003_create_tags.sql": 3,
+		"latest":                     3,
+	} {
+		names, sqls, err := UpSQLThrough(version)
+		if err != nil {
+			t.Fatalf("%s: %v", version, err)
+		}
+		if len(names) != want || len(sqls) != want {
+			t.Fatalf("%s: got %d migrations, want %d", version, len(names), want)
+		}
+	}
+	if _, sqls, _ := UpSQLThrough("1700000001"); sqls[0] != "CREATE TABLE users (id INTEGER PRIMARY KEY);" {
+		t.Fatalf("up sql = %q", sqls[0])
+	}
+	if _, _, err := UpSQLThrough("1600000000"); err == nil {
+		t.Fatal("expected an error for an unknown version")
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+const stubsPath = "database/stubs"
+
 func getTemplate(name string) (string, error) {
-	path := filepath.Join("database/stubs", name+".stub.sql")
+	path := filepath.Join(stubsPath, name+".stub.sql")
 	if b, err := os.ReadFile(path); err == nil {
 		return string(b), nil
 	}
@@ -19,6 +22,42 @@ func getTemplate(name string) (string, error) {
 	return "", fmt.Errorf("stub %q not found", name)
 }
 
+// CheckStubs inspects the user stub directory and returns one message per
+// problem: files that are not named *.stub.sql (and are therefore ignored) and
+// stubs missing the "-- UP" or "-- DOWN" marker. A missing directory is fine.
+func CheckStubs() ([]string, error) {
+	entries, err := os.ReadDir(stubsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var problems []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		if !strings.HasSuffix(name, ".stub.sql") {
+			problems = append(problems, fmt.Sprintf("%s is ignored (stubs must be named <prefix>.stub.sql)", name))
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(stubsPath, name))
+		if err != nil {
+			return nil, err
+		}
+		content := string(b)
+		if !strings.Contains(content, "-- UP") {
+			problems = append(problems, fmt.Sprintf("%s has no \"-- UP\" section", name))
+		}
+		if !strings.Contains(content, "-- DOWN") {
+			problems = append(problems, fmt.Sprintf("%s has no \"-- DOWN\" section", name))
+		}
+	}
+	return problems, nil
+}
+
 var builtinStubs = map[string]string{
 	"create_table": `-- UP
 CREATE TABLE {table_name} (
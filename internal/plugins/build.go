@@ -29,22 +29,42 @@ func BuildPlugin(p Plugin) (string, error) {
 }
 
 func EnsurePluginExecutable(p Plugin) error {
+	stale, err := NeedsBuild(p)
+	if err != nil || !stale {
+		return err
+	}
+
+	_, err = BuildPlugin(p)
+	return err
+}
+
+// NeedsBuild reports whether a source-based Go plugin has no binary yet or has
+// sources, or files in its watch directories, newer than its binary. Plugins
+// without Go source never need a build.
+func NeedsBuild(p Plugin) (bool, error) {
 	if p.Manifest.Lang != "go" || p.Manifest.Source == "" {
-		return nil
+		return false, nil
 	}
 
 	entryInfo, entryErr := os.Stat(p.EntryPath())
 	sourceModTime, sourceErr := latestSourceModTime(p.SourcePath())
 	if sourceErr != nil {
-		return fmt.Errorf("stat plugin source %s: %w", p.SourcePath(), sourceErr)
+		return false, fmt.Errorf("stat plugin source %s: %w", p.SourcePath(), sourceErr)
 	}
-
-	if entryErr == nil && !sourceModTime.After(entryInfo.ModTime()) {
-		return nil
+	for _, dir := range p.Manifest.Watch {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(p.BaseDir, dir)
+		}
+		watched, err := latestSourceModTime(dir)
+		if err != nil {
+			return false, fmt.Errorf("stat plugin source %s: %w", dir, err)
+		}
+		if watched.After(sourceModTime) {
+			sourceModTime = watched
+		}
 	}
 
-	_, err := BuildPlugin(p)
-	return err
+	return entryErr != nil || sourceModTime.After(entryInfo.ModTime()), nil
 }
 
 func latestSourceModTime(root string) (time.Time, error) {
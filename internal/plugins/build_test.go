@@ -0,0 +1,46 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNeedsBuildWatch(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	watched := filepath.Join(root, "seeds")
+	p := Plugin{
+		Manifest: PluginManifest{Name: "seeders", Lang: "go", Entry: "seeders", Source: "src", Watch: []string{"seeds"}},
+		BaseDir:  root,
+	}
+	for _, path := range []string{filepath.Join(p.SourcePath(), "main.go"), filepath.Join(watched, "users.go"), p.EntryPath()} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-time.Hour)
+	for _, path := range []string{filepath.Join(p.SourcePath(), "main.go"), filepath.Join(watched, "users.go")} {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if stale, err := NeedsBuild(p); err != nil || stale {
+		t.Fatalf("fresh binary: stale=%v err=%v", stale, err)
+	}
+	if err := os.WriteFile(filepath.Join(watched, "users.go"), []byte("y"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(watched, "users.go"), time.Now().Add(time.Hour), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if stale, err := NeedsBuild(p); err != nil || !stale {
+		t.Fatalf("changed watch dir: stale=%v err=%v", stale, err)
+	}
+}
@@ -13,6 +13,7 @@ type PluginManifest struct {
 	Lang        string                `json:"lang"`  // runtime: binary|node|php...
 	Entry       string                `json:"entry"` // файл/бинарь для запуска
 	Source      string                `json:"source,omitempty"`
+	Watch       []string              `json:"watch,omitempty"` // доп. каталоги, изменения в которых требуют пересборки
 	Commands    []PluginCommand       `json:"commands"`
 	Hooks       map[string]HookConfig `json:"hooks"`
 }
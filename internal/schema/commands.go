@@ -26,16 +26,18 @@ func Register(parent *cobra.Command) {
 	parent.AddCommand(erdCmd())
 	parent.AddCommand(snapshotCmd())
 	parent.AddCommand(diffCmd())
+	parent.AddCommand(lintCmd())
 	parent.AddCommand(modelCmd())
 }
 
 func showCmd() *cobra.Command {
 	var all bool
+	var sf schemaFlags
 	c := &cobra.Command{
 		Use:   "schema:show",
 		Short: "Print a human-readable overview of the current database schema",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			m, err := introspect(all)
+			m, err := introspect(all, sf.list())
 			if err != nil {
 				return err
 			}
@@ -44,12 +46,14 @@ func showCmd() *cobra.Command {
 		},
 	}
 	c.Flags().BoolVarP(&all, "all", "a", false, "include Forge's internal tables (migrations, seeds)")
+	sf.bind(c)
 	return c
 }
 
 func dumpCmd() *cobra.Command {
 	var out string
 	var all bool
+	var sf schemaFlags
 	c := &cobra.Command{
 		Use:   "schema:dump",
 		Short: "Dump the current database schema as SQL DDL",
@@ -58,7 +62,7 @@ func dumpCmd() *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("failed to initialize database: %v", err)
 			}
-			m, err := Introspect(db)
+			m, err := IntrospectSchemas(db, sf.list())
 			if err != nil {
 				return err
 			}
@@ -72,12 +76,14 @@ func dumpCmd() *cobra.Command {
 	}
 	c.Flags().StringVarP(&out, "out", "o", "", "write to file instead of stdout")
 	c.Flags().BoolVarP(&all, "all", "a", false, "include Forge's internal tables (migrations, seeds)")
+	sf.bind(c)
 	return c
 }
 
 func erdCmd() *cobra.Command {
 	var out, format string
 	var all bool
+	var sf schemaFlags
 	c := &cobra.Command{
 		Use:   "schema:erd",
 		Short: "Generate an ERD from the current database schema (Mermaid or Graphviz DOT)",
@@ -85,7 +91,7 @@ func erdCmd() *cobra.Command {
   forge db schema:erd -o erd.mmd       # Mermaid to file
   forge db schema:erd --format dot -o erd.dot`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			m, err := introspect(all)
+			m, err := introspect(all, sf.list())
 			if err != nil {
 				return err
 			}
@@ -104,17 +110,19 @@ func erdCmd() *cobra.Command {
 	c.Flags().StringVarP(&out, "out", "o", "", "write to file instead of stdout")
 	c.Flags().StringVarP(&format, "format", "f", "mermaid", "diagram format: mermaid | dot")
 	c.Flags().BoolVarP(&all, "all", "a", false, "include Forge's internal tables (migrations, seeds)")
+	sf.bind(c)
 	return c
 }
 
 func snapshotCmd() *cobra.Command {
 	var out string
 	var all bool
+	var sf schemaFlags
 	c := &cobra.Command{
 		Use:   "schema:snapshot",
 		Short: "Save the current database schema to a snapshot file (for schema:diff)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			m, err := introspect(all)
+			m, err := introspect(all, sf.list())
 			if err != nil {
 				return err
 			}
@@ -134,46 +142,184 @@ func snapshotCmd() *cobra.Command {
 	}
 	c.Flags().StringVarP(&out, "out", "o", defaultSnapshotPath, "snapshot file path")
 	c.Flags().BoolVarP(&all, "all", "a", false, "include Forge's internal tables (migrations, seeds)")
+	sf.bind(c)
 	return c
 }
 
 func diffCmd() *cobra.Command {
-	var from string
+	var from, to, scratch, format string
 	var all, exitCode bool
+	var threshold float64
+	var sf schemaFlags
 	c := &cobra.Command{
 		Use:   "schema:diff",
-		Short: "Diff the live database schema against a saved snapshot",
-		Long: `Compare the current database schema against a snapshot created with
-schema:snapshot. Useful for detecting schema drift (e.g. in CI with --exit-code).`,
+		Short: "Diff two schemas: live databases, snapshots or migration versions",
+		Long: `Compare two schemas, old (--from) against new (--to). Each side is one of:
+
+  live                       the configured database
+  postgres://… mysql://… sqlite://…
+                             another database, e.g. staging vs production
+  path/to/snapshot.json      a snapshot created with schema:snapshot
+  1712345678 | latest        a migration version: the migrations up to it are
+                             applied to a scratch database
+
+Prefix a side with snapshot:, migration: or dsn: to force its kind. The
+scratch database is an in-memory sqlite database for sqlite projects and a
+temporary database on the configured server otherwise (it needs the right to
+create databases); --scratch names an empty database to use instead.
+
+A removed and an added table or column that look alike (same type, position
+and keys for a column; mostly the same columns for a table) are reported as a
+rename. --rename-threshold sets how alike, from 0 to 1; 0 turns it off.
+
+--format json prints the schema.Diff structure, markdown a table for
+pull-request comments and sarif a SARIF 2.1.0 log for code-scanning tools.
+
+Useful for detecting schema drift (e.g. in CI with --exit-code).`,
+		Example: `  forge db schema:diff                                  # snapshot vs live
+  forge db schema:diff --from latest                    # migrations vs live
+  forge db schema:diff --from postgres://prod/app --to postgres://staging/app
+  forge db schema:diff --from 1712345678 --to latest    # what changed since a version
+  forge db schema:diff --from latest --format markdown  # for a PR comment`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			oldM, err := LoadSnapshot(from)
-			if err != nil {
-				if os.IsNotExist(err) {
-					return fmt.Errorf("snapshot %s not found — run `forge db schema:snapshot` first", from)
-				}
+			if threshold < 0 || threshold > 1 {
+				return fmt.Errorf("--rename-threshold must be between 0 and 1")
+			}
+			if _, err := FormatDiff(Diff{}, format); err != nil {
 				return err
 			}
-			newM, err := introspect(all)
+			o := sourceOptions{all: all, schemas: sf.list(), scratch: scratch}
+			oldM, err := loadSource(from, o)
 			if err != nil {
-				return err
+				return fmt.Errorf("--from: %w", err)
+			}
+			newM, err := loadSource(to, o)
+			if err != nil {
+				return fmt.Errorf("--to: %w", err)
+			}
+			d := DiffModelsWith(oldM, newM, DiffOptions{RenameThreshold: threshold})
+			if d.Empty() && (format == "" || format == "text") {
+				fmt.Printf("No differences between %s and %s.\n", from, to)
+			} else {
+				out, err := FormatDiff(d, format)
+				if err != nil {
+					return err
+				}
+				fmt.Print(out)
 			}
-			d := DiffModels(oldM, newM)
-			fmt.Print(RenderDiff(d))
 			if exitCode && !d.Empty() {
 				os.Exit(1)
 			}
 			return nil
 		},
 	}
-	c.Flags().StringVar(&from, "from", defaultSnapshotPath, "snapshot file to compare against")
+	c.Flags().StringVar(&from, "from", defaultSnapshotPath, "old side: live, a DSN, a snapshot file or a migration version")
+	c.Flags().StringVar(&to, "to", "live", "new side: live, a DSN, a snapshot file or a migration version")
+	c.Flags().StringVar(&scratch, "scratch", "", "Forge DSN of an empty database to build migration versions in")
+	c.Flags().StringVarP(&format, "format", "f", "text", "output format: "+strings.Join(DiffFormats, " | "))
+	c.Flags().Float64Var(&threshold, "rename-threshold", DefaultRenameThreshold, "similarity (0-1) to report a removed and an added table or column as a rename; 0 disables")
 	c.Flags().BoolVarP(&all, "all", "a", false, "include Forge's internal tables (migrations, seeds)")
+	sf.bind(c)
 	c.Flags().BoolVar(&exitCode, "exit-code", false, "exit with code 1 if the schema differs (for CI)")
 	return c
 }
 
+func lintCmd() *cobra.Command {
+	var configPath, format, failOn, scratch string
+	var overrides []string
+	var all, listRules bool
+	var sf schemaFlags
+	c := &cobra.Command{
+		Use:   "schema:lint [source]",
+		Short: "Check the schema for common mistakes",
+		Long: `Run lint rules over a schema: the live database by default, or any source
+schema:diff accepts (a DSN, a snapshot file or a migration version).
+
+Every rule has a severity (info, warning, error) that can be changed, or set to
+off, in ` + DefaultLintFile + ` or with --rule name=severity:
+
+  rules:
+    missing-timestamps: off
+    plural-table: warning
+    money-float:
+      severity: error
+      ignore: [legacy_*]     # tables or table.column, shell globs
+  ignore:
+    - schema_versions        # skipped by every rule
+
+The command exits with code 1 when a finding reaches --fail-on (default error),
+so it can guard CI.`,
+		Example: `  forge db schema:lint
+  forge db schema:lint --list-rules
+  forge db schema:lint latest --fail-on warning   # lint what the migrations build
+  forge db schema:lint --rule plural-table=off --format sarif > lint.sarif`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if listRules {
+				for _, r := range LintRules() {
+					fmt.Printf("%-20s %-8s %s\n", r.Name, r.Severity, r.Description)
+				}
+				return nil
+			}
+			if !contains(LintFormats, format) {
+				return fmt.Errorf("unknown --format %q (use: %s)", format, strings.Join(LintFormats, ", "))
+			}
+			threshold := SeverityOff
+			if failOn != "none" {
+				var err error
+				if threshold, err = ParseSeverity(failOn); err != nil {
+					return fmt.Errorf("--fail-on: %w", err)
+				}
+			}
+			cfg, err := LoadLintConfig(configPath)
+			if err != nil {
+				return err
+			}
+			for _, o := range overrides {
+				name, sev, ok := strings.Cut(o, "=")
+				if !ok {
+					return fmt.Errorf("--rule %q: use name=severity", o)
+				}
+				if err := cfg.Set(strings.TrimSpace(name), sev); err != nil {
+					return err
+				}
+			}
+
+			source := "live"
+			if len(args) == 1 {
+				source = args[0]
+			}
+			m, err := loadSource(source, sourceOptions{all: all, schemas: sf.list(), scratch: scratch})
+			if err != nil {
+				return err
+			}
+			findings := Lint(m, cfg)
+			if err := WriteLint(os.Stdout, findings, format); err != nil {
+				return err
+			}
+			for _, f := range findings {
+				if threshold != SeverityOff && f.Severity >= threshold {
+					os.Exit(1)
+				}
+			}
+			return nil
+		},
+	}
+	c.Flags().StringVarP(&configPath, "config", "c", DefaultLintFile, "lint configuration file (optional)")
+	c.Flags().StringArrayVar(&overrides, "rule", nil, "override a rule's severity, e.g. plural-table=off (repeatable)")
+	c.Flags().StringVarP(&format, "format", "f", "text", "output format: "+strings.Join(LintFormats, " | "))
+	c.Flags().StringVar(&failOn, "fail-on", "error", "exit with code 1 on findings of this severity or higher: info | warning | error | none")
+	c.Flags().BoolVar(&listRules, "list-rules", false, "list the rules and their default severity")
+	c.Flags().StringVar(&scratch, "scratch", "", "Forge DSN of an empty database to build a migration version in")
+	c.Flags().BoolVarP(&all, "all", "a", false, "include Forge's internal tables (migrations, seeds)")
+	sf.bind(c)
+	return c
+}
+
 func modelCmd() *cobra.Command {
 	var out, pkg string
 	var all bool
+	var sf schemaFlags
 	c := &cobra.Command{
 		Use:   "make:model [table]",
 		Short: "Generate Go structs from the database schema",
@@ -188,7 +334,7 @@ app compiles it. Pass a table name to generate a single model, or omit it for al
 			if err != nil {
 				return fmt.Errorf("failed to initialize database: %v", err)
 			}
-			m, err := Introspect(db)
+			m, err := IntrospectSchemas(db, sf.list())
 			if err != nil {
 				return err
 			}
@@ -223,6 +369,7 @@ app compiles it. Pass a table name to generate a single model, or omit it for al
 	c.Flags().StringVarP(&out, "out", "o", "", "write to file instead of stdout")
 	c.Flags().StringVarP(&pkg, "package", "p", "models", "Go package name for the generated file")
 	c.Flags().BoolVarP(&all, "all", "a", false, "include Forge's internal tables when generating all models")
+	sf.bind(c)
 	return c
 }
 
@@ -233,31 +380,59 @@ func dirOf(path string) string {
 	return "."
 }
 
-func introspect(all bool) (*Model, error) {
+// schemaFlags are the --schema/--schemas flags of the schema:* commands.
+type schemaFlags struct {
+	one, many []string
+}
+
+func (f *schemaFlags) bind(c *cobra.Command) {
+	c.Flags().StringArrayVar(&f.one, "schema", nil, "postgres schema or mysql database to include (repeatable)")
+	c.Flags().StringSliceVar(&f.many, "schemas", nil, "comma-separated schemas/databases to include (default: FORGE_DB_SCHEMAS, else the current one)")
+}
+
+// list returns the schemas to introspect; nil means the current one.
+func (f *schemaFlags) list() []string {
+	out := append(append([]string(nil), f.one...), f.many...)
+	if len(out) == 0 {
+		if s, err := config.CurrentSettings(); err == nil {
+			out = s.Schemas
+		}
+	}
+	return out
+}
+
+func introspect(all bool, schemas []string) (*Model, error) {
 	db, err := database.InitDB()
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %v", err)
 	}
-	m, err := Introspect(db)
+	m, err := IntrospectSchemas(db, schemas)
 	if err != nil {
 		return nil, err
 	}
 	return applyVisibility(m, all), nil
 }
 
-// applyVisibility drops Forge's internal tables unless all is true.
+// applyVisibility drops Forge's internal tables and their triggers unless all
+// is true.
 func applyVisibility(m *Model, all bool) *Model {
 	if all {
 		return m
 	}
-	filtered := &Model{Driver: m.Driver}
+	filtered := *m
+	filtered.Tables, filtered.Triggers = nil, nil
 	for _, t := range m.Tables {
 		if internalTables[t.Name] {
 			continue
 		}
 		filtered.Tables = append(filtered.Tables, t)
 	}
-	return filtered
+	for _, tr := range m.Triggers {
+		if !internalTables[tr.Table] {
+			filtered.Triggers = append(filtered.Triggers, tr)
+		}
+	}
+	return &filtered
 }
 
 func writeOut(path, content string) error {
@@ -29,7 +29,7 @@ func LoadSnapshot(path string) (*Model, error) {
 // ColumnChange describes a single altered column attribute.
 type ColumnChange struct {
 	Column string
-	Field  string // "type", "nullable", "default"
+	Field  string // "type", "nullable", "default", "comment"
 	Old    string
 	New    string
 }
@@ -40,12 +40,21 @@ type TableDiff struct {
 	AddedColumns   []Column
 	RemovedColumns []string
 	ChangedColumns []ColumnChange
-	Notes          []string // PK / index / FK changes
+	Notes          []string // PK / index / FK / check / comment changes
+	RenamedColumns []Rename
 }
 
 func (t TableDiff) empty() bool {
 	return len(t.AddedColumns) == 0 && len(t.RemovedColumns) == 0 &&
-		len(t.ChangedColumns) == 0 && len(t.Notes) == 0
+		len(t.ChangedColumns) == 0 && len(t.Notes) == 0 && len(t.RenamedColumns) == 0
+}
+
+// ObjectChange is an added (+), removed (-) or changed (~) view, trigger,
+// sequence, enum or function.
+type ObjectChange struct {
+	Kind string // "view", "materialized view", "trigger", "sequence", "enum", "function"
+	Name string
+	Op   string
 }
 
 // Diff is the full comparison between two models (old -> new).
@@ -53,15 +62,35 @@ type Diff struct {
 	AddedTables   []string
 	RemovedTables []string
 	ChangedTables []TableDiff
+	Objects       []ObjectChange
+	RenamedTables []Rename
 }
 
 // Empty reports whether the two models are equivalent.
 func (d Diff) Empty() bool {
-	return len(d.AddedTables) == 0 && len(d.RemovedTables) == 0 && len(d.ChangedTables) == 0
+	return len(d.AddedTables) == 0 && len(d.RemovedTables) == 0 && len(d.ChangedTables) == 0 &&
+		len(d.Objects) == 0 && len(d.RenamedTables) == 0
+}
+
+// DiffOptions tune DiffModelsWith.
+type DiffOptions struct {
+	// RenameThreshold turns on rename detection: a removed and an added table
+	// (or column) at least this similar, from 0 to 1, are reported as a rename.
+	// 0 reports every rename as a removal plus an addition.
+	RenameThreshold float64
 }
 
+// DefaultRenameThreshold is the similarity schema:diff requires for a rename.
+const DefaultRenameThreshold = 0.8
+
 // DiffModels compares oldM (e.g. a snapshot) against newM (e.g. the live DB).
+// Renamed tables and columns show up as removed and added; see DiffModelsWith.
 func DiffModels(oldM, newM *Model) Diff {
+	return DiffModelsWith(oldM, newM, DiffOptions{})
+}
+
+// DiffModelsWith is DiffModels with options, e.g. rename detection.
+func DiffModelsWith(oldM, newM *Model, opts DiffOptions) Diff {
 	var d Diff
 	oldT := tableMap(oldM)
 	newT := tableMap(newM)
@@ -79,27 +108,94 @@ func DiffModels(oldM, newM *Model) Diff {
 	sortStrings(d.AddedTables)
 	sortStrings(d.RemovedTables)
 
+	if opts.RenameThreshold > 0 && len(d.AddedTables) > 0 && len(d.RemovedTables) > 0 {
+		d.RenamedTables = matchRenames(d.RemovedTables, d.AddedTables, opts.RenameThreshold, func(o, n string) float64 {
+			return tableSimilarity(oldT[o], newT[n])
+		})
+		d.RemovedTables, d.AddedTables = withoutRenames(d.RemovedTables, d.AddedTables, d.RenamedTables)
+		oldT = renameTables(oldT, d.RenamedTables)
+	}
+
 	for _, nt := range newM.Tables {
 		ot, ok := oldT[nt.Name]
 		if !ok {
 			continue
 		}
-		if td := diffTable(ot, nt); !td.empty() {
+		if td := diffTable(ot, nt, opts); !td.empty() {
 			d.ChangedTables = append(d.ChangedTables, td)
 		}
 	}
 	sort.Slice(d.ChangedTables, func(i, j int) bool { return d.ChangedTables[i].Name < d.ChangedTables[j].Name })
+	d.Objects = diffObjects(oldM, newM)
 	return d
 }
 
-func diffTable(oldT, newT Table) TableDiff {
+// diffObjects compares the non-table objects of two models by kind and name.
+// Definitions are compared with whitespace collapsed.
+func diffObjects(oldM, newM *Model) []ObjectChange {
+	oldO, newO := objectDefs(oldM), objectDefs(newM)
+	var out []ObjectChange
+	for key, def := range newO {
+		kind, name, _ := strings.Cut(key, "\x00")
+		if oldDef, ok := oldO[key]; !ok {
+			out = append(out, ObjectChange{kind, name, "+"})
+		} else if oldDef != def {
+			out = append(out, ObjectChange{kind, name, "~"})
+		}
+	}
+	for key := range oldO {
+		if _, ok := newO[key]; !ok {
+			kind, name, _ := strings.Cut(key, "\x00")
+			out = append(out, ObjectChange{kind, name, "-"})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// objectDefs maps "kind\x00name" to a normalized definition.
+func objectDefs(m *Model) map[string]string {
+	out := map[string]string{}
+	if m == nil {
+		return out
+	}
+	norm := func(s string) string { return strings.Join(strings.Fields(s), " ") }
+	for _, v := range m.Views {
+		kind := "view"
+		if v.Materialized {
+			kind = "materialized view"
+		}
+		out[kind+"\x00"+v.Name] = norm(v.Definition)
+	}
+	for _, t := range m.Triggers {
+		out["trigger\x00"+t.Name] = norm(t.Definition)
+	}
+	for _, s := range m.Sequences {
+		out["sequence\x00"+s.Name] = fmt.Sprintf("%d %d %s", s.Start, s.Increment, s.OwnedBy)
+	}
+	for _, e := range m.Enums {
+		out["enum\x00"+e.Name] = strings.Join(e.Values, "\x00")
+	}
+	for _, f := range m.Functions {
+		out["function\x00"+f.Name+"("+f.Arguments+")"] = norm(f.Definition)
+	}
+	return out
+}
+
+func diffTable(oldT, newT Table, opts DiffOptions) TableDiff {
 	td := TableDiff{Name: newT.Name}
 	oldC := columnMap(oldT)
 	newC := columnMap(newT)
 
+	var added []string
 	for _, c := range newT.Columns {
 		if _, ok := oldC[c.Name]; !ok {
-			td.AddedColumns = append(td.AddedColumns, c)
+			added = append(added, c.Name)
 		}
 	}
 	for _, c := range oldT.Columns {
@@ -107,6 +203,18 @@ func diffTable(oldT, newT Table) TableDiff {
 			td.RemovedColumns = append(td.RemovedColumns, c.Name)
 		}
 	}
+	if opts.RenameThreshold > 0 && len(added) > 0 && len(td.RemovedColumns) > 0 {
+		td.RenamedColumns = matchRenames(td.RemovedColumns, added, opts.RenameThreshold, func(o, n string) float64 {
+			return columnSimilarity(oldT, o, newT, n)
+		})
+		td.RemovedColumns, added = withoutRenames(td.RemovedColumns, added, td.RenamedColumns)
+		// Compare the rest of the table as if the columns had their new names.
+		oldT = renameColumns(oldT, td.RenamedColumns)
+		oldC = columnMap(oldT)
+	}
+	for _, name := range added {
+		td.AddedColumns = append(td.AddedColumns, newC[name])
+	}
 	for _, nc := range newT.Columns {
 		oc, ok := oldC[nc.Name]
 		if !ok {
@@ -121,6 +229,9 @@ func diffTable(oldT, newT Table) TableDiff {
 		if oc.Default != nc.Default {
 			td.ChangedColumns = append(td.ChangedColumns, ColumnChange{nc.Name, "default", oc.Default, nc.Default})
 		}
+		if oc.Comment != nc.Comment {
+			td.ChangedColumns = append(td.ChangedColumns, ColumnChange{nc.Name, "comment", oc.Comment, nc.Comment})
+		}
 	}
 
 	if !equalStrings(oldT.PrimaryKey, newT.PrimaryKey) {
@@ -128,7 +239,11 @@ func diffTable(oldT, newT Table) TableDiff {
 			strings.Join(oldT.PrimaryKey, ", "), strings.Join(newT.PrimaryKey, ", ")))
 	}
 	td.Notes = append(td.Notes, setDiff("index", indexSigs(oldT), indexSigs(newT))...)
-	td.Notes = append(td.Notes, setDiff("foreign key", fkSigs(oldT), fkSigs(newT))...)
+	td.Notes = append(td.Notes, fkNotes(oldT, newT)...)
+	td.Notes = append(td.Notes, setDiff("check", checkSigs(oldT), checkSigs(newT))...)
+	if oldT.Comment != newT.Comment {
+		td.Notes = append(td.Notes, fmt.Sprintf("comment: %q -> %q", oldT.Comment, newT.Comment))
+	}
 	return td
 }
 
@@ -138,6 +253,9 @@ func RenderDiff(d Diff) string {
 		return "No differences — live schema matches the snapshot.\n"
 	}
 	var b strings.Builder
+	for _, r := range d.RenamedTables {
+		fmt.Fprintf(&b, "~ table %s -> %s (renamed)\n", r.Old, r.New)
+	}
 	for _, t := range d.AddedTables {
 		fmt.Fprintf(&b, "+ table %s\n", t)
 	}
@@ -146,6 +264,9 @@ func RenderDiff(d Diff) string {
 	}
 	for _, td := range d.ChangedTables {
 		fmt.Fprintf(&b, "~ table %s\n", td.Name)
+		for _, r := range td.RenamedColumns {
+			fmt.Fprintf(&b, "    ~ column %s -> %s (renamed)\n", r.Old, r.New)
+		}
 		for _, c := range td.AddedColumns {
 			fmt.Fprintf(&b, "    + column %s %s\n", c.Name, c.Type)
 		}
@@ -159,6 +280,9 @@ func RenderDiff(d Diff) string {
 			fmt.Fprintf(&b, "    ~ %s\n", n)
 		}
 	}
+	for _, o := range d.Objects {
+		fmt.Fprintf(&b, "%s %s %s\n", o.Op, o.Kind, o.Name)
+	}
 	return b.String()
 }
 
@@ -190,15 +314,64 @@ func indexSigs(t Table) map[string]bool {
 		if ix.Unique {
 			kind = "unique"
 		}
-		out[fmt.Sprintf("%s (%s)", kind, strings.Join(ix.Columns, ","))] = true
+		out[kind+" "+indexSummary(ix)] = true
 	}
 	return out
 }
 
+// fkSigs describes the foreign keys of t without their names, which not every
+// database keeps.
 func fkSigs(t Table) map[string]bool {
 	out := map[string]bool{}
 	for _, fk := range t.ForeignKeys {
-		out[fmt.Sprintf("(%s) -> %s(%s)", strings.Join(fk.Columns, ","), fk.RefTable, strings.Join(fk.RefColumns, ","))] = true
+		out[fkSig(fk)] = true
+	}
+	return out
+}
+
+func fkSig(fk ForeignKey) string {
+	fk.Name = ""
+	return fkSummary(fk)
+}
+
+// fkNotes compares foreign keys by what they do; a name change is reported
+// on its own, and only when both sides have a name.
+func fkNotes(oldT, newT Table) []string {
+	oldFKs, newFKs := map[string]ForeignKey{}, map[string]ForeignKey{}
+	for _, fk := range oldT.ForeignKeys {
+		oldFKs[fkSig(fk)] = fk
+	}
+	for _, fk := range newT.ForeignKeys {
+		newFKs[fkSig(fk)] = fk
+	}
+	removed, added := map[string]bool{}, map[string]bool{}
+	var renamed []string
+	for sig, o := range oldFKs {
+		n, ok := newFKs[sig]
+		switch {
+		case !ok:
+			removed[fkSummary(o)] = true
+		case o.Name != "" && n.Name != "" && o.Name != n.Name:
+			renamed = append(renamed, fmt.Sprintf("~ foreign key %s -> %s (renamed)", o.Name, n.Name))
+		}
+	}
+	for sig, n := range newFKs {
+		if _, ok := oldFKs[sig]; !ok {
+			added[fkSummary(n)] = true
+		}
+	}
+	sortStrings(renamed)
+	return append(setDiff("foreign key", removed, added), renamed...)
+}
+
+func checkSigs(t Table) map[string]bool {
+	out := map[string]bool{}
+	for _, ck := range t.Checks {
+		sig := "(" + strings.Join(strings.Fields(ck.Expression), " ") + ")"
+		if ck.Name != "" {
+			sig = ck.Name + " " + sig
+		}
+		out[sig] = true
 	}
 	return out
 }
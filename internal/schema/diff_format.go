@@ -0,0 +1,172 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DiffFormats are the --format values of schema:diff.
+var DiffFormats = []string{"text", "json", "markdown", "sarif"}
+
+// FormatDiff renders a diff in one of DiffFormats.
+func FormatDiff(d Diff, format string) (string, error) {
+	switch format {
+	case "", "text":
+		return RenderDiff(d), nil
+	case "json":
+		b, err := DiffJSON(d)
+		return string(b) + "\n", err
+	case "markdown", "md":
+		return RenderDiffMarkdown(d), nil
+	case "sarif":
+		b, err := DiffSARIF(d)
+		return string(b) + "\n", err
+	}
+	return "", fmt.Errorf("unknown --format %q (use: %s)", format, strings.Join(DiffFormats, ", "))
+}
+
+// DiffJSON serializes a diff as indented JSON with the field names of Diff.
+// Lists are always present (empty rather than null) so tools can rely on them.
+func DiffJSON(d Diff) ([]byte, error) {
+	d.AddedTables = nonNil(d.AddedTables)
+	d.RemovedTables = nonNil(d.RemovedTables)
+	if d.ChangedTables == nil {
+		d.ChangedTables = []TableDiff{}
+	}
+	if d.Objects == nil {
+		d.Objects = []ObjectChange{}
+	}
+	if d.RenamedTables == nil {
+		d.RenamedTables = []Rename{}
+	}
+	tables := make([]TableDiff, len(d.ChangedTables))
+	for i, td := range d.ChangedTables {
+		if td.AddedColumns == nil {
+			td.AddedColumns = []Column{}
+		}
+		td.RemovedColumns = nonNil(td.RemovedColumns)
+		if td.ChangedColumns == nil {
+			td.ChangedColumns = []ColumnChange{}
+		}
+		td.Notes = nonNil(td.Notes)
+		if td.RenamedColumns == nil {
+			td.RenamedColumns = []Rename{}
+		}
+		tables[i] = td
+	}
+	d.ChangedTables = tables
+	return json.MarshalIndent(d, "", "  ")
+}
+
+func nonNil(ss []string) []string {
+	if ss == nil {
+		return []string{}
+	}
+	return ss
+}
+
+// diffEntry is one line of a diff, flattened for markdown and SARIF.
+type diffEntry struct {
+	Op     string // "added", "removed", "renamed", "changed"
+	Kind   string // "table", "column", "view", ...
+	Name   string // table, table.column or object name
+	Detail string
+}
+
+func (e diffEntry) ruleID() string {
+	return strings.ReplaceAll(e.Kind, " ", "-") + "-" + e.Op
+}
+
+func diffEntries(d Diff) []diffEntry {
+	var out []diffEntry
+	for _, r := range d.RenamedTables {
+		out = append(out, diffEntry{"renamed", "table", r.New, fmt.Sprintf("from %s (%.0f%% similar)", r.Old, r.Score*100)})
+	}
+	for _, t := range d.AddedTables {
+		out = append(out, diffEntry{"added", "table", t, ""})
+	}
+	for _, t := range d.RemovedTables {
+		out = append(out, diffEntry{"removed", "table", t, ""})
+	}
+	for _, td := range d.ChangedTables {
+		for _, r := range td.RenamedColumns {
+			out = append(out, diffEntry{"renamed", "column", td.Name + "." + r.New, fmt.Sprintf("from %s (%.0f%% similar)", r.Old, r.Score*100)})
+		}
+		for _, c := range td.AddedColumns {
+			out = append(out, diffEntry{"added", "column", td.Name + "." + c.Name, c.Type})
+		}
+		for _, c := range td.RemovedColumns {
+			out = append(out, diffEntry{"removed", "column", td.Name + "." + c, ""})
+		}
+		for _, c := range td.ChangedColumns {
+			out = append(out, diffEntry{"changed", "column", td.Name + "." + c.Column, fmt.Sprintf("%s %q -> %q", c.Field, c.Old, c.New)})
+		}
+		for _, n := range td.Notes {
+			out = append(out, diffEntry{"changed", "table", td.Name, n})
+		}
+	}
+	ops := map[string]string{"+": "added", "-": "removed", "~": "changed"}
+	for _, o := range d.Objects {
+		out = append(out, diffEntry{ops[o.Op], o.Kind, o.Name, ""})
+	}
+	return out
+}
+
+// RenderDiffMarkdown renders a diff as a Markdown table, e.g. for a
+// pull-request comment.
+func RenderDiffMarkdown(d Diff) string {
+	var b strings.Builder
+	b.WriteString("### Schema diff\n\n")
+	entries := diffEntries(d)
+	if len(entries) == 0 {
+		b.WriteString("No differences.\n")
+		return b.String()
+	}
+
+	counts := map[string]int{}
+	for _, e := range entries {
+		counts[e.Op]++
+	}
+	var summary []string
+	for _, op := range []string{"added", "removed", "renamed", "changed"} {
+		if counts[op] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[op], op))
+		}
+	}
+	fmt.Fprintf(&b, "%s.\n\n", strings.Join(summary, ", "))
+
+	b.WriteString("| Change | Object | Details |\n|---|---|---|\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "| %s | %s `%s` | %s |\n", e.Op, e.Kind, mdCell(e.Name), mdCell(e.Detail))
+	}
+	return b.String()
+}
+
+func mdCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// DiffSARIF renders a diff as a SARIF 2.1.0 log, one result per change, for
+// code-scanning tools. Removals are errors, changes warnings and additions
+// and renames notes.
+func DiffSARIF(d Diff) ([]byte, error) {
+	levels := map[string]string{"added": "note", "renamed": "note", "changed": "warning", "removed": "error"}
+	var rules []sarifRule
+	var results []sarifResult
+	seen := map[string]bool{}
+	for _, e := range diffEntries(d) {
+		id := e.ruleID()
+		if !seen[id] {
+			seen[id] = true
+			rules = append(rules, newSARIFRule(id, fmt.Sprintf("%s %s", e.Kind, e.Op)))
+		}
+		text := fmt.Sprintf("%s %s %s", e.Kind, e.Name, e.Op)
+		if e.Detail != "" {
+			text += ": " + e.Detail
+		}
+		results = append(results, newSARIFResult(id, levels[e.Op], text, e.Name, e.Kind))
+	}
+	return sarifLog(rules, results)
+}
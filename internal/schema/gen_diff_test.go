@@ -100,3 +100,135 @@ func TestDiffEmptyWhenEqual(t *testing.T) {
 		t.Fatal("identical models should diff empty")
 	}
 }
+
+func TestDiffForeignKeyNames(t *testing.T) {
+	model := func(name string) *Model {
+		return &Model{Driver: "postgres", Tables: []Table{{
+			Name:        "posts",
+			Columns:     []Column{{Name: "id", Type: "integer"}, {Name: "user_id", Type: "integer"}},
+			ForeignKeys: []ForeignKey{{Name: name, Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}}},
+		}}}
+	}
+	// A snapshot from a database that does not keep FK names is no change.
+	if d := DiffModels(model(""), model("fk_posts_user")); !d.Empty() {
+		t.Fatalf("unnamed vs named FK should diff empty:\n%s", RenderDiff(d))
+	}
+	out := RenderDiff(DiffModels(model("fk_old"), model("fk_new")))
+	if !strings.Contains(out, "~ foreign key fk_old -> fk_new (renamed)") || strings.Contains(out, "+ foreign key") {
+		t.Fatalf("FK rename not reported on its own:\n%s", out)
+	}
+}
+
+func TestDiffDetectsRenames(t *testing.T) {
+	old := &Model{Driver: "sqlite", Tables: []Table{
+		{
+			Name: "users",
+			Columns: []Column{
+				{Name: "id", Type: "integer"},
+				{Name: "email", Type: "text"},
+				{Name: "name", Type: "text", Nullable: true},
+			},
+			PrimaryKey: []string{"id"},
+			Indexes:    []Index{{Name: "ux_users_email", Columns: []string{"email"}, Unique: true}},
+		},
+		{
+			Name:        "post",
+			Columns:     []Column{{Name: "id", Type: "integer"}, {Name: "user_id", Type: "integer"}, {Name: "body", Type: "text"}},
+			PrimaryKey:  []string{"id"},
+			ForeignKeys: []ForeignKey{{Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}}},
+		},
+		{Name: "legacy", Columns: []Column{{Name: "id", Type: "integer"}}},
+	}}
+	newM := &Model{Driver: "sqlite", Tables: []Table{
+		{
+			Name: "users",
+			Columns: []Column{
+				{Name: "id", Type: "integer"},
+				{Name: "email_address", Type: "text"},
+				{Name: "name", Type: "text", Nullable: true},
+			},
+			PrimaryKey: []string{"id"},
+			Indexes:    []Index{{Name: "ux_users_email", Columns: []string{"email_address"}, Unique: true}},
+		},
+		{
+			Name:        "posts",
+			Columns:     []Column{{Name: "id", Type: "integer"}, {Name: "user_id", Type: "integer"}, {Name: "body", Type: "text"}},
+			PrimaryKey:  []string{"id"},
+			ForeignKeys: []ForeignKey{{Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}}},
+		},
+		{Name: "tags", Columns: []Column{{Name: "id", Type: "integer"}}},
+	}}
+
+	// Without a threshold renames stay a removal plus an addition.
+	if d := DiffModels(old, newM); len(d.RenamedTables) != 0 || len(d.AddedTables) != 2 {
+		t.Fatalf("unexpected renames: %+v", d)
+	}
+
+	d := DiffModelsWith(old, newM, DiffOptions{RenameThreshold: DefaultRenameThreshold})
+	if len(d.RenamedTables) != 1 || d.RenamedTables[0].Old != "post" || d.RenamedTables[0].New != "posts" {
+		t.Fatalf("renamed tables = %+v", d.RenamedTables)
+	}
+	// Single-column tables are too alike to call renames.
+	if len(d.AddedTables) != 1 || d.AddedTables[0] != "tags" || len(d.RemovedTables) != 1 {
+		t.Fatalf("added %v, removed %v", d.AddedTables, d.RemovedTables)
+	}
+	if len(d.ChangedTables) != 1 {
+		t.Fatalf("changed tables = %+v", d.ChangedTables)
+	}
+	td := d.ChangedTables[0]
+	if len(td.RenamedColumns) != 1 || td.RenamedColumns[0].Old != "email" || td.RenamedColumns[0].New != "email_address" {
+		t.Fatalf("renamed columns = %+v", td.RenamedColumns)
+	}
+	if len(td.AddedColumns) != 0 || len(td.RemovedColumns) != 0 || len(td.Notes) != 0 {
+		t.Fatalf("a rename should not leave other changes: %+v", td)
+	}
+	// An unrelated column added elsewhere is no rename, even with the same type.
+	withBio := newM.Tables[0]
+	withBio.Columns = []Column{{Name: "id", Type: "integer"}, {Name: "email_address", Type: "text"}, {Name: "bio", Type: "text", Nullable: true}}
+	withNick := withBio
+	withNick.Columns = []Column{{Name: "id", Type: "integer"}, {Name: "nickname", Type: "text", Nullable: true}, {Name: "email_address", Type: "text"}}
+	nd := DiffModelsWith(&Model{Driver: "sqlite", Tables: []Table{withNick}}, &Model{Driver: "sqlite", Tables: []Table{withBio}},
+		DiffOptions{RenameThreshold: DefaultRenameThreshold})
+	if len(nd.ChangedTables) != 1 || len(nd.ChangedTables[0].RenamedColumns) != 0 || len(nd.ChangedTables[0].AddedColumns) != 1 {
+		t.Fatalf("nickname -> bio taken for a rename: %+v", nd.ChangedTables)
+	}
+	if js, _ := DiffJSON(nd); !strings.Contains(string(js), `"RenamedColumns": []`) {
+		t.Fatalf("json:\n%s", js)
+	}
+	if out := RenderDiff(d); !strings.Contains(out, "~ table post -> posts (renamed)") ||
+		!strings.Contains(out, "~ column email -> email_address (renamed)") {
+		t.Fatalf("text output:\n%s", out)
+	}
+
+	md, err := FormatDiff(d, "markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"| renamed | table `posts` | from post (100% similar) |", "| added | table `tags` |  |", "1 added, 1 removed, 2 renamed."} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	js, err := FormatDiff(DiffModels(newM, newM), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(js, `"AddedTables": []`) || !strings.Contains(js, `"RenamedTables": []`) ||
+		!strings.Contains(js, `"Objects": []`) || strings.Contains(js, "null") {
+		t.Fatalf("json:\n%s", js)
+	}
+
+	sarif, err := FormatDiff(d, "sarif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"version": "2.1.0"`, `"ruleId": "table-removed"`, `"level": "error"`, `"fullyQualifiedName": "users.email_address"`} {
+		if !strings.Contains(sarif, want) {
+			t.Fatalf("sarif missing %q:\n%s", want, sarif)
+		}
+	}
+	if _, err := FormatDiff(d, "xml"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
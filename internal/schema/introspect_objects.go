@@ -0,0 +1,369 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Schema objects besides tables: views, triggers, CHECK constraints and
+// comments on every driver, plus postgres sequences, enums and functions.
+
+// ---------------- SQLite ----------------
+
+func introspectSQLiteObjects(db *gorm.DB, m *Model) error {
+	type objRow struct {
+		Type    string `gorm:"column:type"`
+		Name    string `gorm:"column:name"`
+		TblName string `gorm:"column:tbl_name"`
+		SQL     string `gorm:"column:sql"`
+	}
+	var rows []objRow
+	if err := db.Raw(
+		`SELECT type, name, tbl_name, sql FROM sqlite_master
+		 WHERE type IN ('table', 'index', 'view', 'trigger') AND sql IS NOT NULL AND name NOT LIKE 'sqlite_%'
+		 ORDER BY name`,
+	).Scan(&rows).Error; err != nil {
+		return err
+	}
+	for _, r := range rows {
+		switch r.Type {
+		case "table":
+			if t := m.Table(r.Name); t != nil {
+				t.Checks = sqliteChecks(r.SQL)
+				sqliteForeignKeyNames(r.SQL, t)
+			}
+		case "index":
+			if t := m.Table(r.TblName); t != nil {
+				for i := range t.Indexes {
+					if t.Indexes[i].Name == r.Name {
+						sqliteIndexDetails(r.SQL, &t.Indexes[i])
+					}
+				}
+			}
+		case "view":
+			m.Views = append(m.Views, View{Name: r.Name, Definition: viewBody(r.SQL)})
+		case "trigger":
+			timing, event := triggerTimingEvent(r.SQL)
+			m.Triggers = append(m.Triggers, Trigger{Name: r.Name, Table: r.TblName, Timing: timing, Event: event, Definition: strings.TrimSpace(r.SQL)})
+		}
+	}
+	return nil
+}
+
+var createViewRe = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:TEMP(?:ORARY)?\s+)?VIEW\s+(?:IF\s+NOT\s+EXISTS\s+)?(?:"[^"]*"|` + "`[^`]*`" + `|\[[^\]]*\]|\S+?)(?:\s*\([^)]*\))?\s+AS\s+(.*)$`)
+
+// viewBody returns the SELECT of a CREATE VIEW statement.
+func viewBody(sql string) string {
+	if m := createViewRe.FindStringSubmatch(sql); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return strings.TrimSpace(sql)
+}
+
+var triggerTimingRe = regexp.MustCompile(`(?is)\b(BEFORE|AFTER|INSTEAD\s+OF)?\s*((?:INSERT|UPDATE|DELETE|TRUNCATE)(?:\s+OF\s+.+?)?(?:\s+OR\s+(?:INSERT|UPDATE|DELETE|TRUNCATE)(?:\s+OF\s+.+?)?)*)\s+ON\s`)
+
+// triggerTimingEvent reads the timing and events of a CREATE TRIGGER
+// statement. sqlite triggers without a timing run BEFORE.
+func triggerTimingEvent(sql string) (timing, event string) {
+	m := triggerTimingRe.FindStringSubmatch(sql)
+	if m == nil {
+		return "", ""
+	}
+	timing = strings.ToUpper(strings.Join(strings.Fields(m[1]), " "))
+	if timing == "" {
+		timing = "BEFORE"
+	}
+	var events []string
+	for _, part := range regexp.MustCompile(`(?i)\s+OR\s+`).Split(m[2], -1) {
+		events = append(events, strings.ToUpper(strings.Fields(part)[0]))
+	}
+	return timing, strings.Join(events, " OR ")
+}
+
+// ---------------- Postgres ----------------
+
+func introspectPostgresObjects(db *gorm.DB, m *Model, schema, current string) error {
+	type viewRow struct {
+		Name         string `gorm:"column:name"`
+		Definition   string `gorm:"column:definition"`
+		Materialized bool   `gorm:"column:materialized"`
+	}
+	var views []viewRow
+	if err := db.Raw(
+		`SELECT viewname AS name, definition, false AS materialized FROM pg_views WHERE schemaname = ?
+		 UNION ALL
+		 SELECT matviewname, definition, true FROM pg_matviews WHERE schemaname = ?
+		 ORDER BY name`, schema, schema,
+	).Scan(&views).Error; err != nil {
+		return fmt.Errorf("views: %w", err)
+	}
+	for _, v := range views {
+		m.Views = append(m.Views, View{Name: qualify(schema, current, v.Name), Materialized: v.Materialized, Definition: strings.TrimSuffix(strings.TrimSpace(v.Definition), ";")})
+	}
+
+	type trigRow struct {
+		Name       string `gorm:"column:name"`
+		Table      string `gorm:"column:table_name"`
+		Definition string `gorm:"column:definition"`
+	}
+	var trigs []trigRow
+	if err := db.Raw(
+		`SELECT t.tgname AS name, c.relname AS table_name, pg_get_triggerdef(t.oid) AS definition
+		 FROM pg_trigger t
+		 JOIN pg_class c ON c.oid = t.tgrelid
+		 JOIN pg_namespace n ON n.oid = c.relnamespace
+		 WHERE NOT t.tgisinternal AND n.nspname = ?
+		 ORDER BY t.tgname`, schema,
+	).Scan(&trigs).Error; err != nil {
+		return fmt.Errorf("triggers: %w", err)
+	}
+	for _, r := range trigs {
+		timing, event := triggerTimingEvent(r.Definition)
+		m.Triggers = append(m.Triggers, Trigger{Name: qualify(schema, current, r.Name), Table: qualify(schema, current, r.Table), Timing: timing, Event: event, Definition: r.Definition})
+	}
+
+	type seqRow struct {
+		Name        string  `gorm:"column:name"`
+		Start       int64   `gorm:"column:start"`
+		Increment   int64   `gorm:"column:increment"`
+		OwnerTable  *string `gorm:"column:owner_table"`
+		OwnerColumn *string `gorm:"column:owner_column"`
+	}
+	var seqs []seqRow
+	if err := db.Raw(
+		`SELECT c.relname AS name, s.seqstart AS start, s.seqincrement AS increment,
+		        t.relname AS owner_table, a.attname AS owner_column
+		 FROM pg_class c
+		 JOIN pg_namespace n ON n.oid = c.relnamespace
+		 JOIN pg_sequence s ON s.seqrelid = c.oid
+		 LEFT JOIN pg_depend d ON d.objid = c.oid AND d.classid = 'pg_class'::regclass
+		      AND d.refclassid = 'pg_class'::regclass AND d.deptype = 'a'
+		 LEFT JOIN pg_class t ON t.oid = d.refobjid
+		 LEFT JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = d.refobjsubid
+		 WHERE c.relkind = 'S' AND n.nspname = ?
+		   AND NOT EXISTS (SELECT 1 FROM pg_depend di WHERE di.objid = c.oid AND di.deptype = 'i')
+		 ORDER BY c.relname`, schema,
+	).Scan(&seqs).Error; err != nil {
+		return fmt.Errorf("sequences: %w", err)
+	}
+	for _, r := range seqs {
+		seq := Sequence{Name: qualify(schema, current, r.Name), Start: r.Start, Increment: r.Increment}
+		if r.OwnerTable != nil && r.OwnerColumn != nil {
+			seq.OwnedBy = qualify(schema, current, *r.OwnerTable) + "." + *r.OwnerColumn
+		}
+		m.Sequences = append(m.Sequences, seq)
+	}
+
+	type enumRow struct {
+		Name  string `gorm:"column:name"`
+		Label string `gorm:"column:label"`
+	}
+	var labels []enumRow
+	if err := db.Raw(
+		`SELECT t.typname AS name, e.enumlabel AS label
+		 FROM pg_type t
+		 JOIN pg_enum e ON e.enumtypid = t.oid
+		 JOIN pg_namespace n ON n.oid = t.typnamespace
+		 WHERE n.nspname = ?
+		 ORDER BY t.typname, e.enumsortorder`, schema,
+	).Scan(&labels).Error; err != nil {
+		return fmt.Errorf("enums: %w", err)
+	}
+	for _, r := range labels {
+		name := qualify(schema, current, r.Name)
+		if n := len(m.Enums); n == 0 || m.Enums[n-1].Name != name {
+			m.Enums = append(m.Enums, Enum{Name: name})
+		}
+		e := &m.Enums[len(m.Enums)-1]
+		e.Values = append(e.Values, r.Label)
+	}
+
+	type funcRow struct {
+		Name       string `gorm:"column:name"`
+		Arguments  string `gorm:"column:arguments"`
+		Definition string `gorm:"column:definition"`
+	}
+	var funcs []funcRow
+	if err := db.Raw(
+		`SELECT p.proname AS name, pg_get_function_identity_arguments(p.oid) AS arguments,
+		        pg_get_functiondef(p.oid) AS definition
+		 FROM pg_proc p
+		 JOIN pg_namespace n ON n.oid = p.pronamespace
+		 WHERE n.nspname = ? AND p.prokind IN ('f', 'p')
+		   AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.objid = p.oid AND d.deptype = 'e')
+		 ORDER BY p.proname, arguments`, schema,
+	).Scan(&funcs).Error; err != nil {
+		return fmt.Errorf("functions: %w", err)
+	}
+	for _, r := range funcs {
+		m.Functions = append(m.Functions, Function{Name: qualify(schema, current, r.Name), Arguments: r.Arguments, Definition: strings.TrimSpace(r.Definition)})
+	}
+
+	type checkRow struct {
+		Table      string `gorm:"column:table_name"`
+		Name       string `gorm:"column:name"`
+		Definition string `gorm:"column:definition"`
+	}
+	var checks []checkRow
+	if err := db.Raw(
+		`SELECT c.relname AS table_name, con.conname AS name, pg_get_constraintdef(con.oid) AS definition
+		 FROM pg_constraint con
+		 JOIN pg_class c ON c.oid = con.conrelid
+		 JOIN pg_namespace n ON n.oid = c.relnamespace
+		 WHERE con.contype = 'c' AND n.nspname = ?
+		 ORDER BY c.relname, con.conname`, schema,
+	).Scan(&checks).Error; err != nil {
+		return fmt.Errorf("check constraints: %w", err)
+	}
+	for _, r := range checks {
+		if t := m.Table(qualify(schema, current, r.Table)); t != nil {
+			t.Checks = append(t.Checks, Check{Name: r.Name, Expression: checkExpression(r.Definition)})
+		}
+	}
+
+	type commentRow struct {
+		Table       string  `gorm:"column:table_name"`
+		Column      *string `gorm:"column:column_name"`
+		Description string  `gorm:"column:description"`
+	}
+	var comments []commentRow
+	if err := db.Raw(
+		`SELECT c.relname AS table_name, a.attname AS column_name, d.description
+		 FROM pg_description d
+		 JOIN pg_class c ON c.oid = d.objoid
+		 JOIN pg_namespace n ON n.oid = c.relnamespace
+		 LEFT JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = d.objsubid AND d.objsubid > 0
+		 WHERE d.classoid = 'pg_class'::regclass AND c.relkind IN ('r', 'p') AND n.nspname = ?`, schema,
+	).Scan(&comments).Error; err != nil {
+		return fmt.Errorf("comments: %w", err)
+	}
+	for _, r := range comments {
+		setComment(m, qualify(schema, current, r.Table), r.Column, r.Description)
+	}
+	return nil
+}
+
+// checkExpression strips CHECK and the outer parentheses from a constraint
+// definition such as "CHECK ((price > 0))".
+func checkExpression(def string) string {
+	s := strings.TrimSpace(def)
+	if len(s) >= 5 && strings.EqualFold(s[:5], "CHECK") {
+		s = strings.TrimSpace(s[5:])
+	}
+	if i := strings.LastIndex(s, ")"); strings.HasPrefix(s, "(") && i > 0 {
+		// trailing NOT VALID / NO INHERIT stay out of the expression
+		s = s[1:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+func setComment(m *Model, table string, column *string, comment string) {
+	t := m.Table(table)
+	if t == nil || comment == "" {
+		return
+	}
+	if column == nil || *column == "" {
+		t.Comment = comment
+		return
+	}
+	for i := range t.Columns {
+		if t.Columns[i].Name == *column {
+			t.Columns[i].Comment = comment
+		}
+	}
+}
+
+// ---------------- MySQL ----------------
+
+func introspectMySQLObjects(db *gorm.DB, m *Model, schema, current string) error {
+	type viewRow struct {
+		Name       string `gorm:"column:name"`
+		Definition string `gorm:"column:definition"`
+	}
+	var views []viewRow
+	if err := db.Raw(
+		`SELECT table_name AS name, view_definition AS definition
+		 FROM information_schema.views
+		 WHERE table_schema = ?
+		 ORDER BY table_name`, schema,
+	).Scan(&views).Error; err != nil {
+		return fmt.Errorf("views: %w", err)
+	}
+	for _, v := range views {
+		m.Views = append(m.Views, View{Name: qualify(schema, current, v.Name), Definition: strings.TrimSpace(v.Definition)})
+	}
+
+	type trigRow struct {
+		Name      string `gorm:"column:name"`
+		Table     string `gorm:"column:table_name"`
+		Timing    string `gorm:"column:timing"`
+		Event     string `gorm:"column:event"`
+		Statement string `gorm:"column:statement"`
+	}
+	var trigs []trigRow
+	if err := db.Raw(
+		`SELECT trigger_name AS name, event_object_table AS table_name, action_timing AS timing,
+		        event_manipulation AS event, action_statement AS statement
+		 FROM information_schema.triggers
+		 WHERE trigger_schema = ?
+		 ORDER BY trigger_name`, schema,
+	).Scan(&trigs).Error; err != nil {
+		return fmt.Errorf("triggers: %w", err)
+	}
+	q := m.nameQuoter()
+	for _, r := range trigs {
+		r.Name, r.Table = qualify(schema, current, r.Name), qualify(schema, current, r.Table)
+		m.Triggers = append(m.Triggers, Trigger{
+			Name: r.Name, Table: r.Table, Timing: r.Timing, Event: r.Event,
+			Definition: fmt.Sprintf("CREATE TRIGGER %s %s %s ON %s FOR EACH ROW %s", q(r.Name), r.Timing, r.Event, q(r.Table), strings.TrimSpace(r.Statement)),
+		})
+	}
+
+	type checkRow struct {
+		Table  string `gorm:"column:table_name"`
+		Name   string `gorm:"column:name"`
+		Clause string `gorm:"column:clause"`
+	}
+	var checks []checkRow
+	// CHECK constraints are in information_schema from MySQL 8.0.16; older
+	// servers do not enforce them and have no such table.
+	if err := db.Raw(
+		`SELECT tc.table_name AS table_name, cc.constraint_name AS name, cc.check_clause AS clause
+		 FROM information_schema.table_constraints tc
+		 JOIN information_schema.check_constraints cc
+		   ON cc.constraint_schema = tc.constraint_schema AND cc.constraint_name = tc.constraint_name
+		 WHERE tc.table_schema = ? AND tc.constraint_type = 'CHECK'
+		 ORDER BY tc.table_name, cc.constraint_name`, schema,
+	).Scan(&checks).Error; err == nil {
+		for _, r := range checks {
+			if t := m.Table(qualify(schema, current, r.Table)); t != nil {
+				t.Checks = append(t.Checks, Check{Name: r.Name, Expression: checkExpression(r.Clause)})
+			}
+		}
+	}
+
+	type commentRow struct {
+		Table   string  `gorm:"column:table_name"`
+		Column  *string `gorm:"column:column_name"`
+		Comment string  `gorm:"column:comment"`
+	}
+	var comments []commentRow
+	if err := db.Raw(
+		`SELECT table_name, NULL AS column_name, table_comment AS comment
+		 FROM information_schema.tables
+		 WHERE table_schema = ? AND table_type = 'BASE TABLE' AND table_comment <> ''
+		 UNION ALL
+		 SELECT table_name, column_name, column_comment
+		 FROM information_schema.columns
+		 WHERE table_schema = ? AND column_comment <> ''`, schema, schema,
+	).Scan(&comments).Error; err != nil {
+		return fmt.Errorf("comments: %w", err)
+	}
+	for _, r := range comments {
+		setComment(m, qualify(schema, current, r.Table), r.Column, r.Comment)
+	}
+	return nil
+}
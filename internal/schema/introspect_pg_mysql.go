@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"fmt"
 	"strings"
 
 	"gorm.io/gorm"
@@ -8,246 +9,340 @@ import (
 
 // ---------------- Postgres ----------------
 
-func introspectPostgres(db *gorm.DB) (*Model, error) {
+func introspectPostgres(db *gorm.DB, schemas []string) (*Model, error) {
 	m := &Model{Driver: "postgres"}
-
-	var tableNames []string
-	if err := db.Raw(
-		`SELECT table_name FROM information_schema.tables
-		 WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
-		 ORDER BY table_name`,
-	).Scan(&tableNames).Error; err != nil {
+	var current string
+	if err := db.Raw("SELECT current_schema()").Scan(&current).Error; err != nil {
 		return nil, err
 	}
+	if len(schemas) == 0 {
+		schemas = []string{current}
+	} else {
+		m.Schemas = schemas
+	}
 
-	for _, name := range tableNames {
-		t := Table{Name: name}
-
-		type colRow struct {
-			Name     string  `gorm:"column:column_name"`
-			Type     string  `gorm:"column:data_type"`
-			Nullable string  `gorm:"column:is_nullable"`
-			Default  *string `gorm:"column:column_default"`
-		}
-		var cols []colRow
+	for _, schema := range schemas {
+		var tableNames []string
 		if err := db.Raw(
-			`SELECT column_name, data_type, is_nullable, column_default
-			 FROM information_schema.columns
-			 WHERE table_schema = current_schema() AND table_name = ?
-			 ORDER BY ordinal_position`, name,
-		).Scan(&cols).Error; err != nil {
-			return nil, err
+			`SELECT table_name FROM information_schema.tables
+			 WHERE table_schema = ? AND table_type = 'BASE TABLE'
+			 ORDER BY table_name`, schema,
+		).Scan(&tableNames).Error; err != nil {
+			return nil, fmt.Errorf("%s: %w", schema, err)
 		}
-		for _, c := range cols {
-			def := ""
-			if c.Default != nil {
-				def = *c.Default
+
+		for _, name := range tableNames {
+			t := Table{Name: qualify(schema, current, name)}
+
+			type colRow struct {
+				Name     string  `gorm:"column:column_name"`
+				Type     string  `gorm:"column:data_type"`
+				Nullable string  `gorm:"column:is_nullable"`
+				Default  *string `gorm:"column:column_default"`
+			}
+			var cols []colRow
+			if err := db.Raw(
+				`SELECT column_name, data_type, is_nullable, column_default
+				 FROM information_schema.columns
+				 WHERE table_schema = ? AND table_name = ?
+				 ORDER BY ordinal_position`, schema, name,
+			).Scan(&cols).Error; err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", schema, name, err)
+			}
+			for _, c := range cols {
+				def := ""
+				if c.Default != nil {
+					def = *c.Default
+				}
+				t.Columns = append(t.Columns, Column{
+					Name:     c.Name,
+					Type:     c.Type,
+					Nullable: strings.EqualFold(c.Nullable, "YES"),
+					Default:  def,
+				})
 			}
-			t.Columns = append(t.Columns, Column{
-				Name:     c.Name,
-				Type:     c.Type,
-				Nullable: strings.EqualFold(c.Nullable, "YES"),
-				Default:  def,
-			})
-		}
 
-		var pk []string
-		if err := db.Raw(
-			`SELECT kcu.column_name
-			 FROM information_schema.table_constraints tc
-			 JOIN information_schema.key_column_usage kcu
-			   ON tc.constraint_name = kcu.constraint_name
-			  AND tc.table_schema = kcu.table_schema
-			 WHERE tc.constraint_type = 'PRIMARY KEY'
-			   AND tc.table_schema = current_schema() AND tc.table_name = ?
-			 ORDER BY kcu.ordinal_position`, name,
-		).Scan(&pk).Error; err != nil {
-			return nil, err
-		}
-		t.PrimaryKey = pk
+			var pk []string
+			if err := db.Raw(
+				`SELECT kcu.column_name
+				 FROM information_schema.table_constraints tc
+				 JOIN information_schema.key_column_usage kcu
+				   ON tc.constraint_name = kcu.constraint_name
+				  AND tc.table_schema = kcu.table_schema
+				 WHERE tc.constraint_type = 'PRIMARY KEY'
+				   AND tc.table_schema = ? AND tc.table_name = ?
+				 ORDER BY kcu.ordinal_position`, schema, name,
+			).Scan(&pk).Error; err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", schema, name, err)
+			}
+			t.PrimaryKey = pk
 
-		type fkRow struct {
-			Constraint string `gorm:"column:constraint_name"`
-			FromCol    string `gorm:"column:from_col"`
-			RefTable   string `gorm:"column:ref_table"`
-			RefCol     string `gorm:"column:ref_col"`
-		}
-		var fkRows []fkRow
-		if err := db.Raw(
-			`SELECT tc.constraint_name,
-			        kcu.column_name AS from_col,
-			        ccu.table_name  AS ref_table,
-			        ccu.column_name AS ref_col
-			 FROM information_schema.table_constraints tc
-			 JOIN information_schema.key_column_usage kcu
-			   ON tc.constraint_name = kcu.constraint_name AND tc.table_schema = kcu.table_schema
-			 JOIN information_schema.constraint_column_usage ccu
-			   ON ccu.constraint_name = tc.constraint_name AND ccu.table_schema = tc.table_schema
-			 WHERE tc.constraint_type = 'FOREIGN KEY'
-			   AND tc.table_schema = current_schema() AND tc.table_name = ?
-			 ORDER BY tc.constraint_name, kcu.ordinal_position`, name,
-		).Scan(&fkRows).Error; err != nil {
-			return nil, err
-		}
-		gfk := make([]genericFK, 0, len(fkRows))
-		for _, r := range fkRows {
-			gfk = append(gfk, genericFK{Constraint: r.Constraint, FromCol: r.FromCol, RefTable: r.RefTable, RefCol: r.RefCol})
-		}
-		t.ForeignKeys = groupForeignKeys(gfk)
+			type fkRow struct {
+				Constraint string `gorm:"column:constraint_name"`
+				FromCol    string `gorm:"column:from_col"`
+				RefSchema  string `gorm:"column:ref_schema"`
+				RefTable   string `gorm:"column:ref_table"`
+				RefCol     string `gorm:"column:ref_col"`
+				OnDelete   string `gorm:"column:on_delete"`
+				OnUpdate   string `gorm:"column:on_update"`
+			}
+			var fkRows []fkRow
+			if err := db.Raw(
+				`SELECT con.conname AS constraint_name,
+				        a.attname AS from_col,
+				        rn.nspname AS ref_schema,
+				        rt.relname AS ref_table,
+				        ra.attname AS ref_col,
+				        con.confdeltype::text AS on_delete,
+				        con.confupdtype::text AS on_update
+				 FROM pg_constraint con
+				 JOIN pg_class t ON t.oid = con.conrelid
+				 JOIN pg_namespace n ON n.oid = t.relnamespace
+				 JOIN pg_class rt ON rt.oid = con.confrelid
+				 JOIN pg_namespace rn ON rn.oid = rt.relnamespace
+				 CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, ord)
+				 JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				 JOIN pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.refattnum
+				 WHERE con.contype = 'f' AND n.nspname = ? AND t.relname = ?
+				 ORDER BY con.conname, k.ord`, schema, name,
+			).Scan(&fkRows).Error; err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", schema, name, err)
+			}
+			gfk := make([]genericFK, 0, len(fkRows))
+			for _, r := range fkRows {
+				gfk = append(gfk, genericFK{Constraint: r.Constraint, FromCol: r.FromCol, RefTable: qualify(r.RefSchema, current, r.RefTable), RefCol: r.RefCol, OnDelete: r.OnDelete, OnUpdate: r.OnUpdate})
+			}
+			t.ForeignKeys = groupForeignKeys(gfk)
 
-		type idxRow struct {
-			IndexName string `gorm:"column:index_name"`
-			IsUnique  bool   `gorm:"column:is_unique"`
-			Column    string `gorm:"column:column_name"`
-		}
-		var idxRows []idxRow
-		if err := db.Raw(
-			`SELECT i.relname AS index_name,
-			        ix.indisunique AS is_unique,
-			        a.attname AS column_name,
-			        array_position(ix.indkey, a.attnum) AS ord
-			 FROM pg_class t
-			 JOIN pg_index ix ON t.oid = ix.indrelid
-			 JOIN pg_class i ON i.oid = ix.indexrelid
-			 JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(ix.indkey)
-			 WHERE t.relkind = 'r' AND t.relname = ? AND NOT ix.indisprimary
-			 ORDER BY i.relname, ord`, name,
-		).Scan(&idxRows).Error; err != nil {
-			return nil, err
-		}
-		gidx := make([]genericIdx, 0, len(idxRows))
-		for _, r := range idxRows {
-			gidx = append(gidx, genericIdx{Name: r.IndexName, Unique: r.IsUnique, Column: r.Column})
+			type idxRow struct {
+				IndexName string `gorm:"column:index_name"`
+				IsUnique  bool   `gorm:"column:is_unique"`
+				Method    string `gorm:"column:method"`
+				Predicate string `gorm:"column:predicate"`
+				Part      string `gorm:"column:part"`
+				IsExpr    bool   `gorm:"column:is_expr"`
+				IsDesc    bool   `gorm:"column:is_desc"`
+			}
+			var idxRows []idxRow
+			// indkey and indoption are int2vectors, which subscript from 0.
+			if err := db.Raw(
+				`SELECT i.relname AS index_name,
+				        ix.indisunique AS is_unique,
+				        am.amname AS method,
+				        COALESCE(pg_get_expr(ix.indpred, ix.indrelid), '') AS predicate,
+				        pg_get_indexdef(ix.indexrelid, k.n, true) AS part,
+				        ix.indkey[k.n - 1] = 0 AS is_expr,
+				        (ix.indoption[k.n - 1] & 1) = 1 AS is_desc
+				 FROM pg_class t
+				 JOIN pg_namespace ns ON ns.oid = t.relnamespace
+				 JOIN pg_index ix ON t.oid = ix.indrelid
+				 JOIN pg_class i ON i.oid = ix.indexrelid
+				 JOIN pg_am am ON am.oid = i.relam
+				 CROSS JOIN LATERAL generate_series(1, ix.indnkeyatts) AS k(n)
+				 WHERE t.relkind = 'r' AND t.relname = ? AND ns.nspname = ? AND NOT ix.indisprimary
+				 ORDER BY i.relname, k.n`, name, schema,
+			).Scan(&idxRows).Error; err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", schema, name, err)
+			}
+			gidx := make([]genericIdx, 0, len(idxRows))
+			for _, r := range idxRows {
+				col := r.Part
+				if !r.IsExpr {
+					col = unquoteIdent(col)
+				}
+				gidx = append(gidx, genericIdx{Name: r.IndexName, Unique: r.IsUnique, Column: col,
+					Method: r.Method, Where: r.Predicate, Expression: r.IsExpr, Descending: r.IsDesc})
+			}
+			t.Indexes = groupIndexes(gidx)
+
+			m.Tables = append(m.Tables, t)
 		}
-		t.Indexes = groupIndexes(gidx)
 
-		m.Tables = append(m.Tables, t)
 	}
 
 	sortTables(m.Tables)
+	for _, schema := range schemas {
+		if err := introspectPostgresObjects(db, m, schema, current); err != nil {
+			return nil, fmt.Errorf("%s: %w", schema, err)
+		}
+	}
 	return m, nil
 }
 
 // ---------------- MySQL ----------------
 
-func introspectMySQL(db *gorm.DB) (*Model, error) {
+func introspectMySQL(db *gorm.DB, schemas []string) (*Model, error) {
 	m := &Model{Driver: "mysql"}
-
-	var tableNames []string
-	if err := db.Raw(
-		`SELECT table_name FROM information_schema.tables
-		 WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE'
-		 ORDER BY table_name`,
-	).Scan(&tableNames).Error; err != nil {
+	var current string
+	if err := db.Raw("SELECT DATABASE()").Scan(&current).Error; err != nil {
 		return nil, err
 	}
+	if len(schemas) == 0 {
+		schemas = []string{current}
+	} else {
+		m.Schemas = schemas
+	}
 
-	for _, name := range tableNames {
-		t := Table{Name: name}
-
-		type colRow struct {
-			Name     string  `gorm:"column:column_name"`
-			Type     string  `gorm:"column:column_type"`
-			Nullable string  `gorm:"column:is_nullable"`
-			Default  *string `gorm:"column:column_default"`
-		}
-		var cols []colRow
+	for _, schema := range schemas {
+		var tableNames []string
 		if err := db.Raw(
-			`SELECT column_name, column_type, is_nullable, column_default
-			 FROM information_schema.columns
-			 WHERE table_schema = DATABASE() AND table_name = ?
-			 ORDER BY ordinal_position`, name,
-		).Scan(&cols).Error; err != nil {
-			return nil, err
+			`SELECT table_name FROM information_schema.tables
+			 WHERE table_schema = ? AND table_type = 'BASE TABLE'
+			 ORDER BY table_name`, schema,
+		).Scan(&tableNames).Error; err != nil {
+			return nil, fmt.Errorf("%s: %w", schema, err)
 		}
-		for _, c := range cols {
-			def := ""
-			if c.Default != nil {
-				def = *c.Default
+
+		for _, name := range tableNames {
+			t := Table{Name: qualify(schema, current, name)}
+
+			type colRow struct {
+				Name     string  `gorm:"column:column_name"`
+				Type     string  `gorm:"column:column_type"`
+				Nullable string  `gorm:"column:is_nullable"`
+				Default  *string `gorm:"column:column_default"`
+			}
+			var cols []colRow
+			if err := db.Raw(
+				`SELECT column_name, column_type, is_nullable, column_default
+				 FROM information_schema.columns
+				 WHERE table_schema = ? AND table_name = ?
+				 ORDER BY ordinal_position`, schema, name,
+			).Scan(&cols).Error; err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", schema, name, err)
+			}
+			for _, c := range cols {
+				def := ""
+				if c.Default != nil {
+					def = *c.Default
+				}
+				t.Columns = append(t.Columns, Column{
+					Name:     c.Name,
+					Type:     c.Type,
+					Nullable: strings.EqualFold(c.Nullable, "YES"),
+					Default:  def,
+				})
 			}
-			t.Columns = append(t.Columns, Column{
-				Name:     c.Name,
-				Type:     c.Type,
-				Nullable: strings.EqualFold(c.Nullable, "YES"),
-				Default:  def,
-			})
-		}
 
-		var pk []string
-		if err := db.Raw(
-			`SELECT column_name FROM information_schema.key_column_usage
-			 WHERE table_schema = DATABASE() AND table_name = ? AND constraint_name = 'PRIMARY'
-			 ORDER BY ordinal_position`, name,
-		).Scan(&pk).Error; err != nil {
-			return nil, err
-		}
-		t.PrimaryKey = pk
+			var pk []string
+			if err := db.Raw(
+				`SELECT column_name FROM information_schema.key_column_usage
+				 WHERE table_schema = ? AND table_name = ? AND constraint_name = 'PRIMARY'
+				 ORDER BY ordinal_position`, schema, name,
+			).Scan(&pk).Error; err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", schema, name, err)
+			}
+			t.PrimaryKey = pk
 
-		type fkRow struct {
-			Constraint string `gorm:"column:constraint_name"`
-			FromCol    string `gorm:"column:from_col"`
-			RefTable   string `gorm:"column:ref_table"`
-			RefCol     string `gorm:"column:ref_col"`
-		}
-		var fkRows []fkRow
-		if err := db.Raw(
-			`SELECT constraint_name,
-			        column_name AS from_col,
-			        referenced_table_name AS ref_table,
-			        referenced_column_name AS ref_col
-			 FROM information_schema.key_column_usage
-			 WHERE table_schema = DATABASE() AND table_name = ?
-			   AND referenced_table_name IS NOT NULL
-			 ORDER BY constraint_name, ordinal_position`, name,
-		).Scan(&fkRows).Error; err != nil {
-			return nil, err
-		}
-		gfk := make([]genericFK, 0, len(fkRows))
-		for _, r := range fkRows {
-			gfk = append(gfk, genericFK{Constraint: r.Constraint, FromCol: r.FromCol, RefTable: r.RefTable, RefCol: r.RefCol})
-		}
-		t.ForeignKeys = groupForeignKeys(gfk)
+			type fkRow struct {
+				Constraint string `gorm:"column:constraint_name"`
+				FromCol    string `gorm:"column:from_col"`
+				RefSchema  string `gorm:"column:ref_schema"`
+				RefTable   string `gorm:"column:ref_table"`
+				RefCol     string `gorm:"column:ref_col"`
+				OnDelete   string `gorm:"column:on_delete"`
+				OnUpdate   string `gorm:"column:on_update"`
+			}
+			var fkRows []fkRow
+			if err := db.Raw(
+				`SELECT kcu.constraint_name,
+				        kcu.column_name AS from_col,
+				        kcu.referenced_table_schema AS ref_schema,
+				        kcu.referenced_table_name AS ref_table,
+				        kcu.referenced_column_name AS ref_col,
+				        rc.delete_rule AS on_delete,
+				        rc.update_rule AS on_update
+				 FROM information_schema.key_column_usage kcu
+				 JOIN information_schema.referential_constraints rc
+				   ON rc.constraint_schema = kcu.table_schema AND rc.constraint_name = kcu.constraint_name
+				  AND rc.table_name = kcu.table_name
+				 WHERE kcu.table_schema = ? AND kcu.table_name = ?
+				   AND kcu.referenced_table_name IS NOT NULL
+				 ORDER BY kcu.constraint_name, kcu.ordinal_position`, schema, name,
+			).Scan(&fkRows).Error; err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", schema, name, err)
+			}
+			gfk := make([]genericFK, 0, len(fkRows))
+			for _, r := range fkRows {
+				gfk = append(gfk, genericFK{Constraint: r.Constraint, FromCol: r.FromCol, RefTable: qualify(r.RefSchema, current, r.RefTable), RefCol: r.RefCol, OnDelete: r.OnDelete, OnUpdate: r.OnUpdate})
+			}
+			t.ForeignKeys = groupForeignKeys(gfk)
 
-		type idxRow struct {
-			IndexName string `gorm:"column:index_name"`
-			NonUnique int    `gorm:"column:non_unique"`
-			Column    string `gorm:"column:column_name"`
-		}
-		var idxRows []idxRow
-		if err := db.Raw(
-			`SELECT index_name, non_unique, column_name
-			 FROM information_schema.statistics
-			 WHERE table_schema = DATABASE() AND table_name = ? AND index_name != 'PRIMARY'
-			 ORDER BY index_name, seq_in_index`, name,
-		).Scan(&idxRows).Error; err != nil {
-			return nil, err
-		}
-		generic := make([]genericIdx, 0, len(idxRows))
-		for _, r := range idxRows {
-			generic = append(generic, genericIdx{Name: r.IndexName, Unique: r.NonUnique == 0, Column: r.Column})
+			type idxRow struct {
+				IndexName  string  `gorm:"column:index_name"`
+				NonUnique  int     `gorm:"column:non_unique"`
+				Column     *string `gorm:"column:column_name"`
+				Expression *string `gorm:"column:expression"`
+				Collation  *string `gorm:"column:collation"`
+				IndexType  string  `gorm:"column:index_type"`
+			}
+			var idxRows []idxRow
+			// statistics.expression (functional key parts) exists from MySQL 8.0.13.
+			idxQuery := `SELECT index_name, non_unique, column_name, %s AS expression, collation, index_type
+				 FROM information_schema.statistics
+				 WHERE table_schema = ? AND table_name = ? AND index_name != 'PRIMARY'
+				 ORDER BY index_name, seq_in_index`
+			if err := db.Raw(fmt.Sprintf(idxQuery, "expression"), schema, name).Scan(&idxRows).Error; err != nil {
+				idxRows = nil
+				if err := db.Raw(fmt.Sprintf(idxQuery, "NULL"), schema, name).Scan(&idxRows).Error; err != nil {
+					return nil, fmt.Errorf("%s.%s: %w", schema, name, err)
+				}
+			}
+			generic := make([]genericIdx, 0, len(idxRows))
+			for _, r := range idxRows {
+				g := genericIdx{Name: r.IndexName, Unique: r.NonUnique == 0, Method: r.IndexType,
+					Descending: r.Collation != nil && *r.Collation == "D"}
+				if r.Column != nil {
+					g.Column = *r.Column
+				} else if r.Expression != nil {
+					g.Column, g.Expression = *r.Expression, true
+				}
+				generic = append(generic, g)
+			}
+			t.Indexes = groupIndexes(generic)
+
+			m.Tables = append(m.Tables, t)
 		}
-		t.Indexes = groupIndexes(generic)
 
-		m.Tables = append(m.Tables, t)
 	}
 
 	sortTables(m.Tables)
+	for _, schema := range schemas {
+		if err := introspectMySQLObjects(db, m, schema, current); err != nil {
+			return nil, fmt.Errorf("%s: %w", schema, err)
+		}
+	}
 	return m, nil
 }
 
 // ---------------- shared grouping helpers ----------------
 
+// qualify prefixes name with its schema unless that is the connection's
+// current schema (postgres) or database (mysql).
+func qualify(schema, current, name string) string {
+	if schema == current || schema == "" {
+		return name
+	}
+	return schema + "." + name
+}
+
 type genericFK struct {
 	Constraint string
 	FromCol    string
 	RefTable   string
 	RefCol     string
+	OnDelete   string
+	OnUpdate   string
 }
 
 type genericIdx struct {
-	Name   string
-	Unique bool
-	Column string
+	Name       string
+	Unique     bool
+	Column     string
+	Method     string
+	Where      string
+	Expression bool
+	Descending bool
 }
 
 func groupForeignKeys(rows []genericFK) []ForeignKey {
@@ -256,7 +351,7 @@ func groupForeignKeys(rows []genericFK) []ForeignKey {
 	for _, r := range rows {
 		fk, ok := byName[r.Constraint]
 		if !ok {
-			fk = &ForeignKey{RefTable: r.RefTable}
+			fk = &ForeignKey{Name: r.Constraint, RefTable: r.RefTable, OnDelete: fkAction(r.OnDelete), OnUpdate: fkAction(r.OnUpdate)}
 			byName[r.Constraint] = fk
 			order = append(order, r.Constraint)
 		}
@@ -276,11 +371,11 @@ func groupIndexes(rows []genericIdx) []Index {
 	for _, r := range rows {
 		ix, ok := byName[r.Name]
 		if !ok {
-			ix = &Index{Name: r.Name, Unique: r.Unique}
+			ix = &Index{Name: r.Name, Unique: r.Unique, Method: indexMethod(r.Method), Where: r.Where}
 			byName[r.Name] = ix
 			order = append(order, r.Name)
 		}
-		ix.Columns = append(ix.Columns, r.Column)
+		ix.addPart(r.Column, r.Expression, r.Descending)
 	}
 	var out []Index
 	for _, n := range order {
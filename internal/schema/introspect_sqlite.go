@@ -75,6 +75,8 @@ func introspectSQLite(db *gorm.DB) (*Model, error) {
 			Table string `gorm:"column:table"`
 			From  string `gorm:"column:from"`
 			To    string `gorm:"column:to"`
+			OnUpdate string `gorm:"column:on_update"`
+			OnDelete string `gorm:"column:on_delete"`
 		}
 		var fks []fkInfo
 		if err := db.Raw(fmt.Sprintf("PRAGMA foreign_key_list(%q)", name)).Scan(&fks).Error; err != nil {
@@ -85,7 +87,7 @@ func introspectSQLite(db *gorm.DB) (*Model, error) {
 		for _, fk := range fks {
 			cur, ok := fkByID[fk.ID]
 			if !ok {
-				cur = &ForeignKey{RefTable: fk.Table}
+				cur = &ForeignKey{RefTable: fk.Table, OnDelete: fkAction(fk.OnDelete), OnUpdate: fkAction(fk.OnUpdate)}
 				fkByID[fk.ID] = cur
 				fkOrder = append(fkOrder, fk.ID)
 			}
@@ -131,5 +133,8 @@ func introspectSQLite(db *gorm.DB) (*Model, error) {
 	}
 
 	sortTables(m.Tables)
+	if err := introspectSQLiteObjects(db, m); err != nil {
+		return nil, err
+	}
 	return m, nil
 }
@@ -0,0 +1,234 @@
+package schema
+
+import "strings"
+
+// sqlite keeps the DDL of every table and index in sqlite_master; the parts
+// its pragmas do not report (constraint names, CHECKs, index expressions and
+// predicates) are read from that text.
+
+type sqlToken struct {
+	text string
+	pos  int
+}
+
+// sqlTokens splits SQL into words, quoted strings and names, and single
+// punctuation characters; whitespace and comments are dropped.
+func sqlTokens(sql string) []sqlToken {
+	var out []sqlToken
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(sql)
+			}
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			j := i + 1
+			for j < len(sql) {
+				if sql[j] == closing {
+					if closing != ']' && j+1 < len(sql) && sql[j+1] == closing {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			out = append(out, sqlToken{sql[i:min(j+1, len(sql))], i})
+			i = j + 1
+		case isWordByte(c):
+			j := i
+			for j < len(sql) && isWordByte(sql[j]) {
+				j++
+			}
+			out = append(out, sqlToken{sql[i:j], i})
+			i = j
+		default:
+			out = append(out, sqlToken{sql[i : i+1], i})
+			i++
+		}
+	}
+	return out
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c == '.' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func unquoteIdent(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '`' || s[0] == '[') {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// matchParen returns the index of the ")" closing the "(" at toks[open], or
+// len(toks) when it is not closed.
+func matchParen(toks []sqlToken, open int) int {
+	depth := 0
+	for j := open; j < len(toks); j++ {
+		switch toks[j].text {
+		case "(":
+			depth++
+		case ")":
+			if depth--; depth == 0 {
+				return j
+			}
+		}
+	}
+	return len(toks)
+}
+
+// splitList splits toks[from:to] at the commas outside parentheses.
+func splitList(toks []sqlToken, from, to int) [][]sqlToken {
+	var out [][]sqlToken
+	depth, start := 0, from
+	for j := from; j < to; j++ {
+		switch toks[j].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case ",":
+			if depth == 0 {
+				out = append(out, toks[start:j])
+				start = j + 1
+			}
+		}
+	}
+	if start < to {
+		out = append(out, toks[start:to])
+	}
+	return out
+}
+
+// tokenText is the source text spanned by toks, whitespace collapsed.
+func tokenText(sql string, toks []sqlToken) string {
+	if len(toks) == 0 {
+		return ""
+	}
+	last := toks[len(toks)-1]
+	return strings.Join(strings.Fields(sql[toks[0].pos:last.pos+len(last.text)]), " ")
+}
+
+// sqliteChecks finds the CHECK constraints, on columns or the table, in a
+// CREATE TABLE statement.
+func sqliteChecks(sql string) []Check {
+	var out []Check
+	toks := sqlTokens(sql)
+	for i := 0; i+1 < len(toks); i++ {
+		if !strings.EqualFold(toks[i].text, "CHECK") || toks[i+1].text != "(" {
+			continue
+		}
+		name := ""
+		if i >= 2 && strings.EqualFold(toks[i-2].text, "CONSTRAINT") {
+			name = unquoteIdent(toks[i-1].text)
+		}
+		j := matchParen(toks, i+1)
+		if j == len(toks) {
+			break
+		}
+		out = append(out, Check{Name: name, Expression: tokenText(sql, toks[i+2:j])})
+		i = j
+	}
+	return out
+}
+
+// sqliteForeignKeyNames names the foreign keys of t declared with
+// CONSTRAINT name, on the table or on a column.
+func sqliteForeignKeyNames(sql string, t *Table) {
+	toks := sqlTokens(sql)
+	open := 0
+	for open < len(toks) && toks[open].text != "(" {
+		open++
+	}
+	if open == len(toks) {
+		return
+	}
+	for _, def := range splitList(toks, open+1, matchParen(toks, open)) {
+		if len(def) == 0 {
+			continue
+		}
+		var name string
+		var cols []string
+		if strings.EqualFold(def[0].text, "CONSTRAINT") && len(def) > 4 && strings.EqualFold(def[2].text, "FOREIGN") && def[4].text == "(" {
+			name = unquoteIdent(def[1].text)
+			for _, part := range splitList(def, 5, matchParen(def, 4)) {
+				cols = append(cols, unquoteIdent(tokenText(sql, part)))
+			}
+		} else {
+			for j := 1; j+2 < len(def); j++ {
+				if strings.EqualFold(def[j].text, "CONSTRAINT") && strings.EqualFold(def[j+2].text, "REFERENCES") {
+					name, cols = unquoteIdent(def[j+1].text), []string{unquoteIdent(def[0].text)}
+				}
+			}
+		}
+		if name == "" {
+			continue
+		}
+		for i := range t.ForeignKeys {
+			if fk := &t.ForeignKeys[i]; fk.Name == "" && equalStrings(fk.Columns, cols) {
+				fk.Name = name
+				break
+			}
+		}
+	}
+}
+
+// sqliteIndexDetails reads the parts, sort order and WHERE clause of ix from
+// its CREATE INDEX statement.
+func sqliteIndexDetails(sql string, ix *Index) {
+	toks := sqlTokens(sql)
+	open := 0
+	for open < len(toks) && !strings.EqualFold(toks[open].text, "ON") {
+		open++
+	}
+	for open < len(toks) && toks[open].text != "(" {
+		open++
+	}
+	if open == len(toks) {
+		return
+	}
+	closing := matchParen(toks, open)
+	if closing == len(toks) {
+		return
+	}
+
+	parsed := Index{Name: ix.Name, Unique: ix.Unique}
+	for _, part := range splitList(toks, open+1, closing) {
+		desc := false
+		for len(part) > 1 {
+			last := strings.ToUpper(part[len(part)-1].text)
+			if last == "DESC" || last == "ASC" {
+				desc = desc || last == "DESC"
+				part = part[:len(part)-1]
+			} else if len(part) > 2 && strings.EqualFold(part[len(part)-2].text, "COLLATE") {
+				part = part[:len(part)-2]
+			} else {
+				break
+			}
+		}
+		if len(part) == 1 && part[0].text[0] != '\'' {
+			parsed.addPart(unquoteIdent(part[0].text), false, desc)
+		} else {
+			parsed.addPart(tokenText(sql, part), true, desc)
+		}
+	}
+	if closing+1 < len(toks) && strings.EqualFold(toks[closing+1].text, "WHERE") && closing+2 < len(toks) {
+		parsed.Where = strings.TrimSuffix(tokenText(sql, toks[closing+2:]), ";")
+	}
+	*ix = parsed
+}
@@ -0,0 +1,326 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLintFile is where schema:lint looks for its configuration.
+const DefaultLintFile = "database/schema-lint.yaml"
+
+// Severity ranks lint findings. SeverityOff disables a rule.
+type Severity int
+
+const (
+	SeverityOff Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = []string{"off", "info", "warning", "error"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// MarshalText writes the severity by name, e.g. in JSON output.
+func (s Severity) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// ParseSeverity reads off, info, warning (warn) or error.
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "off", "none", "false":
+		return SeverityOff, nil
+	case "info":
+		return SeverityInfo, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "error":
+		return SeverityError, nil
+	}
+	return SeverityOff, fmt.Errorf("unknown severity %q (use: off, info, warning, error)", s)
+}
+
+// LintRule is a named check over one table. Check reports problems with Table,
+// Column and Message set; the engine fills in the rule and severity.
+type LintRule struct {
+	Name        string
+	Description string
+	Severity    Severity // default severity
+	Check       func(m *Model, t Table) []LintFinding
+}
+
+// LintFinding is one problem found by schema:lint.
+type LintFinding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Table    string   `json:"table"`
+	Column   string   `json:"column,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// Object is the table or table.column the finding is about.
+func (f LintFinding) Object() string {
+	if f.Column == "" {
+		return f.Table
+	}
+	return f.Table + "." + f.Column
+}
+
+var (
+	lintMu    sync.RWMutex
+	lintRules []LintRule
+)
+
+// RegisterLintRule adds a rule to schema:lint. Rules run in registration order.
+func RegisterLintRule(r LintRule) {
+	if r.Check == nil || r.Name == "" {
+		return
+	}
+	lintMu.Lock()
+	defer lintMu.Unlock()
+	lintRules = append(lintRules, r)
+}
+
+// LintRules returns the registered rules.
+func LintRules() []LintRule {
+	lintMu.RLock()
+	defer lintMu.RUnlock()
+	return append([]LintRule(nil), lintRules...)
+}
+
+func lintRule(name string) (LintRule, bool) {
+	for _, r := range LintRules() {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return LintRule{}, false
+}
+
+// LintConfig adjusts the rules of a lint run:
+//
+//	rules:
+//	  missing-timestamps: off      # a severity: off, info, warning, error
+//	  plural-table: warning
+//	  money-float:
+//	    severity: error
+//	    ignore: [legacy_*]         # tables or table.column, shell globs
+//	ignore:
+//	  - schema_versions            # skipped by every rule
+type LintConfig struct {
+	Severities map[string]Severity
+	Ignore     []string
+	RuleIgnore map[string][]string
+}
+
+type lintFile struct {
+	Rules  map[string]yaml.Node `yaml:"rules"`
+	Ignore []string             `yaml:"ignore"`
+}
+
+// LoadLintConfig reads a lint configuration file. A missing file is an empty
+// configuration.
+func LoadLintConfig(path string) (LintConfig, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return LintConfig{}, nil
+	}
+	if err != nil {
+		return LintConfig{}, err
+	}
+	cfg, err := ParseLintConfig(b)
+	if err != nil {
+		return LintConfig{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseLintConfig parses a lint configuration (see LintConfig).
+func ParseLintConfig(b []byte) (LintConfig, error) {
+	var f lintFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return LintConfig{}, err
+	}
+	cfg := LintConfig{Ignore: f.Ignore}
+	for name, node := range f.Rules {
+		if node.Kind == yaml.ScalarNode {
+			if err := cfg.Set(name, node.Value); err != nil {
+				return LintConfig{}, err
+			}
+			continue
+		}
+		var r struct {
+			Severity string   `yaml:"severity"`
+			Ignore   []string `yaml:"ignore"`
+		}
+		if err := node.Decode(&r); err != nil {
+			return LintConfig{}, fmt.Errorf("rule %s: %w", name, err)
+		}
+		if r.Severity != "" {
+			if err := cfg.Set(name, r.Severity); err != nil {
+				return LintConfig{}, err
+			}
+		} else if _, ok := lintRule(name); !ok {
+			return LintConfig{}, fmt.Errorf("unknown lint rule %q", name)
+		}
+		if len(r.Ignore) > 0 {
+			if cfg.RuleIgnore == nil {
+				cfg.RuleIgnore = map[string][]string{}
+			}
+			cfg.RuleIgnore[name] = r.Ignore
+		}
+	}
+	return cfg, nil
+}
+
+// Set overrides the severity of a rule, e.g. from --rule name=severity.
+func (c *LintConfig) Set(rule, severity string) error {
+	if _, ok := lintRule(rule); !ok {
+		return fmt.Errorf("unknown lint rule %q (see `forge db schema:lint --list-rules`)", rule)
+	}
+	s, err := ParseSeverity(severity)
+	if err != nil {
+		return fmt.Errorf("rule %s: %w", rule, err)
+	}
+	if c.Severities == nil {
+		c.Severities = map[string]Severity{}
+	}
+	c.Severities[rule] = s
+	return nil
+}
+
+func (c LintConfig) severity(r LintRule) Severity {
+	if s, ok := c.Severities[r.Name]; ok {
+		return s
+	}
+	return r.Severity
+}
+
+func (c LintConfig) ignored(rule string, f LintFinding) bool {
+	for _, patterns := range [][]string{c.Ignore, c.RuleIgnore[rule]} {
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, f.Table); ok {
+				return true
+			}
+			if f.Column != "" {
+				if ok, _ := path.Match(p, f.Object()); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Lint runs the enabled rules over every table of the model. Findings are
+// sorted by severity (highest first), then table and column.
+func Lint(m *Model, cfg LintConfig) []LintFinding {
+	var out []LintFinding
+	for _, r := range LintRules() {
+		sev := cfg.severity(r)
+		if sev == SeverityOff {
+			continue
+		}
+		for _, t := range m.Tables {
+			for _, f := range r.Check(m, t) {
+				f.Rule, f.Severity = r.Name, sev
+				if f.Table == "" {
+					f.Table = t.Name
+				}
+				if !cfg.ignored(r.Name, f) {
+					out = append(out, f)
+				}
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		return a.Column < b.Column
+	})
+	return out
+}
+
+// LintFormats are the --format values of schema:lint.
+var LintFormats = []string{"text", "json", "sarif"}
+
+// WriteLint renders findings in one of LintFormats.
+func WriteLint(w io.Writer, findings []LintFinding, format string) error {
+	switch format {
+	case "", "text":
+		return renderLintText(w, findings)
+	case "json":
+		if findings == nil {
+			findings = []LintFinding{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(findings)
+	case "sarif":
+		b, err := lintSARIF(findings)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+	return fmt.Errorf("unknown --format %q (use: %s)", format, strings.Join(LintFormats, ", "))
+}
+
+func renderLintText(w io.Writer, findings []LintFinding) error {
+	if len(findings) == 0 {
+		_, err := fmt.Fprintln(w, "No problems found.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	counts := map[Severity]int{}
+	for _, f := range findings {
+		counts[f.Severity]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Severity, f.Object(), f.Rule, f.Message)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d problem(s): %d error(s), %d warning(s), %d info\n",
+		len(findings), counts[SeverityError], counts[SeverityWarning], counts[SeverityInfo])
+	return err
+}
+
+func lintSARIF(findings []LintFinding) ([]byte, error) {
+	levels := map[Severity]string{SeverityInfo: "note", SeverityWarning: "warning", SeverityError: "error"}
+	var rules []sarifRule
+	var results []sarifResult
+	seen := map[string]bool{}
+	for _, f := range findings {
+		if !seen[f.Rule] {
+			seen[f.Rule] = true
+			r, _ := lintRule(f.Rule)
+			rules = append(rules, newSARIFRule(f.Rule, r.Description))
+		}
+		kind := "table"
+		if f.Column != "" {
+			kind = "column"
+		}
+		results = append(results, newSARIFResult(f.Rule, levels[f.Severity], f.Message, f.Object(), kind))
+	}
+	return sarifLog(rules, results)
+}
@@ -0,0 +1,232 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+func init() {
+	RegisterLintRule(LintRule{Name: "no-primary-key", Severity: SeverityError,
+		Description: "tables without a primary key", Check: lintPrimaryKey})
+	RegisterLintRule(LintRule{Name: "fk-without-index", Severity: SeverityWarning,
+		Description: "foreign key columns without an index", Check: lintFKIndex})
+	RegisterLintRule(LintRule{Name: "duplicate-index", Severity: SeverityWarning,
+		Description: "indexes on exactly the same columns", Check: lintDuplicateIndex})
+	RegisterLintRule(LintRule{Name: "redundant-index", Severity: SeverityInfo,
+		Description: "indexes covered by a longer index or the primary key", Check: lintRedundantIndex})
+	RegisterLintRule(LintRule{Name: "nullable-unique", Severity: SeverityWarning,
+		Description: "nullable columns in unique indexes (NULLs never collide)", Check: lintNullableUnique})
+	RegisterLintRule(LintRule{Name: "money-float", Severity: SeverityError,
+		Description: "money stored as a floating point number", Check: lintMoneyFloat})
+	RegisterLintRule(LintRule{Name: "snake-case", Severity: SeverityWarning,
+		Description: "table and column names that are not snake_case", Check: lintSnakeCase})
+	RegisterLintRule(LintRule{Name: "plural-table", Severity: SeverityInfo,
+		Description: "singular table names", Check: lintPluralTable})
+	RegisterLintRule(LintRule{Name: "fk-id-suffix", Severity: SeverityInfo,
+		Description: "foreign key columns not ending in _id", Check: lintFKSuffix})
+	RegisterLintRule(LintRule{Name: "missing-timestamps", Severity: SeverityInfo,
+		Description: "tables without created_at / updated_at", Check: lintTimestamps})
+}
+
+func lintPrimaryKey(m *Model, t Table) []LintFinding {
+	if len(t.PrimaryKey) > 0 {
+		return nil
+	}
+	return []LintFinding{{Message: "table has no primary key"}}
+}
+
+// lintFKIndex wants an index (or the primary key) starting with the foreign
+// key's columns, in any order, so joins and cascading deletes use it.
+func lintFKIndex(m *Model, t Table) []LintFinding {
+	var out []LintFinding
+	for _, fk := range t.ForeignKeys {
+		covered := leadsWith(t.PrimaryKey, fk.Columns)
+		for _, ix := range t.Indexes {
+			if ix.Where == "" && !ix.IsExpression(0) && leadsWith(ix.Columns, fk.Columns) {
+				covered = true
+			}
+		}
+		if !covered {
+			out = append(out, LintFinding{Column: strings.Join(fk.Columns, ","),
+				Message: fmt.Sprintf("foreign key (%s) -> %s has no index", strings.Join(fk.Columns, ", "), fk.RefTable)})
+		}
+	}
+	return out
+}
+
+// leadsWith reports whether the first len(cols) entries of parts are cols in
+// any order.
+func leadsWith(parts, cols []string) bool {
+	if len(cols) == 0 || len(parts) < len(cols) {
+		return false
+	}
+	for _, c := range cols {
+		if !contains(parts[:len(cols)], c) {
+			return false
+		}
+	}
+	return true
+}
+
+func lintDuplicateIndex(m *Model, t Table) []LintFinding {
+	var out []LintFinding
+	seen := map[string]string{}
+	for _, ix := range t.Indexes {
+		sig := fmt.Sprint(ix.Unique) + indexSummary(ix)
+		if first, ok := seen[sig]; ok {
+			out = append(out, LintFinding{Message: fmt.Sprintf("index %s duplicates %s %s", ix.Name, first, indexSummary(ix))})
+			continue
+		}
+		seen[sig] = ix.Name
+	}
+	return out
+}
+
+// lintRedundantIndex reports non-unique indexes whose columns start another
+// index, and indexes on exactly the primary key.
+func lintRedundantIndex(m *Model, t Table) []LintFinding {
+	var out []LintFinding
+	for i, ix := range t.Indexes {
+		if equalStrings(ix.Columns, t.PrimaryKey) && ix.Where == "" && len(ix.Expressions) == 0 {
+			out = append(out, LintFinding{Message: fmt.Sprintf("index %s %s repeats the primary key", ix.Name, indexSummary(ix))})
+			continue
+		}
+		if ix.Unique {
+			continue
+		}
+		for j, other := range t.Indexes {
+			if i == j || other.Where != ix.Where || other.Method != ix.Method {
+				continue
+			}
+			longer := len(other.Columns) > len(ix.Columns) || (other.Unique && len(other.Columns) == len(ix.Columns))
+			if longer && isPrefix(other, ix) {
+				out = append(out, LintFinding{Message: fmt.Sprintf("index %s %s is covered by %s %s", ix.Name, indexSummary(ix), other.Name, indexSummary(other))})
+				break
+			}
+		}
+	}
+	return out
+}
+
+// isPrefix reports whether the parts of ix start the parts of other.
+func isPrefix(other, ix Index) bool {
+	if len(ix.Columns) > len(other.Columns) {
+		return false
+	}
+	for k, c := range ix.Columns {
+		if other.Columns[k] != c || other.IsExpression(k) != ix.IsExpression(k) || other.IsDescending(k) != ix.IsDescending(k) {
+			return false
+		}
+	}
+	return true
+}
+
+func lintNullableUnique(m *Model, t Table) []LintFinding {
+	var out []LintFinding
+	cols := columnMap(t)
+	for _, ix := range t.Indexes {
+		// A partial index usually filters the NULLs out on purpose.
+		if !ix.Unique || ix.Where != "" {
+			continue
+		}
+		for k, name := range ix.Columns {
+			if c, ok := cols[name]; ok && !ix.IsExpression(k) && c.Nullable {
+				out = append(out, LintFinding{Column: name,
+					Message: fmt.Sprintf("nullable column in unique index %s: rows with NULL never collide", ix.Name)})
+			}
+		}
+	}
+	return out
+}
+
+var moneyWords = map[string]bool{
+	"price": true, "amount": true, "cost": true, "total": true, "subtotal": true, "balance": true,
+	"money": true, "fee": true, "salary": true, "tax": true, "payment": true, "revenue": true, "budget": true,
+}
+
+func lintMoneyFloat(m *Model, t Table) []LintFinding {
+	var out []LintFinding
+	for _, c := range t.Columns {
+		typ := strings.ToLower(c.Type)
+		if !strings.Contains(typ, "float") && !strings.Contains(typ, "double") && !strings.Contains(typ, "real") {
+			continue
+		}
+		for _, w := range strings.Split(strings.ToLower(c.Name), "_") {
+			if moneyWords[w] {
+				out = append(out, LintFinding{Column: c.Name,
+					Message: fmt.Sprintf("money stored as %s loses cents to rounding; use decimal/numeric or integer cents", c.Type)})
+				break
+			}
+		}
+	}
+	return out
+}
+
+var snakeCaseRe = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+func lintSnakeCase(m *Model, t Table) []LintFinding {
+	var out []LintFinding
+	if _, name := splitQualified(t.Name); !snakeCaseRe.MatchString(name) {
+		out = append(out, LintFinding{Message: fmt.Sprintf("table name %q is not snake_case", name)})
+	}
+	for _, c := range t.Columns {
+		if !snakeCaseRe.MatchString(c.Name) {
+			out = append(out, LintFinding{Column: c.Name, Message: fmt.Sprintf("column name %q is not snake_case", c.Name)})
+		}
+	}
+	return out
+}
+
+// uncountable are words singularize gets wrong or that have no plural form.
+var uncountable = map[string]bool{
+	"data": true, "metadata": true, "media": true, "people": true, "children": true, "news": true,
+	"series": true, "information": true, "equipment": true, "feedback": true, "staff": true,
+}
+
+func lintPluralTable(m *Model, t Table) []LintFinding {
+	_, name := splitQualified(t.Name)
+	words := strings.Split(strings.ToLower(name), "_")
+	last := words[len(words)-1]
+	if uncountable[last] || singularize(last) != last {
+		return nil
+	}
+	return []LintFinding{{Message: fmt.Sprintf("table name %q is singular", name)}}
+}
+
+func lintFKSuffix(m *Model, t Table) []LintFinding {
+	var out []LintFinding
+	for _, fk := range t.ForeignKeys {
+		if len(fk.Columns) == 1 && !strings.HasSuffix(fk.Columns[0], "_id") {
+			out = append(out, LintFinding{Column: fk.Columns[0],
+				Message: fmt.Sprintf("foreign key column to %s does not end in _id", fk.RefTable)})
+		}
+	}
+	return out
+}
+
+// lintTimestamps skips pure join tables, whose columns all belong to the
+// primary key or a foreign key.
+func lintTimestamps(m *Model, t Table) []LintFinding {
+	cols := columnMap(t)
+	var missing []string
+	for _, name := range []string{"created_at", "updated_at"} {
+		if _, ok := cols[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	join := true
+	for _, c := range t.Columns {
+		if !contains(t.PrimaryKey, c.Name) && !fkCol(t, c.Name) {
+			join = false
+			break
+		}
+	}
+	if join {
+		return nil
+	}
+	return []LintFinding{{Message: "missing " + strings.Join(missing, " and ")}}
+}
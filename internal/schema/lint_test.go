@@ -0,0 +1,101 @@
+package schema
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLintRules(t *testing.T) {
+	m := &Model{Driver: "sqlite", Tables: []Table{
+		{
+			Name: "orders",
+			Columns: []Column{
+				{Name: "id", Type: "integer"},
+				{Name: "customer", Type: "integer"},
+				{Name: "total_price", Type: "REAL"},
+				{Name: "couponCode", Type: "text", Nullable: true},
+				{Name: "created_at", Type: "datetime"},
+				{Name: "updated_at", Type: "datetime"},
+			},
+			PrimaryKey: []string{"id"},
+			Indexes: []Index{
+				{Name: "ix_orders_id", Columns: []string{"id"}},
+				{Name: "ux_orders_coupon", Columns: []string{"couponCode"}, Unique: true},
+				{Name: "ix_orders_created", Columns: []string{"created_at"}},
+				{Name: "ix_orders_created_2", Columns: []string{"created_at"}},
+				{Name: "ix_orders_created_total", Columns: []string{"created_at", "total_price"}},
+			},
+			ForeignKeys: []ForeignKey{{Columns: []string{"customer"}, RefTable: "customers", RefColumns: []string{"id"}}},
+		},
+		{
+			Name:    "customer",
+			Columns: []Column{{Name: "id", Type: "integer"}, {Name: "name", Type: "text"}},
+		},
+		{
+			Name:        "order_tags",
+			Columns:     []Column{{Name: "order_id", Type: "integer"}, {Name: "tag_id", Type: "integer"}},
+			PrimaryKey:  []string{"order_id", "tag_id"},
+			ForeignKeys: []ForeignKey{{Columns: []string{"order_id"}, RefTable: "orders", RefColumns: []string{"id"}}},
+		},
+	}}
+
+	found := map[string]bool{}
+	for _, f := range Lint(m, LintConfig{}) {
+		found[f.Rule+" "+f.Object()] = true
+	}
+	for _, want := range []string{
+		"no-primary-key customer",
+		"fk-without-index orders.customer",
+		"duplicate-index orders",
+		"redundant-index orders",
+		"nullable-unique orders.couponCode",
+		"money-float orders.total_price",
+		"snake-case orders.couponCode",
+		"plural-table customer",
+		"fk-id-suffix orders.customer",
+		"missing-timestamps customer",
+	} {
+		if !found[want] {
+			t.Errorf("missing finding %q in %v", want, found)
+		}
+	}
+	// The primary key covers order_tags.order_id, and a join table needs no timestamps.
+	for _, unwanted := range []string{"fk-without-index order_tags.order_id", "missing-timestamps order_tags", "plural-table orders"} {
+		if found[unwanted] {
+			t.Errorf("unexpected finding %q", unwanted)
+		}
+	}
+
+	cfg, err := ParseLintConfig([]byte(`
+rules:
+  plural-table: off
+  money-float:
+    severity: warning
+    ignore: [orders.total_*]
+ignore: [customer]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Set("snake-case", "error"); err != nil {
+		t.Fatal(err)
+	}
+	findings := Lint(m, cfg)
+	for _, f := range findings {
+		if f.Table == "customer" || f.Rule == "plural-table" || f.Rule == "money-float" {
+			t.Errorf("finding should be configured away: %+v", f)
+		}
+	}
+	if findings[0].Rule != "snake-case" || findings[0].Severity != SeverityError {
+		t.Fatalf("findings should be sorted by severity, got %+v", findings[0])
+	}
+
+	if _, err := ParseLintConfig([]byte("rules:\n  no-such-rule: off\n")); err == nil {
+		t.Fatal("expected an error for an unknown rule")
+	}
+	var buf bytes.Buffer
+	if err := WriteLint(&buf, findings, "json"); err != nil || !strings.Contains(buf.String(), `"severity": "error"`) {
+		t.Fatalf("json: %v\n%s", err, buf.String())
+	}
+}
@@ -127,7 +127,7 @@ var commonInitialisms = map[string]bool{
 }
 
 func goFieldName(col string) string {
-	parts := strings.FieldsFunc(col, func(r rune) bool { return r == '_' || r == '-' || r == ' ' })
+	parts := strings.FieldsFunc(col, func(r rune) bool { return r == '_' || r == '-' || r == ' ' || r == '.' })
 	var b strings.Builder
 	for _, p := range parts {
 		if p == "" {
@@ -0,0 +1,459 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kind is the driver-neutral family of a column type, used to move values
+// between drivers.
+type Kind string
+
+const (
+	KindInt     Kind = "int"
+	KindBool    Kind = "bool"
+	KindFloat   Kind = "float"
+	KindDecimal Kind = "decimal"
+	KindString  Kind = "string"
+	KindBinary  Kind = "binary"
+	KindTime    Kind = "time"
+	KindJSON    Kind = "json"
+	KindUUID    Kind = "uuid"
+)
+
+// typeSpec is a parsed column type: a base type name plus its size or
+// precision arguments.
+type typeSpec struct {
+	base  string // smallint, int, bigint, bool, float, double, decimal, varchar, char, text, blob, date, time, datetime, timestamptz, json, uuid
+	size  int    // varchar/char length, decimal precision
+	scale int    // decimal scale
+}
+
+var typeArgs = regexp.MustCompile(`\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\)`)
+
+// parseType maps a column type as reported by Introspect on driver to a
+// typeSpec. Unknown types (enums, arrays, user-defined types) become text.
+func parseType(raw, driver string) typeSpec {
+	t := strings.ToLower(strings.TrimSpace(raw))
+	var spec typeSpec
+	if m := typeArgs.FindStringSubmatch(t); m != nil {
+		spec.size, _ = strconv.Atoi(m[1])
+		if m[2] != "" {
+			spec.scale, _ = strconv.Atoi(m[2])
+		}
+	}
+	name := strings.TrimSpace(typeArgs.ReplaceAllString(t, ""))
+	name = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(name, "zerofill"), "unsigned"))
+
+	switch {
+	case name == "tinyint" && spec.size == 1, strings.HasPrefix(name, "bool"):
+		spec.base = "bool"
+	case name == "tinyint", name == "smallint", name == "int2", name == "smallserial":
+		spec.base = "smallint"
+	case name == "bigint", name == "int8", name == "bigserial":
+		spec.base = "bigint"
+	case driver == "sqlite" && strings.Contains(name, "int"):
+		// sqlite's integer affinity rule; its integers are 64-bit.
+		spec.base = "bigint"
+	case name == "int", name == "integer", name == "int4", name == "mediumint", name == "serial":
+		spec.base = "int"
+	case name == "real" || name == "float4" || name == "float" && spec.size > 0 && spec.size <= 24:
+		spec.base = "float"
+	case strings.HasPrefix(name, "double"), name == "float8", name == "float":
+		spec.base = "double"
+	case name == "numeric", name == "decimal", name == "money":
+		spec.base = "decimal"
+	case strings.Contains(name, "varying"), strings.Contains(name, "varchar"):
+		spec.base = "varchar"
+	case name == "character", name == "char", name == "nchar", name == "bpchar":
+		spec.base = "char"
+	case strings.Contains(name, "text"), name == "clob", name == "citext":
+		spec.base = "text"
+	case strings.Contains(name, "blob"), strings.Contains(name, "binary"), name == "bytea":
+		spec.base = "blob"
+	case name == "date":
+		spec.base = "date"
+	case strings.HasPrefix(name, "time") && strings.Contains(name, "with time zone"), name == "timestamptz":
+		spec.base = "timestamptz"
+	case strings.HasPrefix(name, "timestamp"), strings.HasPrefix(name, "datetime"):
+		spec.base = "datetime"
+	case strings.HasPrefix(name, "time"):
+		spec.base = "time"
+	case name == "json", name == "jsonb":
+		spec.base = "json"
+	case name == "uuid":
+		spec.base = "uuid"
+	default:
+		spec.base = "text"
+	}
+	return spec
+}
+
+// KindOf returns the family of a column type reported by Introspect on driver.
+func KindOf(typ, driver string) Kind {
+	switch parseType(typ, driver).base {
+	case "smallint", "int", "bigint":
+		return KindInt
+	case "bool":
+		return KindBool
+	case "float", "double":
+		return KindFloat
+	case "decimal":
+		return KindDecimal
+	case "blob":
+		return KindBinary
+	case "date", "time", "datetime", "timestamptz":
+		return KindTime
+	case "json":
+		return KindJSON
+	case "uuid":
+		return KindUUID
+	default:
+		return KindString
+	}
+}
+
+// ConvertType translates a column type from one driver's dialect to
+// another's. Types are kept verbatim when both drivers are the same.
+func ConvertType(typ, from, to string) string {
+	if from == to {
+		return typ
+	}
+	return renderType(parseType(typ, from), to)
+}
+
+func renderType(s typeSpec, driver string) string {
+	sized := func(name string, def int) string {
+		n := s.size
+		if n == 0 {
+			n = def
+		}
+		if n == 0 {
+			return name
+		}
+		return fmt.Sprintf("%s(%d)", name, n)
+	}
+	decimal := func(name string) string {
+		switch {
+		case s.size > 0 && s.scale > 0:
+			return fmt.Sprintf("%s(%d,%d)", name, s.size, s.scale)
+		case s.size > 0:
+			return fmt.Sprintf("%s(%d)", name, s.size)
+		case driver == "mysql":
+			return name + "(38,10)"
+		}
+		return name
+	}
+
+	switch driver {
+	case "sqlite":
+		switch s.base {
+		case "smallint", "int", "bigint":
+			return "INTEGER"
+		case "bool":
+			return "BOOLEAN"
+		case "float", "double":
+			return "REAL"
+		case "decimal":
+			return decimal("NUMERIC")
+		case "varchar":
+			return sized("VARCHAR", 0)
+		case "char":
+			return sized("CHAR", 0)
+		case "blob":
+			return "BLOB"
+		case "date":
+			return "DATE"
+		case "time":
+			return "TIME"
+		case "datetime", "timestamptz":
+			return "DATETIME"
+		}
+		return "TEXT"
+
+	case "postgres":
+		switch s.base {
+		case "smallint":
+			return "SMALLINT"
+		case "int":
+			return "INTEGER"
+		case "bigint":
+			return "BIGINT"
+		case "bool":
+			return "BOOLEAN"
+		case "float":
+			return "REAL"
+		case "double":
+			return "DOUBLE PRECISION"
+		case "decimal":
+			return decimal("NUMERIC")
+		case "varchar":
+			return sized("VARCHAR", 0)
+		case "char":
+			return sized("CHAR", 0)
+		case "blob":
+			return "BYTEA"
+		case "date":
+			return "DATE"
+		case "time":
+			return "TIME"
+		case "datetime":
+			return "TIMESTAMP"
+		case "timestamptz":
+			return "TIMESTAMPTZ"
+		case "json":
+			return "JSONB"
+		case "uuid":
+			return "UUID"
+		}
+		return "TEXT"
+
+	case "mysql":
+		switch s.base {
+		case "smallint":
+			return "SMALLINT"
+		case "int":
+			return "INT"
+		case "bigint":
+			return "BIGINT"
+		case "bool":
+			return "TINYINT(1)"
+		case "float":
+			return "FLOAT"
+		case "double":
+			return "DOUBLE"
+		case "decimal":
+			return decimal("DECIMAL")
+		case "varchar":
+			return sized("VARCHAR", 255)
+		case "char":
+			return sized("CHAR", 1)
+		case "blob":
+			return "LONGBLOB"
+		case "date":
+			return "DATE"
+		case "time":
+			return "TIME"
+		case "datetime", "timestamptz":
+			return "DATETIME(6)"
+		case "json":
+			return "JSON"
+		case "uuid":
+			return "CHAR(36)"
+		}
+		return "LONGTEXT"
+	}
+	return strings.ToUpper(s.base)
+}
+
+// AutoIncrementPK returns the primary key column of t when it is a single
+// integer column, which is treated as auto-incrementing on every driver.
+func AutoIncrementPK(t Table, driver string) (string, bool) {
+	if len(t.PrimaryKey) != 1 {
+		return "", false
+	}
+	for _, c := range t.Columns {
+		if c.Name == t.PrimaryKey[0] {
+			return c.Name, KindOf(c.Type, driver) == KindInt
+		}
+	}
+	return "", false
+}
+
+// CreateTableSQL renders the DDL to create t, introspected on driver from, on
+// driver to: a CREATE TABLE statement followed by its CREATE INDEX statements.
+// Only the foreign keys in fks are declared inline, so the caller decides which
+// references can exist yet (see AddForeignKeySQL for the rest).
+func CreateTableSQL(t Table, from, to string, fks []ForeignKey) []string {
+	q := identQuoter(to)
+	autoPK, hasAuto := AutoIncrementPK(t, from)
+
+	var lines []string
+	for _, c := range t.Columns {
+		line := "    " + q(c.Name) + " "
+		switch {
+		case hasAuto && c.Name == autoPK && to == "postgres":
+			if parseType(c.Type, from).base == "int" {
+				line += "SERIAL"
+			} else {
+				line += "BIGSERIAL"
+			}
+		case hasAuto && c.Name == autoPK && to == "sqlite":
+			// INTEGER PRIMARY KEY aliases the rowid.
+			line += "INTEGER"
+		default:
+			line += ConvertType(c.Type, from, to)
+		}
+		if !c.Nullable {
+			line += " NOT NULL"
+		}
+		if hasAuto && c.Name == autoPK && to == "mysql" {
+			line += " AUTO_INCREMENT"
+		} else if def, ok := convertDefault(c, from, to); ok {
+			line += " DEFAULT " + def
+		}
+		lines = append(lines, line)
+	}
+	if len(t.PrimaryKey) > 0 {
+		lines = append(lines, "    PRIMARY KEY ("+quoteList(q, t.PrimaryKey)+")")
+	}
+	for _, fk := range fks {
+		lines = append(lines, "    "+foreignKeyClause(q, fk))
+	}
+
+	stmts := []string{fmt.Sprintf("CREATE TABLE %s (\n%s\n)", q(t.Name), strings.Join(lines, ",\n"))}
+	for _, ix := range t.Indexes {
+		if stmt, ok := CreateIndexSQL(t, ix, from, to); ok {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+// CreateIndexSQL renders the CREATE INDEX statement for ix on t, introspected
+// on driver from, for driver to. Expressions and predicates are SQL of driver
+// from and are carried over as written; the index method only when the
+// drivers match. It reports false for a partial index on mysql, which has
+// none.
+func CreateIndexSQL(t Table, ix Index, from, to string) (string, bool) {
+	return createIndexSQL(identQuoter(to), t, ix, from, to)
+}
+
+func createIndexSQL(q func(string) string, t Table, ix Index, from, to string) (string, bool) {
+	if ix.Where != "" && to == "mysql" {
+		return "", false
+	}
+	kind := "INDEX"
+	if ix.Unique {
+		kind = "UNIQUE INDEX"
+	}
+	using := ""
+	if ix.Method != "" && from == to {
+		switch {
+		case to == "mysql" && (ix.Method == "fulltext" || ix.Method == "spatial"):
+			kind = strings.ToUpper(ix.Method) + " INDEX"
+		case to == "mysql":
+			using = " USING " + strings.ToUpper(ix.Method)
+		case to == "postgres":
+			using = " USING " + ix.Method
+		}
+	}
+	parts := make([]string, len(ix.Columns))
+	for i, c := range ix.Columns {
+		switch {
+		case ix.IsExpression(i):
+			parts[i] = "(" + c + ")"
+		default:
+			parts[i] = q(c)
+			// mysql cannot index TEXT/BLOB columns without a prefix length.
+			if to == "mysql" && kind != "FULLTEXT INDEX" && indexNeedsPrefix(t, c, from) {
+				parts[i] += "(191)"
+			}
+		}
+		if ix.IsDescending(i) {
+			parts[i] += " DESC"
+		}
+	}
+	stmt := fmt.Sprintf("CREATE %s %s ON %s", kind, q(ix.Name), q(t.Name))
+	if to == "postgres" {
+		stmt += using + " (" + strings.Join(parts, ", ") + ")"
+	} else {
+		stmt += " (" + strings.Join(parts, ", ") + ")" + using
+	}
+	if ix.Where != "" {
+		stmt += " WHERE " + ix.Where
+	}
+	return stmt, true
+}
+
+// AddForeignKeySQL renders an ALTER TABLE adding fk to table (postgres and
+// mysql; sqlite cannot add constraints to existing tables).
+func AddForeignKeySQL(table string, fk ForeignKey, driver string) string {
+	q := identQuoter(driver)
+	return fmt.Sprintf("ALTER TABLE %s ADD %s", q(table), foreignKeyClause(q, fk))
+}
+
+func foreignKeyClause(q func(string) string, fk ForeignKey) string {
+	clause := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)", quoteList(q, fk.Columns), q(fk.RefTable), quoteList(q, fk.RefColumns))
+	if fk.Name != "" {
+		clause = "CONSTRAINT " + q(fk.Name) + " " + clause
+	}
+	if fk.OnDelete != "" {
+		clause += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" {
+		clause += " ON UPDATE " + fk.OnUpdate
+	}
+	return clause
+}
+
+func quoteList(q func(string) string, names []string) string {
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = q(n)
+	}
+	return strings.Join(out, ", ")
+}
+
+func indexNeedsPrefix(t Table, col, from string) bool {
+	for _, c := range t.Columns {
+		if c.Name == col {
+			base := parseType(c.Type, from).base
+			return base == "text" || base == "blob" || base == "json"
+		}
+	}
+	return false
+}
+
+var (
+	numericDefault = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+	pgCast         = regexp.MustCompile(`::[a-z ]+(\[\])?$`)
+)
+
+// convertDefault carries a column default across drivers. Literals and the
+// current timestamp are translated; other expressions (sequences, functions)
+// are dropped.
+func convertDefault(c Column, from, to string) (string, bool) {
+	def := strings.TrimSpace(c.Default)
+	if def == "" || strings.EqualFold(def, "null") {
+		return "", false
+	}
+	if strings.HasPrefix(strings.ToLower(def), "nextval(") {
+		return "", false
+	}
+	if from == to {
+		return def, true
+	}
+
+	if from == "postgres" {
+		def = pgCast.ReplaceAllString(def, "")
+		def = strings.TrimSuffix(strings.TrimPrefix(def, "("), ")")
+	}
+	lower := strings.ToLower(def)
+	switch {
+	case lower == "current_timestamp" || lower == "now()" || lower == "datetime('now')" ||
+		strings.HasPrefix(lower, "current_timestamp("):
+		return "CURRENT_TIMESTAMP", true
+	case lower == "true" || lower == "false":
+		if to == "postgres" {
+			return lower, true
+		}
+		if lower == "true" {
+			return "1", true
+		}
+		return "0", true
+	case numericDefault.MatchString(def):
+		if to == "postgres" && KindOf(c.Type, from) == KindBool {
+			return strconv.FormatBool(def != "0"), true
+		}
+		return def, true
+	case strings.HasPrefix(def, "'") && strings.HasSuffix(def, "'") && len(def) >= 2:
+		return def, true
+	case from == "mysql" && !strings.Contains(def, "("):
+		// information_schema reports mysql string defaults unquoted.
+		return "'" + strings.ReplaceAll(def, "'", "''") + "'", true
+	}
+	return "", false
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertType(t *testing.T) {
+	cases := []struct{ typ, from, to, want string }{
+		{"INTEGER", "sqlite", "postgres", "BIGINT"},
+		{"varchar(120)", "sqlite", "mysql", "VARCHAR(120)"},
+		{"TEXT", "sqlite", "mysql", "LONGTEXT"},
+		{"datetime", "sqlite", "postgres", "TIMESTAMP"},
+		{"boolean", "sqlite", "mysql", "TINYINT(1)"},
+		{"tinyint(1)", "mysql", "postgres", "BOOLEAN"},
+		{"int unsigned", "mysql", "postgres", "INTEGER"},
+		{"decimal(10,2)", "mysql", "sqlite", "NUMERIC(10,2)"},
+		{"character varying", "postgres", "mysql", "VARCHAR(255)"},
+		{"timestamp with time zone", "postgres", "sqlite", "DATETIME"},
+		{"jsonb", "postgres", "mysql", "JSON"},
+		{"point", "postgres", "mysql", "LONGTEXT"},
+		{"USER-DEFINED", "postgres", "postgres", "USER-DEFINED"},
+	}
+	for _, tc := range cases {
+		if got := ConvertType(tc.typ, tc.from, tc.to); got != tc.want {
+			t.Errorf("ConvertType(%q, %s, %s) = %q, want %q", tc.typ, tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestCreateTableSQLAcrossDrivers(t *testing.T) {
+	tbl := Table{
+		Name: "posts",
+		Columns: []Column{
+			{Name: "id", Type: "INTEGER"},
+			{Name: "user_id", Type: "INTEGER"},
+			{Name: "title", Type: "TEXT", Nullable: true, Default: "'untitled'"},
+			{Name: "published", Type: "boolean", Default: "0"},
+		},
+		PrimaryKey:  []string{"id"},
+		ForeignKeys: []ForeignKey{{Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}}},
+		Indexes:     []Index{{Name: "ix_posts_title", Columns: []string{"title"}}},
+	}
+
+	pg := strings.Join(CreateTableSQL(tbl, "sqlite", "postgres", tbl.ForeignKeys), ";\n")
+	for _, want := range []string{
+		`"id" BIGSERIAL NOT NULL`,
+		`"title" TEXT DEFAULT 'untitled'`,
+		`"published" BOOLEAN NOT NULL DEFAULT false`,
+		`FOREIGN KEY ("user_id") REFERENCES "users" ("id")`,
+		`CREATE INDEX "ix_posts_title" ON "posts" ("title")`,
+	} {
+		if !strings.Contains(pg, want) {
+			t.Errorf("postgres DDL missing %q:\n%s", want, pg)
+		}
+	}
+
+	my := strings.Join(CreateTableSQL(tbl, "sqlite", "mysql", nil), ";\n")
+	for _, want := range []string{"`id` BIGINT NOT NULL AUTO_INCREMENT", "(`title`(191))"} {
+		if !strings.Contains(my, want) {
+			t.Errorf("mysql DDL missing %q:\n%s", want, my)
+		}
+	}
+	if strings.Contains(my, "FOREIGN KEY") {
+		t.Errorf("foreign keys not passed in must be left out:\n%s", my)
+	}
+}
+
+func TestCreateIndexSQLDetails(t *testing.T) {
+	tbl := Table{Name: "comments", Columns: []Column{{Name: "body", Type: "text"}, {Name: "created_at", Type: "timestamp"}}}
+	ix := Index{Name: "ix_live", Columns: []string{"lower(body)", "created_at"}, Expressions: []bool{true, false},
+		Descending: []bool{false, true}, Where: "deleted_at IS NULL", Method: "gin"}
+
+	got, ok := CreateIndexSQL(tbl, ix, "postgres", "postgres")
+	want := `CREATE INDEX "ix_live" ON "comments" USING gin ((lower(body)), "created_at" DESC) WHERE deleted_at IS NULL`
+	if !ok || got != want {
+		t.Fatalf("postgres:\n got %s\nwant %s", got, want)
+	}
+	if got, _ := CreateIndexSQL(tbl, ix, "postgres", "sqlite"); strings.Contains(got, "USING") {
+		t.Fatalf("method carried across drivers: %s", got)
+	}
+	if _, ok := CreateIndexSQL(tbl, ix, "postgres", "mysql"); ok {
+		t.Fatal("partial index rendered for mysql")
+	}
+
+	fk := ForeignKey{Name: "fk_user", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnDelete: "CASCADE"}
+	if got := AddForeignKeySQL("posts", fk, "mysql"); got != "ALTER TABLE `posts` ADD CONSTRAINT `fk_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE" {
+		t.Fatalf("fk: %s", got)
+	}
+}
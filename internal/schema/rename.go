@@ -0,0 +1,262 @@
+package schema
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Rename is a table or column that exists under another name on the new side.
+// Score is the similarity (0..1) that matched the two.
+type Rename struct {
+	Old   string
+	New   string
+	Score float64
+}
+
+// matchRenames pairs removed with added names whose similarity reaches the
+// threshold, best matches first. A name with two equally good candidates is
+// left alone: guessing wrong is worse than reporting a drop and a create.
+func matchRenames(removed, added []string, threshold float64, similarity func(oldName, newName string) float64) []Rename {
+	var cands []Rename
+	for _, o := range removed {
+		for _, n := range added {
+			if s := similarity(o, n); s >= threshold {
+				cands = append(cands, Rename{o, n, s})
+			}
+		}
+	}
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].Score > cands[j].Score })
+
+	usedOld, usedNew := map[string]bool{}, map[string]bool{}
+	var out []Rename
+	for _, c := range cands {
+		if usedOld[c.Old] || usedNew[c.New] {
+			continue
+		}
+		ambiguous := false
+		for _, other := range cands {
+			if other != c && other.Score == c.Score && (other.Old == c.Old || other.New == c.New) &&
+				!usedOld[other.Old] && !usedNew[other.New] {
+				ambiguous = true
+				break
+			}
+		}
+		usedOld[c.Old], usedNew[c.New] = true, true
+		if !ambiguous {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Old < out[j].Old })
+	return out
+}
+
+// withoutRenames drops the renamed names from the removed and added lists.
+func withoutRenames(removed, added []string, renames []Rename) ([]string, []string) {
+	oldNames, newNames := map[string]bool{}, map[string]bool{}
+	for _, r := range renames {
+		oldNames[r.Old], newNames[r.New] = true, true
+	}
+	keep := func(names []string, drop map[string]bool) []string {
+		var out []string
+		for _, n := range names {
+			if !drop[n] {
+				out = append(out, n)
+			}
+		}
+		return out
+	}
+	return keep(removed, oldNames), keep(added, newNames)
+}
+
+// columnSimilarity scores a removed column of oldT against an added column of
+// newT. The type must match; nullability, default, the keys the column takes
+// part in, its position and its name make up the rest. Without the same
+// position or a related name the score stays below DefaultRenameThreshold, so
+// dropping one text column and adding another is not taken for a rename.
+func columnSimilarity(oldT Table, oldName string, newT Table, newName string) float64 {
+	oi, ni := columnIndex(oldT, oldName), columnIndex(newT, newName)
+	oc, nc := oldT.Columns[oi], newT.Columns[ni]
+	if !strings.EqualFold(oc.Type, nc.Type) {
+		return 0
+	}
+	points := 40
+	if oc.Nullable == nc.Nullable {
+		points += 10
+	}
+	if oc.Default == nc.Default {
+		points += 10
+	}
+	if columnRoles(oldT, oldName) == columnRoles(newT, newName) {
+		points += 10
+	}
+	if oi == ni {
+		points += 15
+	}
+	if relatedNames(oldName, newName) {
+		points += 15
+	}
+	return float64(points) / 100
+}
+
+// relatedNames reports whether one name contains the other or both share a
+// word of three letters or more, e.g. email and email_address.
+func relatedNames(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if strings.Contains(a, b) || strings.Contains(b, a) {
+		return true
+	}
+	words := map[string]bool{}
+	for _, w := range strings.Split(a, "_") {
+		if len(w) >= 3 {
+			words[w] = true
+		}
+	}
+	for _, w := range strings.Split(b, "_") {
+		if words[w] {
+			return true
+		}
+	}
+	return false
+}
+
+func columnIndex(t Table, name string) int {
+	for i, c := range t.Columns {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// columnRoles describes the keys a column belongs to without naming it, e.g.
+// "pk:0 unique:1/0 fk:users.id".
+func columnRoles(t Table, name string) string {
+	var roles []string
+	for i, c := range t.PrimaryKey {
+		if c == name {
+			roles = append(roles, "pk:"+strconv.Itoa(i))
+		}
+	}
+	for _, ix := range t.Indexes {
+		kind := "index"
+		if ix.Unique {
+			kind = "unique"
+		}
+		for i, c := range ix.Columns {
+			if c == name && !ix.IsExpression(i) {
+				roles = append(roles, kind+":"+strconv.Itoa(len(ix.Columns))+"/"+strconv.Itoa(i))
+			}
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		for i, c := range fk.Columns {
+			if c == name && i < len(fk.RefColumns) {
+				roles = append(roles, "fk:"+fk.RefTable+"."+fk.RefColumns[i])
+			}
+		}
+	}
+	sort.Strings(roles)
+	return strings.Join(roles, " ")
+}
+
+// tableSimilarity scores a removed table against an added one, mostly by the
+// columns (name and type) they share. Tables sharing fewer than two columns,
+// e.g. two tables holding just an id, are never considered renames.
+func tableSimilarity(oldT, newT Table) float64 {
+	sig := func(c Column) string { return c.Name + " " + strings.ToLower(c.Type) }
+	oldCols := map[string]bool{}
+	for _, c := range oldT.Columns {
+		oldCols[sig(c)] = true
+	}
+	shared := 0
+	for _, c := range newT.Columns {
+		if oldCols[sig(c)] {
+			shared++
+		}
+	}
+	if shared < 2 {
+		return 0
+	}
+	union := len(oldT.Columns) + len(newT.Columns) - shared
+	points := float64(70*shared) / float64(union)
+	if equalStrings(oldT.PrimaryKey, newT.PrimaryKey) {
+		points += 10
+	}
+	if len(setDiff("", indexSigs(oldT), indexSigs(newT))) == 0 {
+		points += 10
+	}
+	if len(setDiff("", fkSigs(oldT), fkSigs(newT))) == 0 {
+		points += 10
+	}
+	return points / 100
+}
+
+// renameTables gives renamed tables their new name, including in the foreign
+// keys of the other tables, so the rest of the diff compares like with like.
+func renameTables(tables map[string]Table, renames []Rename) map[string]Table {
+	names := map[string]string{}
+	for _, r := range renames {
+		names[r.Old] = r.New
+	}
+	out := map[string]Table{}
+	for name, t := range tables {
+		if n, ok := names[name]; ok {
+			t.Name, name = n, n
+		}
+		fks := make([]ForeignKey, len(t.ForeignKeys))
+		for i, fk := range t.ForeignKeys {
+			if n, ok := names[fk.RefTable]; ok {
+				fk.RefTable = n
+			}
+			fks[i] = fk
+		}
+		t.ForeignKeys = fks
+		out[name] = t
+	}
+	return out
+}
+
+// renameColumns returns a copy of t with renamed columns under their new name
+// in the column list and every key.
+func renameColumns(t Table, renames []Rename) Table {
+	names := map[string]string{}
+	for _, r := range renames {
+		names[r.Old] = r.New
+	}
+	rename := func(cols []string, skip func(int) bool) []string {
+		out := make([]string, len(cols))
+		for i, c := range cols {
+			if n, ok := names[c]; ok && (skip == nil || !skip(i)) {
+				c = n
+			}
+			out[i] = c
+		}
+		return out
+	}
+
+	cols := make([]Column, len(t.Columns))
+	for i, c := range t.Columns {
+		if n, ok := names[c.Name]; ok {
+			c.Name = n
+		}
+		cols[i] = c
+	}
+	t.Columns = cols
+	t.PrimaryKey = rename(t.PrimaryKey, nil)
+
+	idx := make([]Index, len(t.Indexes))
+	for i, ix := range t.Indexes {
+		ix.Columns = rename(ix.Columns, ix.IsExpression)
+		idx[i] = ix
+	}
+	t.Indexes = idx
+
+	fks := make([]ForeignKey, len(t.ForeignKeys))
+	for i, fk := range t.ForeignKeys {
+		fk.Columns = rename(fk.Columns, nil)
+		fks[i] = fk
+	}
+	t.ForeignKeys = fks
+	return t
+}
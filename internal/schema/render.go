@@ -13,6 +13,9 @@ func RenderText(m *Model) string {
 	fmt.Fprintf(&b, "Schema (%s) — %d table(s)\n", m.Driver, len(m.Tables))
 	for _, t := range m.Tables {
 		fmt.Fprintf(&b, "\n%s\n", t.Name)
+		if t.Comment != "" {
+			fmt.Fprintf(&b, "  -- %s\n", t.Comment)
+		}
 		for _, c := range t.Columns {
 			flags := []string{}
 			if contains(t.PrimaryKey, c.Name) {
@@ -28,20 +31,52 @@ func RenderText(m *Model) string {
 			if len(flags) > 0 {
 				suffix = "  [" + strings.Join(flags, ", ") + "]"
 			}
+			if c.Comment != "" {
+				suffix += "  -- " + c.Comment
+			}
 			fmt.Fprintf(&b, "  - %-24s %s%s\n", c.Name, c.Type, suffix)
 		}
 		for _, fk := range t.ForeignKeys {
-			fmt.Fprintf(&b, "  FK (%s) -> %s(%s)\n",
-				strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "))
+			fmt.Fprintf(&b, "  FK %s\n", fkSummary(fk))
 		}
 		for _, ix := range t.Indexes {
 			kind := "INDEX"
 			if ix.Unique {
 				kind = "UNIQUE"
 			}
-			fmt.Fprintf(&b, "  %s %s (%s)\n", kind, ix.Name, strings.Join(ix.Columns, ", "))
+			fmt.Fprintf(&b, "  %s %s %s\n", kind, ix.Name, indexSummary(ix))
+		}
+		for _, ck := range t.Checks {
+			if ck.Name != "" {
+				fmt.Fprintf(&b, "  CHECK %s (%s)\n", ck.Name, ck.Expression)
+			} else {
+				fmt.Fprintf(&b, "  CHECK (%s)\n", ck.Expression)
+			}
 		}
 	}
+	for _, e := range m.Enums {
+		fmt.Fprintf(&b, "\nenum %s (%s)\n", e.Name, strings.Join(e.Values, ", "))
+	}
+	for _, s := range m.Sequences {
+		fmt.Fprintf(&b, "\nsequence %s (start %d, increment %d)", s.Name, s.Start, s.Increment)
+		if s.OwnedBy != "" {
+			fmt.Fprintf(&b, " owned by %s", s.OwnedBy)
+		}
+		b.WriteString("\n")
+	}
+	for _, v := range m.Views {
+		kind := "view"
+		if v.Materialized {
+			kind = "materialized view"
+		}
+		fmt.Fprintf(&b, "\n%s %s\n  %s\n", kind, v.Name, strings.Join(strings.Fields(v.Definition), " "))
+	}
+	for _, tr := range m.Triggers {
+		fmt.Fprintf(&b, "\ntrigger %s on %s (%s %s)\n", tr.Name, tr.Table, tr.Timing, tr.Event)
+	}
+	for _, f := range m.Functions {
+		fmt.Fprintf(&b, "\nfunction %s(%s)\n", f.Name, f.Arguments)
+	}
 	return b.String()
 }
 
@@ -116,23 +151,34 @@ func DumpSQL(db *gorm.DB, m *Model) (string, error) {
 		for _, t := range m.Tables {
 			allow[t.Name] = true
 		}
+		for _, v := range m.Views {
+			allow[v.Name] = true
+		}
+		triggers := map[string]bool{}
+		for _, tr := range m.Triggers {
+			triggers[tr.Name] = true
+		}
 		type ddlRow struct {
 			SQL     string `gorm:"column:sql"`
+			Type    string `gorm:"column:type"`
+			Name    string `gorm:"column:name"`
 			TblName string `gorm:"column:tbl_name"`
 		}
 		var rows []ddlRow
 		if err := db.Raw(
-			`SELECT sql, tbl_name FROM sqlite_master
-			 WHERE sql IS NOT NULL AND type IN ('table','index') AND name NOT LIKE 'sqlite_%'
-			 ORDER BY type DESC, name`,
+			`SELECT sql, type, name, tbl_name FROM sqlite_master
+			 WHERE sql IS NOT NULL AND type IN ('table','index','view','trigger') AND name NOT LIKE 'sqlite_%'
+			 ORDER BY CASE type WHEN 'table' THEN 0 WHEN 'index' THEN 1 WHEN 'view' THEN 2 ELSE 3 END, name`,
 		).Scan(&rows).Error; err != nil {
 			return "", err
 		}
 		var stmts []string
 		for _, r := range rows {
-			if allow[r.TblName] {
-				stmts = append(stmts, r.SQL)
+			// Triggers follow the model, so a caller can leave them out.
+			if r.Type == "trigger" && !triggers[r.Name] || !allow[r.TblName] {
+				continue
 			}
+			stmts = append(stmts, r.SQL)
 		}
 		if len(stmts) == 0 {
 			return "", nil
@@ -147,12 +193,23 @@ func DumpSQL(db *gorm.DB, m *Model) (string, error) {
 				Create string `gorm:"column:Create Table"`
 			}
 			var r row
-			if err := db.Raw("SHOW CREATE TABLE " + identQuoter("mysql")(t.Name)).Scan(&r).Error; err != nil {
+			q := m.nameQuoter()
+			if err := db.Raw("SHOW CREATE TABLE " + q(t.Name)).Scan(&r).Error; err != nil {
 				return "", err
 			}
+			// SHOW CREATE TABLE names the table without its database.
+			if _, bare := splitQualified(t.Name); bare != t.Name {
+				r.Create = strings.Replace(r.Create, "CREATE TABLE "+q(bare), "CREATE TABLE "+q(t.Name), 1)
+			}
 			b.WriteString(r.Create)
 			b.WriteString(";\n\n")
 		}
+		for _, v := range m.Views {
+			fmt.Fprintf(&b, "CREATE VIEW %s AS %s;\n\n", m.nameQuoter()(v.Name), v.Definition)
+		}
+		for _, tr := range m.Triggers {
+			fmt.Fprintf(&b, "DELIMITER ;;\n%s;;\nDELIMITER ;\n\n", tr.Definition)
+		}
 		return b.String(), nil
 
 	case "postgres":
@@ -164,8 +221,34 @@ func DumpSQL(db *gorm.DB, m *Model) (string, error) {
 }
 
 func reconstructPostgresDDL(m *Model) string {
-	q := identQuoter("postgres")
+	q := m.nameQuoter()
 	var b strings.Builder
+	schemas := map[string]bool{}
+	for _, t := range m.Tables {
+		if schema, _ := splitQualified(t.Name); schema != "" && !schemas[schema] {
+			schemas[schema] = true
+			fmt.Fprintf(&b, "CREATE SCHEMA IF NOT EXISTS %s;\n", q(schema))
+		}
+	}
+	if len(schemas) > 0 {
+		b.WriteString("\n")
+	}
+	for _, e := range m.Enums {
+		vals := make([]string, len(e.Values))
+		for i, v := range e.Values {
+			vals[i] = pgString(v)
+		}
+		fmt.Fprintf(&b, "CREATE TYPE %s AS ENUM (%s);\n", q(e.Name), strings.Join(vals, ", "))
+	}
+	for _, s := range m.Sequences {
+		fmt.Fprintf(&b, "CREATE SEQUENCE %s START WITH %d INCREMENT BY %d;\n", q(s.Name), s.Start, s.Increment)
+	}
+	for _, f := range m.Functions {
+		fmt.Fprintf(&b, "%s;\n", f.Definition)
+	}
+	if len(m.Enums)+len(m.Sequences)+len(m.Functions) > 0 {
+		b.WriteString("\n")
+	}
 	for _, t := range m.Tables {
 		fmt.Fprintf(&b, "CREATE TABLE %s (\n", q(t.Name))
 		var lines []string
@@ -187,37 +270,92 @@ func reconstructPostgresDDL(m *Model) string {
 			lines = append(lines, "    PRIMARY KEY ("+strings.Join(cols, ", ")+")")
 		}
 		for _, fk := range t.ForeignKeys {
-			from := make([]string, len(fk.Columns))
-			for i, c := range fk.Columns {
-				from[i] = q(c)
-			}
-			to := make([]string, len(fk.RefColumns))
-			for i, c := range fk.RefColumns {
-				to[i] = q(c)
+			lines = append(lines, "    "+foreignKeyClause(q, fk))
+		}
+		for _, ck := range t.Checks {
+			line := "    "
+			if ck.Name != "" {
+				line += "CONSTRAINT " + q(ck.Name) + " "
 			}
-			lines = append(lines, fmt.Sprintf("    FOREIGN KEY (%s) REFERENCES %s (%s)",
-				strings.Join(from, ", "), q(fk.RefTable), strings.Join(to, ", ")))
+			lines = append(lines, line+"CHECK ("+ck.Expression+")")
 		}
 		b.WriteString(strings.Join(lines, ",\n"))
 		b.WriteString("\n);\n")
 		for _, ix := range t.Indexes {
-			kind := "INDEX"
-			if ix.Unique {
-				kind = "UNIQUE INDEX"
-			}
-			cols := make([]string, len(ix.Columns))
-			for i, c := range ix.Columns {
-				cols[i] = q(c)
+			stmt, _ := createIndexSQL(q, t, ix, "postgres", "postgres")
+			b.WriteString(stmt + ";\n")
+		}
+		if t.Comment != "" {
+			fmt.Fprintf(&b, "COMMENT ON TABLE %s IS %s;\n", q(t.Name), pgString(t.Comment))
+		}
+		for _, c := range t.Columns {
+			if c.Comment != "" {
+				fmt.Fprintf(&b, "COMMENT ON COLUMN %s.%s IS %s;\n", q(t.Name), q(c.Name), pgString(c.Comment))
 			}
-			fmt.Fprintf(&b, "CREATE %s %s ON %s (%s);\n", kind, q(ix.Name), q(t.Name), strings.Join(cols, ", "))
 		}
 		b.WriteString("\n")
 	}
+	for _, s := range m.Sequences {
+		if i := strings.LastIndexByte(s.OwnedBy, '.'); i > 0 {
+			fmt.Fprintf(&b, "ALTER SEQUENCE %s OWNED BY %s.%s;\n", q(s.Name), q(s.OwnedBy[:i]), q(s.OwnedBy[i+1:]))
+		}
+	}
+	for _, v := range m.Views {
+		kind := "VIEW"
+		if v.Materialized {
+			kind = "MATERIALIZED VIEW"
+		}
+		fmt.Fprintf(&b, "CREATE %s %s AS\n%s;\n", kind, q(v.Name), v.Definition)
+	}
+	for _, tr := range m.Triggers {
+		fmt.Fprintf(&b, "%s;\n", tr.Definition)
+	}
 	return b.String()
 }
 
+// pgString quotes s as a postgres string literal.
+func pgString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
 // ---------------- helpers ----------------
 
+// fkSummary describes a foreign key, e.g.
+// "fk_posts_user (user_id) -> users(id) ON DELETE CASCADE".
+func fkSummary(fk ForeignKey) string {
+	s := fmt.Sprintf("(%s) -> %s(%s)", strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "))
+	if fk.Name != "" {
+		s = fk.Name + " " + s
+	}
+	if fk.OnDelete != "" {
+		s += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" {
+		s += " ON UPDATE " + fk.OnUpdate
+	}
+	return s
+}
+
+// indexSummary describes the parts of an index, e.g.
+// "(lower(email), created_at DESC) USING gin WHERE deleted_at IS NULL".
+func indexSummary(ix Index) string {
+	parts := make([]string, len(ix.Columns))
+	for i, c := range ix.Columns {
+		parts[i] = c
+		if ix.IsDescending(i) {
+			parts[i] += " DESC"
+		}
+	}
+	s := "(" + strings.Join(parts, ", ") + ")"
+	if ix.Method != "" {
+		s += " USING " + ix.Method
+	}
+	if ix.Where != "" {
+		s += " WHERE " + ix.Where
+	}
+	return s
+}
+
 func contains(ss []string, s string) bool {
 	for _, x := range ss {
 		if x == s {
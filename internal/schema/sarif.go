@@ -0,0 +1,68 @@
+package schema
+
+import "encoding/json"
+
+// SARIF 2.1.0, as much of it as schema:diff and schema:lint need. Schema
+// objects have no file location, so results point at logical locations
+// (a table, or table.column).
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"` // error, warning or note
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+func newSARIFRule(id, description string) sarifRule {
+	return sarifRule{id, sarifMessage{description}}
+}
+
+func newSARIFResult(ruleID, level, text, name, kind string) sarifResult {
+	return sarifResult{
+		RuleID:    ruleID,
+		Level:     level,
+		Message:   sarifMessage{text},
+		Locations: []sarifLocation{{[]sarifLogicalLocation{{name, kind}}}},
+	}
+}
+
+// sarifLog wraps rules and results in a single-run SARIF log.
+func sarifLog(rules []sarifRule, results []sarifResult) ([]byte, error) {
+	if rules == nil {
+		rules = []sarifRule{}
+	}
+	if results == nil {
+		results = []sarifResult{}
+	}
+	log := map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []any{map[string]any{
+			"tool": map[string]any{"driver": map[string]any{
+				"name":           "forge",
+				"informationUri": "https://github.com/acolev/forge",
+				"rules":          rules,
+			}},
+			"results": results,
+		}},
+	}
+	return json.MarshalIndent(log, "", "  ")
+}
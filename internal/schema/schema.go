@@ -6,14 +6,26 @@ package schema
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"gorm.io/gorm"
 )
 
-// Model is a driver-neutral description of a database schema.
+// Model is a driver-neutral description of a database schema. Objects other
+// than tables are omitted from snapshots when there are none, so snapshots of
+// schemas without them are unchanged.
+//
+// Names of tables and other objects outside the connection's current schema
+// (postgres) or database (mysql) are qualified, e.g. "audit.events".
 type Model struct {
-	Driver string
-	Tables []Table
+	Driver    string
+	Schemas   []string `json:",omitempty"` // set when introspected with explicit schemas
+	Tables    []Table
+	Views     []View     `json:",omitempty"`
+	Triggers  []Trigger  `json:",omitempty"`
+	Sequences []Sequence `json:",omitempty"` // postgres
+	Enums     []Enum     `json:",omitempty"` // postgres
+	Functions []Function `json:",omitempty"` // postgres
 }
 
 type Table struct {
@@ -22,6 +34,8 @@ type Table struct {
 	PrimaryKey  []string
 	ForeignKeys []ForeignKey
 	Indexes     []Index
+	Checks      []Check `json:",omitempty"`
+	Comment     string  `json:",omitempty"`
 }
 
 type Column struct {
@@ -29,18 +43,122 @@ type Column struct {
 	Type     string
 	Nullable bool
 	Default  string
+	Comment  string `json:",omitempty"`
+}
+
+// Check is a CHECK constraint; Expression is the condition without CHECK and
+// its outer parentheses.
+type Check struct {
+	Name       string `json:",omitempty"` // empty for unnamed sqlite checks
+	Expression string
+}
+
+// View is a view or materialized view; Definition is its SELECT.
+type View struct {
+	Name         string
+	Materialized bool `json:",omitempty"`
+	Definition   string
+}
+
+// Trigger is a table trigger. Definition is the full CREATE TRIGGER statement.
+type Trigger struct {
+	Name       string
+	Table      string
+	Timing     string // BEFORE, AFTER or INSTEAD OF
+	Event      string // INSERT, UPDATE, DELETE; several joined with " OR "
+	Definition string
+}
+
+// Sequence is a postgres sequence; OwnedBy is the "table.column" it belongs to.
+type Sequence struct {
+	Name      string
+	Start     int64
+	Increment int64
+	OwnedBy   string `json:",omitempty"`
+}
+
+// Enum is a postgres enum type.
+type Enum struct {
+	Name   string
+	Values []string
+}
+
+// Function is a postgres function or procedure; Definition is the full CREATE
+// statement.
+type Function struct {
+	Name       string
+	Arguments  string
+	Definition string
 }
 
 type ForeignKey struct {
+	Name       string `json:",omitempty"` // constraint name; empty when the constraint is unnamed
 	Columns    []string
 	RefTable   string
 	RefColumns []string
+	OnDelete   string `json:",omitempty"` // CASCADE, SET NULL, SET DEFAULT or RESTRICT; empty for NO ACTION
+	OnUpdate   string `json:",omitempty"`
 }
 
 type Index struct {
 	Name    string
-	Columns []string
+	Columns []string // column names; an expression part holds the expression, e.g. lower(email)
 	Unique  bool
+
+	// Expressions[i] and Descending[i] describe Columns[i]; both are nil when
+	// no part is an expression or sorts descending.
+	Expressions []bool `json:",omitempty"`
+	Descending  []bool `json:",omitempty"`
+	Where       string `json:",omitempty"` // predicate of a partial index
+	Method      string `json:",omitempty"` // hash, gin, gist, fulltext, ...; empty for the default btree
+}
+
+// IsExpression reports whether part i of the index is an expression.
+func (ix Index) IsExpression(i int) bool { return i < len(ix.Expressions) && ix.Expressions[i] }
+
+// IsDescending reports whether part i of the index sorts descending.
+func (ix Index) IsDescending(i int) bool { return i < len(ix.Descending) && ix.Descending[i] }
+
+// addPart appends a part to the index, growing Expressions and Descending
+// only once a part needs them.
+func (ix *Index) addPart(col string, expr, desc bool) {
+	n := len(ix.Columns)
+	ix.Columns = append(ix.Columns, col)
+	if expr || ix.Expressions != nil {
+		ix.Expressions = append(ix.Expressions, make([]bool, n+1-len(ix.Expressions))...)
+		ix.Expressions[n] = expr
+	}
+	if desc || ix.Descending != nil {
+		ix.Descending = append(ix.Descending, make([]bool, n+1-len(ix.Descending))...)
+		ix.Descending[n] = desc
+	}
+}
+
+// fkAction normalizes a referential action; NO ACTION, the default, is empty.
+// Postgres reports actions as single letters.
+func fkAction(s string) string {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "", "A", "NO ACTION":
+		return ""
+	case "R", "RESTRICT":
+		return "RESTRICT"
+	case "C", "CASCADE":
+		return "CASCADE"
+	case "N", "SET NULL":
+		return "SET NULL"
+	case "D", "SET DEFAULT":
+		return "SET DEFAULT"
+	}
+	return strings.ToUpper(s)
+}
+
+// indexMethod normalizes an index method; btree, the default, is empty.
+func indexMethod(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "btree" {
+		return ""
+	}
+	return s
 }
 
 // Table returns the table with the given name, or nil.
@@ -55,32 +173,57 @@ func (m *Model) Table(name string) *Table {
 
 // Introspect builds a Model from the live database connection.
 func Introspect(db *gorm.DB) (*Model, error) {
+	return IntrospectSchemas(db, nil)
+}
+
+// IntrospectSchemas builds a Model from the given postgres schemas or mysql
+// databases; none means the current one. sqlite has only "main".
+func IntrospectSchemas(db *gorm.DB, schemas []string) (*Model, error) {
 	driver := db.Dialector.Name()
 	switch driver {
 	case "sqlite":
+		for _, s := range schemas {
+			if s != "main" {
+				return nil, fmt.Errorf("sqlite has no schema %q (only main)", s)
+			}
+		}
 		return introspectSQLite(db)
 	case "postgres":
-		return introspectPostgres(db)
+		return introspectPostgres(db, schemas)
 	case "mysql":
-		return introspectMySQL(db)
+		return introspectMySQL(db, schemas)
 	default:
 		return nil, fmt.Errorf("schema introspection not supported for driver %q", driver)
 	}
 }
 
-// DropAllTables removes every user table in the current database. Used by
-// `forge db fresh`. Foreign-key enforcement is disabled for the duration so
-// drop order does not matter.
+// DropAllTables removes every user table and view in the current database,
+// and on postgres the functions, standalone sequences and enum types as well.
+// Used by `forge db fresh`. Foreign-key enforcement is disabled for the
+// duration so drop order does not matter.
 func DropAllTables(db *gorm.DB) error {
 	m, err := Introspect(db)
 	if err != nil {
 		return err
 	}
-	if len(m.Tables) == 0 {
+	if len(m.Tables)+len(m.Views)+len(m.Functions)+len(m.Sequences)+len(m.Enums) == 0 {
 		return nil
 	}
 
-	q := identQuoter(m.Driver)
+	q := m.nameQuoter()
+	for _, v := range m.Views {
+		drop := "DROP VIEW IF EXISTS "
+		if v.Materialized {
+			drop = "DROP MATERIALIZED VIEW IF EXISTS "
+		}
+		stmt := drop + q(v.Name)
+		if m.Driver == "postgres" {
+			stmt += " CASCADE"
+		}
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("drop view %s: %w", v.Name, err)
+		}
+	}
 	switch m.Driver {
 	case "sqlite":
 		if err := db.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
@@ -108,12 +251,32 @@ func DropAllTables(db *gorm.DB) error {
 				return fmt.Errorf("drop %s: %w", t.Name, err)
 			}
 		}
+		// Triggers went with their tables; serial sequences too.
+		for _, f := range m.Functions {
+			if err := db.Exec(fmt.Sprintf("DROP ROUTINE IF EXISTS %s(%s) CASCADE", q(f.Name), f.Arguments)).Error; err != nil {
+				return fmt.Errorf("drop function %s: %w", f.Name, err)
+			}
+		}
+		for _, s := range m.Sequences {
+			if err := db.Exec("DROP SEQUENCE IF EXISTS " + q(s.Name) + " CASCADE").Error; err != nil {
+				return fmt.Errorf("drop sequence %s: %w", s.Name, err)
+			}
+		}
+		for _, e := range m.Enums {
+			if err := db.Exec("DROP TYPE IF EXISTS " + q(e.Name) + " CASCADE").Error; err != nil {
+				return fmt.Errorf("drop type %s: %w", e.Name, err)
+			}
+		}
 	default:
 		return fmt.Errorf("drop all not supported for driver %q", m.Driver)
 	}
 	return nil
 }
 
+// Quoter returns the identifier quoter for a driver (double quotes, or
+// backticks on mysql).
+func Quoter(driver string) func(string) string { return identQuoter(driver) }
+
 // identQuoter returns a dialect-appropriate identifier quoter.
 func identQuoter(driver string) func(string) string {
 	if driver == "mysql" {
@@ -122,6 +285,92 @@ func identQuoter(driver string) func(string) string {
 	return func(s string) string { return `"` + s + `"` }
 }
 
+// nameQuoter quotes the names of m. A name the introspector qualified with one
+// of m.Schemas, such as audit.events, is quoted part by part; any other name,
+// dots included, is quoted whole.
+func (m *Model) nameQuoter() func(string) string {
+	q := identQuoter(m.Driver)
+	if len(m.Schemas) == 0 {
+		return q
+	}
+	return func(s string) string {
+		if schema, name := splitQualified(s); schema != "" && contains(m.Schemas, schema) {
+			return q(schema) + "." + q(name)
+		}
+		return q(s)
+	}
+}
+
+// splitQualified splits "audit.events" into its schema and name; unqualified
+// names have an empty schema.
+func splitQualified(name string) (schema, table string) {
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
 func sortTables(tables []Table) {
 	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
 }
+
+// DependencyOrder returns the tables ordered so that every table comes after
+// the tables its foreign keys reference, which is the order to create tables or
+// insert rows in. Self-references and references to tables outside the list
+// are ignored; a reference cycle is broken at its first table by name.
+func DependencyOrder(tables []Table) []Table {
+	byName := map[string]Table{}
+	for _, t := range tables {
+		byName[t.Name] = t
+	}
+
+	deps := map[string]map[string]bool{}
+	for _, t := range tables {
+		deps[t.Name] = map[string]bool{}
+		for _, fk := range t.ForeignKeys {
+			if _, ok := byName[fk.RefTable]; ok && fk.RefTable != t.Name {
+				deps[t.Name][fk.RefTable] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(tables))
+	for _, t := range tables {
+		names = append(names, t.Name)
+	}
+	sort.Strings(names)
+
+	var out []Table
+	done := map[string]bool{}
+	for len(out) < len(names) {
+		progressed := false
+		for _, n := range names {
+			if done[n] {
+				continue
+			}
+			ready := true
+			for d := range deps[n] {
+				if !done[d] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				done[n] = true
+				out = append(out, byName[n])
+				progressed = true
+			}
+		}
+		if !progressed {
+			// Cycle: break it at the first remaining table and carry on.
+			for _, n := range names {
+				if !done[n] {
+					done[n] = true
+					out = append(out, byName[n])
+					break
+				}
+			}
+		}
+	}
+	return out
+}
@@ -135,3 +135,287 @@ func TestDropAllTables(t *testing.T) {
 		t.Fatalf("expected 0 tables after drop, got %d", len(m.Tables))
 	}
 }
+
+func TestDependencyOrder(t *testing.T) {
+	tables := []Table{
+		{Name: "comments", ForeignKeys: []ForeignKey{{Columns: []string{"post_id"}, RefTable: "posts"}}},
+		{Name: "posts", ForeignKeys: []ForeignKey{{Columns: []string{"user_id"}, RefTable: "users"}}},
+		{Name: "users", ForeignKeys: []ForeignKey{{Columns: []string{"parent_id"}, RefTable: "users"}}},
+		{Name: "a", ForeignKeys: []ForeignKey{{Columns: []string{"b_id"}, RefTable: "b"}}},
+		{Name: "b", ForeignKeys: []ForeignKey{{Columns: []string{"a_id"}, RefTable: "a"}}},
+	}
+	var got []string
+	for _, t := range DependencyOrder(tables) {
+		got = append(got, t.Name)
+	}
+	want := "users,posts,comments,a,b"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("order = %v, want %s", got, want)
+	}
+}
+
+func TestIntrospectSQLiteObjects(t *testing.T) {
+	db := openTestDB(t)
+	for _, s := range []string{
+		`CREATE TABLE products (
+			id INTEGER PRIMARY KEY,
+			price NUMERIC CHECK (price > 0),
+			qty INTEGER,
+			CONSTRAINT ck_qty CHECK (qty >= 0 AND qty < (1000))
+		)`,
+		`CREATE VIEW active_users AS SELECT id, email FROM users WHERE name IS NOT NULL`,
+		`CREATE TRIGGER trg_posts_touch AFTER UPDATE OF title ON posts
+		 BEGIN UPDATE users SET name = name WHERE id = NEW.user_id; END`,
+	} {
+		if err := db.Exec(s).Error; err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	m, err := Introspect(db)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	products := m.Table("products")
+	if products == nil || len(products.Checks) != 2 {
+		t.Fatalf("products checks = %+v", products)
+	}
+	if c := products.Checks[0]; c.Name != "" || c.Expression != "price > 0" {
+		t.Fatalf("column check = %+v", c)
+	}
+	if c := products.Checks[1]; c.Name != "ck_qty" || c.Expression != "qty >= 0 AND qty < (1000)" {
+		t.Fatalf("table check = %+v", c)
+	}
+	if len(m.Views) != 1 || m.Views[0].Name != "active_users" || !strings.HasPrefix(m.Views[0].Definition, "SELECT id, email") {
+		t.Fatalf("views = %+v", m.Views)
+	}
+	if len(m.Triggers) != 1 {
+		t.Fatalf("triggers = %+v", m.Triggers)
+	}
+	if tr := m.Triggers[0]; tr.Table != "posts" || tr.Timing != "AFTER" || tr.Event != "UPDATE" {
+		t.Fatalf("trigger = %+v", tr)
+	}
+
+	text := RenderText(m)
+	for _, want := range []string{"CHECK ck_qty (qty >= 0", "view active_users", "trigger trg_posts_touch on posts (AFTER UPDATE)"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("text output missing %q:\n%s", want, text)
+		}
+	}
+	ddl, err := DumpSQL(db, m)
+	if err != nil {
+		t.Fatalf("dump: %v", err)
+	}
+	view, trigger := strings.Index(ddl, "CREATE VIEW active_users"), strings.Index(ddl, "CREATE TRIGGER trg_posts_touch")
+	if view < strings.Index(ddl, "CREATE TABLE users") || trigger < view {
+		t.Fatalf("dump order wrong:\n%s", ddl)
+	}
+
+	data, err := SnapshotJSON(m)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if strings.Contains(string(data), `"Sequences"`) {
+		t.Fatalf("empty sequences in snapshot:\n%s", data)
+	}
+	changed := *m
+	changed.Views = []View{{Name: "active_users", Definition: "SELECT id FROM users"}, {Name: "recent_posts", Definition: "SELECT * FROM posts"}}
+	changed.Triggers = nil
+	out := RenderDiff(DiffModels(m, &changed))
+	for _, want := range []string{"~ view active_users", "+ view recent_posts", "- trigger trg_posts_touch"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("diff output missing %q:\n%s", want, out)
+		}
+	}
+
+	if err := DropAllTables(db); err != nil {
+		t.Fatalf("drop all: %v", err)
+	}
+	if m, _ := Introspect(db); len(m.Tables) != 0 || len(m.Views) != 0 {
+		t.Fatalf("left behind: %+v", m)
+	}
+}
+
+func TestIntrospectSQLiteKeyDetails(t *testing.T) {
+	db := openTestDB(t)
+	for _, s := range []string{
+		`CREATE TABLE comments (
+			id INTEGER PRIMARY KEY,
+			post_id INTEGER CONSTRAINT fk_comments_post REFERENCES posts (id) ON DELETE CASCADE,
+			user_id INTEGER,
+			body TEXT,
+			created_at DATETIME,
+			deleted_at DATETIME,
+			FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL ON UPDATE RESTRICT
+		)`,
+		`CREATE INDEX ix_comments_live ON comments (post_id, created_at DESC) WHERE deleted_at IS NULL`,
+		`CREATE INDEX ix_comments_body ON comments (lower(body) COLLATE NOCASE ASC)`,
+	} {
+		if err := db.Exec(s).Error; err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	m, err := Introspect(db)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	comments := m.Table("comments")
+	fks := map[string]ForeignKey{}
+	for _, fk := range comments.ForeignKeys {
+		fks[fk.Columns[0]] = fk
+	}
+	if fk := fks["post_id"]; fk.Name != "fk_comments_post" || fk.OnDelete != "CASCADE" || fk.OnUpdate != "" {
+		t.Fatalf("post_id FK = %+v", fk)
+	}
+	if fk := fks["user_id"]; fk.Name != "" || fk.OnDelete != "SET NULL" || fk.OnUpdate != "RESTRICT" {
+		t.Fatalf("user_id FK = %+v", fk)
+	}
+
+	ixs := map[string]Index{}
+	for _, ix := range comments.Indexes {
+		ixs[ix.Name] = ix
+	}
+	live := ixs["ix_comments_live"]
+	if strings.Join(live.Columns, ",") != "post_id,created_at" || live.IsDescending(0) || !live.IsDescending(1) ||
+		live.Where != "deleted_at IS NULL" || live.Expressions != nil {
+		t.Fatalf("partial index = %+v", live)
+	}
+	body := ixs["ix_comments_body"]
+	if len(body.Columns) != 1 || body.Columns[0] != "lower(body)" || !body.IsExpression(0) || body.Descending != nil {
+		t.Fatalf("expression index = %+v", body)
+	}
+	if users := m.Table("users"); users.Indexes[0].Expressions != nil || users.Indexes[0].Where != "" {
+		t.Fatalf("plain index = %+v", users.Indexes[0])
+	}
+
+	text := RenderText(m)
+	for _, want := range []string{
+		"FK fk_comments_post (post_id) -> posts(id) ON DELETE CASCADE",
+		"INDEX ix_comments_live (post_id, created_at DESC) WHERE deleted_at IS NULL",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("text output missing %q:\n%s", want, text)
+		}
+	}
+
+	changed := *m
+	changed.Tables = append([]Table(nil), m.Tables...)
+	ct := changed.Table("comments")
+	ct.ForeignKeys = append([]ForeignKey(nil), comments.ForeignKeys...)
+	ct.Indexes = append([]Index(nil), comments.Indexes...)
+	for i := range ct.ForeignKeys {
+		ct.ForeignKeys[i].OnDelete = "RESTRICT"
+	}
+	for i := range ct.Indexes {
+		ct.Indexes[i].Where = ""
+	}
+	out := RenderDiff(DiffModels(m, &changed))
+	for _, want := range []string{
+		"+ foreign key fk_comments_post (post_id) -> posts(id) ON DELETE RESTRICT",
+		"- foreign key fk_comments_post (post_id) -> posts(id) ON DELETE CASCADE",
+		"- index index (post_id, created_at DESC) WHERE deleted_at IS NULL",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("diff output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestQualifiedNames(t *testing.T) {
+	// Only names qualified with an introspected schema are split.
+	if got := Quoter("sqlite")("v1.2_data"); got != `"v1.2_data"` {
+		t.Fatalf("sqlite quote = %s", got)
+	}
+	multi := (&Model{Driver: "mysql", Schemas: []string{"shop", "audit"}}).nameQuoter()
+	if got := multi("audit.events"); got != "`audit`.`events`" {
+		t.Fatalf("mysql qualified quote = %s", got)
+	}
+	if got := multi("v1.2_data"); got != "`v1.2_data`" {
+		t.Fatalf("mysql dotted quote = %s", got)
+	}
+	if qualify("public", "public", "users") != "users" || qualify("audit", "public", "events") != "audit.events" {
+		t.Fatal("qualify")
+	}
+
+	m := &Model{Driver: "postgres", Schemas: []string{"public", "audit"}, Tables: []Table{
+		{Name: "audit.events", Columns: []Column{{Name: "id", Type: "integer"}, {Name: "user_id", Type: "integer"}},
+			Indexes:     []Index{{Name: "idx_events_user", Columns: []string{"user_id"}}},
+			ForeignKeys: []ForeignKey{{Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}}}},
+		{Name: "users", Columns: []Column{{Name: "id", Type: "integer"}}, PrimaryKey: []string{"id"}},
+	}}
+	ddl := reconstructPostgresDDL(m)
+	for _, want := range []string{
+		`CREATE SCHEMA IF NOT EXISTS "audit";`,
+		`CREATE TABLE "audit"."events" (`,
+		`CREATE INDEX "idx_events_user" ON "audit"."events" ("user_id")`,
+		`FOREIGN KEY ("user_id") REFERENCES "users" ("id")`,
+	} {
+		if !strings.Contains(ddl, want) {
+			t.Fatalf("ddl missing %q:\n%s", want, ddl)
+		}
+	}
+	if out := RenderMermaid(m); !strings.Contains(out, "audit_events }o--|| users") {
+		t.Fatalf("mermaid missing cross-schema relation:\n%s", out)
+	}
+	if got := goStructName("audit.events"); got != "AuditEvent" {
+		t.Fatalf("struct name = %s", got)
+	}
+
+	db := openTestDB(t)
+	if _, err := IntrospectSchemas(db, []string{"audit"}); err == nil {
+		t.Fatal("expected an error for a sqlite schema other than main")
+	}
+	if m, err := IntrospectSchemas(db, []string{"main"}); err != nil || len(m.Tables) != 2 {
+		t.Fatalf("main: %v", err)
+	}
+}
+
+func TestDiffSources(t *testing.T) {
+	for spec, want := range map[string]string{
+		"":                           "live",
+		"live":                       "live",
+		"postgres://u@db/app":        "dsn",
+		"dsn:database/other.db":      "dsn",
+		"database/schema.json":       "snapshot",
+		"snapshot:schema.snap":       "snapshot",
+		"1712345678":                 "migration",
+		"latest":                     "migration",
+		"migration:001_create_users": "migration",
+		"database/database.db":       "",
+	} {
+		if got, _ := sourceKind(spec); got != want {
+			t.Errorf("sourceKind(%q) = %q, want %q", spec, got, want)
+		}
+	}
+
+	orig := MigrationSQL
+	defer func() { MigrationSQL = orig }()
+	MigrationSQL = func(version string) ([]string, []string, error) {
+		names := []string{"001_users.sql", "002_posts.sql"}
+		sqls := []string{
+			"CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT);",
+			"CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users(id));",
+		}
+		if version == "001" {
+			return names[:1], sqls[:1], nil
+		}
+		return names, sqls, nil
+	}
+	o := sourceOptions{scratch: "sqlite://file:diff_sources?mode=memory&cache=shared"}
+	first, err := loadSource("001", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := loadSource("latest", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Tables) != 1 || len(latest.Tables) != 2 {
+		t.Fatalf("tables = %d and %d, want 1 and 2", len(first.Tables), len(latest.Tables))
+	}
+	d := DiffModels(first, latest)
+	if len(d.AddedTables) != 1 || d.AddedTables[0] != "posts" {
+		t.Fatalf("diff = %+v", d)
+	}
+}
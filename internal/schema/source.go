@@ -0,0 +1,179 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"forge/internal/config"
+	"forge/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// MigrationSQL returns the names and UP SQL of the migrations up to and
+// including a version. The migrations package, which imports this one, sets
+// it; schema:diff uses it to build the schema of a migration version.
+var MigrationSQL func(version string) (names, sqls []string, err error)
+
+// sourceOptions apply to every side of a schema:diff.
+type sourceOptions struct {
+	all     bool
+	schemas []string
+	scratch string // Forge DSN of an empty database for migration versions
+}
+
+// loadSource resolves one side of a schema:diff:
+//
+//	live                  the configured database
+//	postgres://…, sqlite://…, mysql://…
+//	                      another database
+//	path/to/file.json     a snapshot written by schema:snapshot
+//	1712345678, latest    a migration version, built in a scratch database
+//
+// The prefixes snapshot:, migration: and dsn: force a kind.
+func loadSource(spec string, o sourceOptions) (*Model, error) {
+	kind, value := sourceKind(spec)
+	switch kind {
+	case "live":
+		return introspect(o.all, o.schemas)
+	case "snapshot":
+		m, err := LoadSnapshot(value)
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("snapshot %s not found — run `forge db schema:snapshot` first", value)
+		}
+		return m, err
+	case "dsn":
+		db, err := database.Connect(value)
+		if err != nil {
+			return nil, err
+		}
+		defer closeDB(db)
+		m, err := IntrospectSchemas(db, o.schemas)
+		if err != nil {
+			return nil, err
+		}
+		return applyVisibility(m, o.all), nil
+	case "migration":
+		return migrationModel(value, o)
+	}
+	return nil, fmt.Errorf("cannot resolve %q: use live, a DSN (postgres://, mysql://, sqlite://), a snapshot .json file or a migration version", spec)
+}
+
+func sourceKind(spec string) (kind, value string) {
+	spec = strings.TrimSpace(spec)
+	for _, k := range []string{"snapshot", "migration", "dsn"} {
+		if v, ok := strings.CutPrefix(spec, k+":"); ok {
+			return k, v
+		}
+	}
+	lower := strings.ToLower(spec)
+	switch {
+	case lower == "" || lower == "live":
+		return "live", ""
+	case strings.Contains(spec, "://"):
+		return "dsn", spec
+	case strings.HasSuffix(lower, ".json"):
+		return "snapshot", spec
+	case lower == "latest" || isDigits(spec):
+		return "migration", spec
+	}
+	return "", spec
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// migrationModel applies the migrations up to version to an empty database
+// and introspects it. Without --scratch that is an in-memory sqlite database
+// for sqlite projects, and a temporary database on the configured server for
+// postgres and mysql, dropped afterwards.
+func migrationModel(version string, o sourceOptions) (*Model, error) {
+	if MigrationSQL == nil {
+		return nil, fmt.Errorf("migration versions are not available")
+	}
+	names, sqls, err := MigrationSQL(version)
+	if err != nil {
+		return nil, err
+	}
+
+	scratch := o.scratch
+	if scratch == "" {
+		var cleanup func()
+		if scratch, cleanup, err = scratchDatabase(); err != nil {
+			return nil, err
+		}
+		defer cleanup()
+	}
+	db, err := database.Connect(scratch)
+	if err != nil {
+		return nil, fmt.Errorf("scratch database: %w", err)
+	}
+	defer closeDB(db)
+
+	for i, sql := range sqls {
+		if strings.TrimSpace(sql) == "" {
+			continue
+		}
+		if err := db.Exec(sql).Error; err != nil {
+			return nil, fmt.Errorf("apply %s to the scratch database: %w", names[i], err)
+		}
+	}
+	m, err := IntrospectSchemas(db, o.schemas)
+	if err != nil {
+		return nil, err
+	}
+	return applyVisibility(m, o.all), nil
+}
+
+// scratchDatabase creates an empty database of the configured driver and
+// returns its Forge DSN and a function that drops it.
+func scratchDatabase() (string, func(), error) {
+	settings, err := config.CurrentSettings()
+	if err != nil {
+		return "", nil, err
+	}
+	driver, _, err := database.DriverDSN(settings.DBDSN)
+	if err != nil {
+		return "", nil, err
+	}
+	name := fmt.Sprintf("forge_diff_%d", time.Now().UnixNano())
+	if driver == "sqlite" {
+		return "sqlite://file:" + name + "?mode=memory&cache=shared", func() {}, nil
+	}
+
+	admin, err := database.ConnectSettings(settings)
+	if err != nil {
+		return "", nil, err
+	}
+	q := identQuoter(driver)
+	if err := admin.Exec("CREATE DATABASE " + q(name)).Error; err != nil {
+		closeDB(admin)
+		return "", nil, fmt.Errorf("create scratch database %s (or pass --scratch <dsn> of an empty database): %w", name, err)
+	}
+	cleanup := func() {
+		if err := admin.Exec("DROP DATABASE IF EXISTS " + q(name)).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not drop scratch database %s: %v\n", name, err)
+		}
+		closeDB(admin)
+	}
+	dsn, err := database.WithDatabase(settings.DBDSN, name)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return dsn, cleanup, nil
+}
+
+func closeDB(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
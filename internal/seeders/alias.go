@@ -0,0 +1,501 @@
+package seeders
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"forge/internal/schema"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// A fixture row can be named with _alias and referenced as "@alias.column" by
+// later rows of the same fixture or of any later seed:
+//
+//	- table: users
+//	  rows:
+//	    - { _alias: alice, email: alice@example.com }
+//	- table: posts
+//	  rows:
+//	    - { author_id: "@alice.id", title: Hello }
+//
+// Generated primary keys are read back after the insert. Aliases are stored
+// with the seed, so they stay usable in later runs until the seed is rolled
+// back. "@name.column" is a reference only when some seed declares the alias
+// name, so literals such as "@john.doe" are inserted as written; a string
+// starting with "@@" stands for itself minus the first "@".
+
+const aliasField = "_alias"
+
+var (
+	aliasNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	aliasRefRe  = regexp.MustCompile(`^@([A-Za-z_][A-Za-z0-9_-]*)\.([A-Za-z_][A-Za-z0-9_]*)$`)
+)
+
+// aliasKey locates an aliased row.
+type aliasKey struct {
+	Table   string   `json:"table"`
+	Columns []string `json:"columns"`
+	Key     []any    `json:"key"`
+}
+
+func encodeAliases(m map[string]aliasKey) string {
+	if len(m) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(m)
+	return string(b)
+}
+
+func decodeAliases(s string) (map[string]aliasKey, error) {
+	m := map[string]aliasKey{}
+	if strings.TrimSpace(s) == "" {
+		return m, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid aliases: %w", err)
+	}
+	return m, nil
+}
+
+// aliasRef parses "@alias.column".
+func aliasRef(v any) (name, col string, ok bool) {
+	s, isStr := v.(string)
+	if !isStr {
+		return "", "", false
+	}
+	m := aliasRefRe.FindStringSubmatch(s)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// aliasRefs lists the aliases a value references, nested values included.
+func aliasRefs(v any, out map[string]bool) {
+	switch x := v.(type) {
+	case string:
+		if name, _, ok := aliasRef(x); ok {
+			out[name] = true
+		}
+	case map[string]any:
+		for _, c := range x {
+			aliasRefs(c, out)
+		}
+	case []any:
+		for _, c := range x {
+			aliasRefs(c, out)
+		}
+	}
+}
+
+// takeAliases removes _alias from the rows and returns it per row ("" for
+// rows without one).
+func takeAliases(rows []map[string]any) ([]string, error) {
+	names := make([]string, len(rows))
+	seen := map[string]int{}
+	for i, r := range rows {
+		raw, ok := r[aliasField]
+		if !ok {
+			continue
+		}
+		delete(r, aliasField)
+		name := strings.TrimSpace(fmt.Sprint(raw))
+		if !aliasNameRe.MatchString(name) {
+			return nil, fmt.Errorf("row %d: invalid %s %q", i+1, aliasField, name)
+		}
+		if prev, dup := seen[name]; dup {
+			return nil, fmt.Errorf("rows %d and %d: duplicate %s %q", prev+1, i+1, aliasField, name)
+		}
+		seen[name] = i
+		names[i] = name
+	}
+	return names, nil
+}
+
+// aliasGroups splits the rows into insert rounds: every row comes in a later
+// round than the rows of the same fixture whose aliases it references, and
+// rows keep their order within a round.
+func aliasGroups(rows []map[string]any, names []string) ([][]int, error) {
+	defined := map[string]int{}
+	for i, n := range names {
+		if n != "" {
+			defined[n] = i
+		}
+	}
+	if len(defined) == 0 {
+		all := make([]int, len(rows))
+		for i := range all {
+			all[i] = i
+		}
+		return [][]int{all}, nil
+	}
+
+	level := make([]int, len(rows))
+	state := make([]int, len(rows)) // 0 new, 1 visiting, 2 done
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case 1:
+			return fmt.Errorf("row %d: aliases reference each other in a cycle", i+1)
+		case 2:
+			return nil
+		}
+		state[i] = 1
+		refs := map[string]bool{}
+		for _, v := range rows[i] {
+			aliasRefs(v, refs)
+		}
+		for name := range refs {
+			j, ok := defined[name]
+			if !ok {
+				continue
+			}
+			if err := visit(j); err != nil {
+				return err
+			}
+			if level[j]+1 > level[i] {
+				level[i] = level[j] + 1
+			}
+		}
+		state[i] = 2
+		return nil
+	}
+
+	var groups [][]int
+	for i := range rows {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+		for len(groups) <= level[i] {
+			groups = append(groups, nil)
+		}
+		groups[level[i]] = append(groups[level[i]], i)
+	}
+	return groups, nil
+}
+
+// insertedRow is a row written by a fixture, located by its primary key.
+type insertedRow struct {
+	table  string
+	cols   []string
+	key    []any
+	values map[string]any // the inserted values, where known
+}
+
+// column returns a column of the row, from the inserted values when they have
+// it and from the database otherwise.
+func (r *insertedRow) column(tx *gorm.DB, col string) (any, error) {
+	if v, ok := r.values[col]; ok {
+		return v, nil
+	}
+	q := schema.Quoter(tx.Dialector.Name())
+	where, args := keyCondition(q, r.cols, [][]any{r.key})
+	var v any
+	err := tx.Raw(fmt.Sprintf("SELECT %s FROM %s WHERE %s", q(col), q(r.table), where), args...).Row().Scan(&v)
+	if err != nil {
+		return nil, fmt.Errorf("read %s.%s: %w", r.table, col, err)
+	}
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+	r.values[col] = v
+	return v, nil
+}
+
+// readInserted locates a row just inserted into the seed's table, reading back
+// the primary key when the database assigned it. affected is the insert's row
+// count.
+func readInserted(tx *gorm.DB, s YAMLSeed, row map[string]any, affected int64) (*insertedRow, error) {
+	cols, auto, err := primaryKey(tx, s.Table)
+	if err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("table %s has no primary key", s.Table)
+	}
+
+	key, ok := rowKey(row, cols)
+	switch {
+	case ok:
+	case s.OnConflict != "" && len(s.ConflictKey) > 0:
+		// An upsert may have touched an existing row: find it by the conflict key.
+		if key, err = conflictRowKey(tx, s, cols, row); err != nil {
+			return nil, err
+		}
+	case affected == 0:
+		return nil, fmt.Errorf("the row was not inserted (conflict); set conflict_key so the existing row can be found")
+	case auto:
+		id, err := lastInsertID(tx)
+		if err != nil {
+			return nil, err
+		}
+		key = []any{id}
+	default:
+		return nil, fmt.Errorf("give the primary key of %s in the row; only auto-increment keys are read back", s.Table)
+	}
+
+	r := &insertedRow{table: s.Table, cols: cols, key: key, values: map[string]any{}}
+	for k, v := range row {
+		if _, isExpr := v.(clause.Expr); !isExpr {
+			r.values[k] = v
+		}
+	}
+	for i, c := range cols {
+		r.values[c] = key[i]
+	}
+	return r, nil
+}
+
+// aliasSet resolves "@alias.column" for one fixture: against the aliases of
+// executed seeds, loaded on first use, and those the fixture defines.
+type aliasSet struct {
+	tx       *gorm.DB
+	known    map[string]*insertedRow
+	owner    map[string]string   // alias -> seed that defined it
+	defined  map[string]aliasKey // defined by this fixture
+	declared map[string]bool     // declared by this fixture or another seed
+}
+
+// newAliasSet starts the aliases of fixture s, whose rows declare names.
+func newAliasSet(tx *gorm.DB, s YAMLSeed, names []string) *aliasSet {
+	a := &aliasSet{tx: tx, defined: map[string]aliasKey{}, declared: map[string]bool{}}
+	for name := range s.declared {
+		a.declared[name] = true
+	}
+	for _, name := range names {
+		if name != "" {
+			a.declared[name] = true
+		}
+	}
+	return a
+}
+
+func (a *aliasSet) load() error {
+	if a.known != nil {
+		return nil
+	}
+	var seeds []Seed
+	if err := a.tx.Select("name", "aliases").Where("aliases <> ''").Find(&seeds).Error; err != nil {
+		return fmt.Errorf("load aliases: %w", err)
+	}
+	a.known, a.owner = map[string]*insertedRow{}, map[string]string{}
+	for _, s := range seeds {
+		m, err := decodeAliases(s.Aliases)
+		if err != nil {
+			return fmt.Errorf("seed %q: %w", s.Name, err)
+		}
+		for name, k := range m {
+			key := make([]any, len(k.Key))
+			for i, v := range k.Key {
+				key[i] = keyValue(v)
+			}
+			a.known[name] = &insertedRow{table: k.Table, cols: k.Columns, key: key, values: map[string]any{}}
+			a.owner[name] = s.Name
+		}
+	}
+	return nil
+}
+
+// resolve replaces alias references in v; "@@x" becomes "@x".
+func (a *aliasSet) resolve(v any) (any, error) {
+	switch x := v.(type) {
+	case string:
+		if strings.HasPrefix(x, "@@") {
+			return x[1:], nil
+		}
+		if name, col, ok := aliasRef(x); ok {
+			if err := a.load(); err != nil {
+				return nil, err
+			}
+			if a.known[name] == nil && !a.declared[name] {
+				return x, nil // not an alias: a literal like "@john.doe"
+			}
+			return a.lookup(name, col)
+		}
+	case map[string]any:
+		for k, c := range x {
+			r, err := a.resolve(c)
+			if err != nil {
+				return nil, err
+			}
+			x[k] = r
+		}
+	case []any:
+		for i, c := range x {
+			r, err := a.resolve(c)
+			if err != nil {
+				return nil, err
+			}
+			x[i] = r
+		}
+	}
+	return v, nil
+}
+
+func (a *aliasSet) resolveRow(row map[string]any) error {
+	for k, v := range row {
+		r, err := a.resolve(v)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", k, err)
+		}
+		row[k] = r
+	}
+	return nil
+}
+
+func (a *aliasSet) lookup(name, col string) (any, error) {
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	r, ok := a.known[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown alias %q (a seed defining it must run first; see depends_on)", name)
+	}
+	v, err := r.column(a.tx, col)
+	if err != nil {
+		return nil, fmt.Errorf("alias %q: %w", name, err)
+	}
+	return v, nil
+}
+
+// add names an inserted row.
+func (a *aliasSet) add(s YAMLSeed, name string, r *insertedRow) error {
+	if err := a.load(); err != nil {
+		return err
+	}
+	if owner, dup := a.owner[name]; dup {
+		return fmt.Errorf("alias %q is already defined by seed %q", name, owner)
+	}
+	a.known[name], a.owner[name] = r, s.Name
+	a.defined[name] = aliasKey{Table: r.table, Columns: r.cols, Key: r.key}
+	return nil
+}
+
+// conflictRowKey finds the primary key of the row matching the conflict key
+// columns of row.
+func conflictRowKey(tx *gorm.DB, s YAMLSeed, cols []string, row map[string]any) ([]any, error) {
+	q := schema.Quoter(tx.Dialector.Name())
+	conds := make([]string, len(s.ConflictKey))
+	args := make([]any, len(s.ConflictKey))
+	for i, c := range s.ConflictKey {
+		v, ok := row[c]
+		if !ok {
+			return nil, fmt.Errorf("conflict_key column %s is missing from the row", c)
+		}
+		conds[i], args[i] = q(c)+" = ?", v
+	}
+	key := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range key {
+		ptrs[i] = &key[i]
+	}
+	err := tx.Raw(fmt.Sprintf("SELECT %s FROM %s WHERE %s", quoteCols(q, cols), q(s.Table), strings.Join(conds, " AND ")), args...).
+		Row().Scan(ptrs...)
+	if err != nil {
+		return nil, fmt.Errorf("find row by conflict_key: %w", err)
+	}
+	for i, v := range key {
+		if b, ok := v.([]byte); ok {
+			key[i] = string(b)
+		}
+	}
+	return key, nil
+}
+
+// lastInsertID reads the key generated by the previous insert on tx.
+func lastInsertID(tx *gorm.DB) (int64, error) {
+	var query string
+	switch tx.Dialector.Name() {
+	case "sqlite":
+		query = "SELECT last_insert_rowid()"
+	case "mysql":
+		query = "SELECT LAST_INSERT_ID()"
+	case "postgres":
+		query = "SELECT lastval()"
+	default:
+		return 0, fmt.Errorf("reading generated keys is not supported on %s", tx.Dialector.Name())
+	}
+	var id int64
+	if err := tx.Raw(query).Row().Scan(&id); err != nil {
+		return 0, fmt.Errorf("read generated key: %w", err)
+	}
+	return id, nil
+}
+
+// linkAliasDeps makes every seed depend on the seeds defining the aliases its
+// rows reference, and tells it which of those aliases are declared. Only
+// literal aliases are seen; an _alias built by an expression needs an explicit
+// depends_on.
+func linkAliasDeps(all []seedEntry) {
+	definedBy := map[string]string{}
+	for _, e := range all {
+		for _, r := range fixtureRowsOf(e.seed) {
+			if name, ok := r[aliasField].(string); ok && !strings.Contains(name, "{{") {
+				definedBy[strings.TrimSpace(name)] = e.seed.Name
+			}
+		}
+	}
+	if len(definedBy) == 0 {
+		return
+	}
+	for i := range all {
+		s := &all[i].seed
+		refs := map[string]bool{}
+		for _, r := range fixtureRowsOf(*s) {
+			for _, v := range r {
+				aliasRefs(v, refs)
+			}
+		}
+		names := make([]string, 0, len(refs))
+		for name := range refs {
+			owner, ok := definedBy[name]
+			if !ok {
+				continue
+			}
+			if s.declared == nil {
+				s.declared = map[string]bool{}
+			}
+			s.declared[name] = true
+			if owner != s.Name && !strIn(s.DependsOn, owner) {
+				names = append(names, owner)
+			}
+		}
+		sort.Strings(names)
+		for _, owner := range names {
+			if !strIn(s.DependsOn, owner) {
+				s.DependsOn = append(append([]string{}, s.DependsOn...), owner)
+			}
+		}
+	}
+}
+
+// fixtureRowsOf returns the literal rows and the templates of a fixture seed,
+// its children's included.
+func fixtureRowsOf(s YAMLSeed) []map[string]any {
+	if !strings.EqualFold(strings.TrimSpace(s.Type), "fixture") {
+		return nil
+	}
+	rows := append([]map[string]any{}, s.Rows...)
+	if s.Template != nil {
+		rows = append(rows, s.Template)
+	}
+	var walk func([]YAMLChild)
+	walk = func(children []YAMLChild) {
+		for _, ch := range children {
+			if ch.Template != nil {
+				rows = append(rows, ch.Template)
+			}
+			walk(ch.Children)
+		}
+	}
+	walk(s.Children)
+	return rows
+}
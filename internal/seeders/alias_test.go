@@ -0,0 +1,107 @@
+package seeders
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFixtureAliases(t *testing.T) {
+	db := openSeedDB(t,
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT UNIQUE, name TEXT, manager_id INTEGER)`,
+		`CREATE TABLE posts (id INTEGER PRIMARY KEY, author_id INTEGER, title TEXT)`,
+		`INSERT INTO users (id, email) VALUES (7, 'old@example.com')`,
+	)
+
+	// alice references boss, defined by a later row: boss is inserted first.
+	if err := runFixture(db, YAMLSeed{Name: "users", Type: "fixture", Table: "users", Rows: []map[string]any{
+		{"_alias": "alice", "email": "alice@example.com", "manager_id": "@boss.id"},
+		{"_alias": "boss", "email": "boss@example.com", "name": "Boss"},
+		{"email": "plain@example.com"},
+	}}, 1); err != nil {
+		t.Fatalf("users: %v", err)
+	}
+	// A later seed reads both the generated key and a column loaded back from
+	// the database; an upsert captures the existing row's key.
+	if err := runFixture(db, YAMLSeed{Name: "posts", Type: "fixture", Table: "posts", Rows: []map[string]any{
+		{"author_id": "@alice.id", "title": "@boss.name"},
+		{"author_id": "@alice.manager_id", "title": "@@handle"},
+	}}, 1); err != nil {
+		t.Fatalf("posts: %v", err)
+	}
+	if err := runFixture(db, YAMLSeed{Name: "old", Type: "fixture", Table: "users", OnConflict: "update_all", ConflictKey: []string{"email"},
+		Rows: []map[string]any{{"_alias": "old", "email": "old@example.com", "name": "Old"}},
+	}, 1); err != nil {
+		t.Fatalf("old: %v", err)
+	}
+
+	var got []struct {
+		AuthorID int
+		Title    string
+	}
+	db.Table("posts").Order("id").Find(&got)
+	var aliceID, bossID int
+	db.Table("users").Where("email = ?", "alice@example.com").Pluck("id", &aliceID)
+	db.Table("users").Where("email = ?", "boss@example.com").Pluck("id", &bossID)
+	if len(got) != 2 || got[0].AuthorID != aliceID || got[0].Title != "Boss" || got[1].AuthorID != bossID || got[1].Title != "@handle" {
+		t.Fatalf("posts = %+v (alice %d, boss %d)", got, aliceID, bossID)
+	}
+
+	var old Seed
+	db.Where("name = ?", "old").First(&old)
+	if m, err := decodeAliases(old.Aliases); err != nil || keyString(m["old"].Key) != "7" {
+		t.Fatalf("old aliases = %q, %v", old.Aliases, err)
+	}
+
+	// No seed declares john or example: the values are kept as written.
+	if err := runFixture(db, YAMLSeed{Name: "literals", Type: "fixture", Table: "users", Rows: []map[string]any{
+		{"email": "@example.com", "name": "@john.doe"},
+	}}, 1); err != nil {
+		t.Fatalf("literals: %v", err)
+	}
+	var name string
+	db.Table("users").Where("email = ?", "@example.com").Pluck("name", &name)
+	if name != "@john.doe" {
+		t.Fatalf("literal name = %q", name)
+	}
+
+	for name, s := range map[string]YAMLSeed{
+		"duplicate": {Rows: []map[string]any{{"_alias": "alice", "email": "again@example.com"}}},
+		// nobody is declared by a seed that has not run.
+		"unknown": {Rows: []map[string]any{{"email": "x@example.com", "manager_id": "@nobody.id"}}, declared: map[string]bool{"nobody": true}},
+		"cycle":   {Rows: []map[string]any{{"_alias": "a", "manager_id": "@b.id"}, {"_alias": "b", "manager_id": "@a.id"}}},
+	} {
+		s.Name, s.Type, s.Table = name, "fixture", "users"
+		if err := runFixture(db, s, 2); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	var n int64
+	db.Model(&Seed{}).Where("batch = 2").Count(&n)
+	if n != 0 {
+		t.Fatalf("failed seeds were recorded: %d", n)
+	}
+}
+
+func TestLinkAliasDeps(t *testing.T) {
+	all := []seedEntry{
+		{seed: YAMLSeed{Name: "posts", Type: "fixture", Template: map[string]any{"author_id": "@alice.id"}}},
+		{seed: YAMLSeed{Name: "users", Type: "fixture", Rows: []map[string]any{{"_alias": "alice"}, {"_alias": "bob", "manager_id": "@alice.id"}}}},
+		{seed: YAMLSeed{Name: "tags", Type: "fixture", Rows: []map[string]any{{"owner": "@bob.id", "note": "@@alice.id"}}}},
+	}
+	linkAliasDeps(all)
+	for i, want := range []string{"users", "", "users"} {
+		if got := strings.Join(all[i].seed.DependsOn, ","); got != want {
+			t.Errorf("%s depends on %q, want %q", all[i].seed.Name, got, want)
+		}
+	}
+	if !all[2].seed.declared["bob"] || all[2].seed.declared["alice"] {
+		t.Errorf("tags declared aliases = %v", all[2].seed.declared)
+	}
+
+	if got := literalFixtureString("@alice.id"); got != "@@alice.id" {
+		t.Errorf("literalFixtureString = %q", got)
+	}
+	if got := literalFixtureString("@home"); got != "@home" {
+		t.Errorf("literalFixtureString = %q", got)
+	}
+}
@@ -0,0 +1,318 @@
+package seeders
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"forge/internal/schema"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// DefaultAnonymizeFile is where `--anonymize` looks for rules by default.
+const DefaultAnonymizeFile = "database/anonymize.yaml"
+
+// AnonymizeSaltKey overrides the salt of the anonymization config.
+const AnonymizeSaltKey = "FORGE_ANONYMIZE_SALT"
+
+// Anonymizer rewrites personal data while rows are copied or exported.
+//
+// Rules are given per table and column:
+//
+//	salt: change-me
+//	tables:
+//	  users:
+//	    email: fake:email      # any fake: token
+//	    password: hash         # sha256 of salt+value (hash:N keeps N hex chars)
+//	    phone: null
+//	    bio: truncate:40       # keep the first 40 characters
+//	    city: shuffle          # permute the column's values across rows
+//	    id: keep
+//	  sessions: truncate       # drop every row of the table
+//
+// Fake and hash values depend only on the salt and the input value, so the
+// same input always maps to the same output and values shared between tables
+// stay consistent. Columns without a rule are kept.
+type Anonymizer struct {
+	salt  string
+	skip  map[string]bool
+	rules map[string]map[string]anonRule
+	pools map[string]*shufflePool // "table.column"
+}
+
+type anonRule struct {
+	kind string // fake, hash, null, keep, truncate, shuffle
+	arg  string // fake token, hash/truncate length
+	n    int
+}
+
+type shufflePool struct {
+	values []any
+	perm   []int
+}
+
+type anonymizeFile struct {
+	Salt   string               `yaml:"salt"`
+	Tables map[string]yaml.Node `yaml:"tables"`
+}
+
+// LoadAnonymizer reads anonymization rules from a YAML file.
+func LoadAnonymizer(path string) (*Anonymizer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	a, err := ParseAnonymizer(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return a, nil
+}
+
+// ParseAnonymizer parses anonymization rules (see Anonymizer).
+func ParseAnonymizer(b []byte) (*Anonymizer, error) {
+	var f anonymizeFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+	a := &Anonymizer{
+		salt:  f.Salt,
+		skip:  map[string]bool{},
+		rules: map[string]map[string]anonRule{},
+		pools: map[string]*shufflePool{},
+	}
+	if env := os.Getenv(AnonymizeSaltKey); env != "" {
+		a.salt = env
+	}
+	for table, node := range f.Tables {
+		switch node.Kind {
+		case yaml.ScalarNode:
+			if node.Value != "truncate" {
+				return nil, fmt.Errorf("table %s: unknown table rule %q (only truncate)", table, node.Value)
+			}
+			a.skip[table] = true
+		case yaml.MappingNode:
+			var cols map[string]*string
+			if err := node.Decode(&cols); err != nil {
+				return nil, fmt.Errorf("table %s: %w", table, err)
+			}
+			a.rules[table] = map[string]anonRule{}
+			for col, raw := range cols {
+				spec := "null" // a bare YAML null
+				if raw != nil {
+					spec = *raw
+				}
+				r, err := parseAnonRule(spec)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %w", table, col, err)
+				}
+				a.rules[table][col] = r
+			}
+		default:
+			return nil, fmt.Errorf("table %s: expected column rules or truncate", table)
+		}
+	}
+	return a, nil
+}
+
+func parseAnonRule(spec string) (anonRule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "fake:") {
+		// Validate the token once, up front.
+		if _, err := newFaker(0).value(spec); err != nil {
+			return anonRule{}, err
+		}
+		return anonRule{kind: "fake", arg: spec}, nil
+	}
+	kind, arg, _ := strings.Cut(spec, ":")
+	r := anonRule{kind: kind, arg: arg}
+	switch kind {
+	case "keep", "null", "shuffle":
+		if arg != "" {
+			return r, fmt.Errorf("rule %q takes no argument", kind)
+		}
+	case "hash", "truncate":
+		if kind == "truncate" && arg == "" {
+			// A bare truncate drops a whole table; for a column it would
+			// quietly blank every value.
+			return r, fmt.Errorf("truncate needs a length for a column (truncate:N)")
+		}
+		if arg != "" {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 {
+				return r, fmt.Errorf("invalid length in %q", spec)
+			}
+			r.n = n
+		}
+	default:
+		return r, fmt.Errorf("unknown rule %q (use fake:<token>, hash, null, keep, truncate:N or shuffle)", spec)
+	}
+	return r, nil
+}
+
+// SkipTable reports whether no rows of table may leave the source.
+func (a *Anonymizer) SkipTable(table string) bool {
+	return a != nil && a.skip[table]
+}
+
+// Prepare loads what the rules of a table need up front: the value pool of
+// shuffled columns. from is the FROM clause of the caller's row scan, with its
+// filters, order and limit, so the pool holds exactly the rows scanned.
+func (a *Anonymizer) Prepare(db *gorm.DB, table, from string) error {
+	if a == nil {
+		return nil
+	}
+	q := schema.Quoter(db.Dialector.Name())
+	for col, r := range a.rules[table] {
+		if r.kind != "shuffle" {
+			continue
+		}
+		var values []any
+		rows, err := db.Raw("SELECT " + q(col) + " " + from).Rows()
+		if err != nil {
+			return fmt.Errorf("shuffle %s.%s: %w", table, col, err)
+		}
+		for rows.Next() {
+			var v any
+			if err := rows.Scan(&v); err != nil {
+				rows.Close()
+				return fmt.Errorf("shuffle %s.%s: %w", table, col, err)
+			}
+			values = append(values, v)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("shuffle %s.%s: %w", table, col, err)
+		}
+		rnd := mathrand.New(mathrand.NewSource(a.seed("shuffle", table+"."+col)))
+		a.pools[table+"."+col] = &shufflePool{values: values, perm: rnd.Perm(len(values))}
+	}
+	return nil
+}
+
+// Apply rewrites one row in place. index is the row's position in the table
+// scan and picks its value for shuffled columns.
+func (a *Anonymizer) Apply(table string, cols []string, vals []any, index int64) error {
+	if a == nil {
+		return nil
+	}
+	rules := a.rules[table]
+	if len(rules) == 0 {
+		return nil
+	}
+	for i, col := range cols {
+		r, ok := rules[col]
+		if !ok {
+			continue
+		}
+		if r.kind == "shuffle" {
+			pool := a.pools[table+"."+col]
+			if pool == nil {
+				return fmt.Errorf("%s.%s: shuffle pool not prepared", table, col)
+			}
+			if len(pool.values) > 0 {
+				vals[i] = pool.values[pool.perm[int(index%int64(len(pool.perm)))]]
+			}
+			continue
+		}
+		v, err := a.apply(r, vals[i])
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", table, col, err)
+		}
+		vals[i] = v
+	}
+	return nil
+}
+
+// ApplyValue anonymizes a single value of table.column. It reports false for
+// shuffled columns, whose output depends on the row rather than the value.
+func (a *Anonymizer) ApplyValue(table, col string, v any) (any, bool, error) {
+	if a == nil {
+		return v, true, nil
+	}
+	r, ok := a.rules[table][col]
+	if !ok {
+		return v, true, nil
+	}
+	if r.kind == "shuffle" {
+		return v, false, nil
+	}
+	out, err := a.apply(r, v)
+	return out, err == nil, err
+}
+
+func (a *Anonymizer) apply(r anonRule, v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch r.kind {
+	case "keep":
+		return v, nil
+	case "null":
+		return nil, nil
+	case "hash":
+		sum := sha256.Sum256([]byte(a.salt + "\x00" + anonString(v)))
+		h := hex.EncodeToString(sum[:])
+		if r.n > 0 && r.n < len(h) {
+			h = h[:r.n]
+		}
+		return h, nil
+	case "truncate":
+		s := []rune(anonString(v))
+		if len(s) > r.n {
+			s = s[:r.n]
+		}
+		return string(s), nil
+	case "fake":
+		input := anonString(v)
+		f := newFaker(a.seed(r.arg, input))
+		f.now = fakeEpoch
+		out, err := f.value(r.arg)
+		if err != nil {
+			return nil, err
+		}
+		if r.arg == "fake:email" {
+			// Fake names repeat; a tag derived from the input keeps the
+			// addresses unique like the originals.
+			if s, ok := out.(string); ok {
+				local, domain, _ := strings.Cut(s, "@")
+				out = fmt.Sprintf("%s.%s@%s", local, a.tag(input), domain)
+			}
+		}
+		return out, nil
+	}
+	return nil, errors.New("unknown rule " + r.kind)
+}
+
+// seed derives a deterministic random seed from the salt and the inputs.
+func (a *Anonymizer) seed(parts ...string) int64 {
+	sum := sha256.Sum256([]byte(a.salt + "\x00" + strings.Join(parts, "\x00")))
+	return int64(binary.BigEndian.Uint64(sum[:8]))
+}
+
+func (a *Anonymizer) tag(input string) string {
+	sum := sha256.Sum256([]byte(a.salt + "\x00tag\x00" + input))
+	return hex.EncodeToString(sum[:5])
+}
+
+func anonString(v any) string {
+	switch x := v.(type) {
+	case []byte:
+		return string(x)
+	case string:
+		return x
+	case time.Time:
+		return x.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(x)
+	}
+}
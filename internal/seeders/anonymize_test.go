@@ -0,0 +1,72 @@
+package seeders
+
+import (
+	"strings"
+	"testing"
+)
+
+const anonRules = `
+salt: s3cret
+tables:
+  users:
+    email: fake:email
+    password: hash:16
+    phone: null
+    bio: truncate:5
+    city: shuffle
+    id: keep
+  sessions: truncate
+`
+
+func TestAnonymizerRules(t *testing.T) {
+	a, err := ParseAnonymizer([]byte(anonRules))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !a.SkipTable("sessions") || a.SkipTable("users") {
+		t.Fatal("only sessions should be skipped")
+	}
+
+	cols := []string{"id", "email", "password", "phone", "bio", "name"}
+	row := func() []any { return []any{int64(1), "real@corp.com", "hunter2", "+1 555", "Long biography", "Ann"} }
+
+	first := row()
+	if err := a.Apply("users", cols, first, 0); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if first[0] != int64(1) || first[5] != "Ann" {
+		t.Fatalf("keep / unlisted columns changed: %v", first)
+	}
+	email, _ := first[1].(string)
+	if email == "real@corp.com" || !strings.Contains(email, "@") {
+		t.Fatalf("email not faked: %v", first[1])
+	}
+	if h, _ := first[2].(string); len(h) != 16 {
+		t.Fatalf("hash = %v, want 16 hex chars", first[2])
+	}
+	if first[3] != nil || first[4] != "Long " {
+		t.Fatalf("null/truncate = %v, %v", first[3], first[4])
+	}
+
+	// Deterministic: the same input gives the same output, also for a single value.
+	second := row()
+	_ = a.Apply("users", cols, second, 7)
+	if second[1] != first[1] || second[2] != first[2] {
+		t.Fatalf("not deterministic: %v vs %v", first, second)
+	}
+	if v, ok, _ := a.ApplyValue("users", "email", "real@corp.com"); !ok || v != first[1] {
+		t.Fatalf("ApplyValue = %v, want %v", v, first[1])
+	}
+	other := row()
+	other[1] = "someone@corp.com"
+	_ = a.Apply("users", cols, other, 0)
+	if other[1] == first[1] {
+		t.Fatal("different inputs should not collide")
+	}
+
+	for _, bad := range []string{"tables: {users: {email: fake:nope}}", "tables: {users: {email: scramble}}", "tables: {users: drop}", "tables: {users: {bio: truncate}}"} {
+		if _, err := ParseAnonymizer([]byte(bad)); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
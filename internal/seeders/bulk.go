@@ -0,0 +1,381 @@
+package seeders
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"forge/internal/schema"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// With bulk: true a fixture skips GORM for its plain rows: postgres loads them
+// with COPY FROM STDIN, sqlite and mysql with multi-row prepared statements
+// reused for every batch. on_conflict still applies; on postgres the rows go
+// through a temporary table and INSERT ... SELECT ... ON CONFLICT.
+
+const (
+	// bulkProgressEvery is how often a bulk load reports its progress.
+	bulkProgressEvery = 2 * time.Second
+	// Bound parameters per statement.
+	sqliteMaxVars = 32766
+	mysqlMaxVars  = 65535
+)
+
+// rowWriter inserts the plain rows of a fixture.
+type rowWriter interface {
+	write(rows []map[string]any) error
+	// done releases the writer; ok reports whether the load succeeded.
+	done(ok bool) error
+}
+
+// beginFixture starts the transaction of a fixture seed. A postgres bulk load
+// pins a connection, returned as conn, so that COPY runs inside the transaction.
+func beginFixture(db *gorm.DB, s YAMLSeed) (tx *gorm.DB, conn *sql.Conn, err error) {
+	if !s.Bulk || db.Dialector.Name() != "postgres" {
+		tx = db.Begin()
+		return tx, nil, tx.Error
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, err
+	}
+	if conn, err = sqlDB.Conn(context.Background()); err != nil {
+		return nil, nil, err
+	}
+	session := db.Session(&gorm.Session{NewDB: true})
+	session.Statement.ConnPool = conn
+	if tx = session.Begin(); tx.Error != nil {
+		conn.Close()
+		return nil, nil, tx.Error
+	}
+	return tx, conn, nil
+}
+
+// newRowWriter picks the writer for the seed: GORM's Create by default, the
+// driver's fast path with bulk: true.
+func newRowWriter(tx *gorm.DB, conn *sql.Conn, s YAMLSeed, chunk int) (rowWriter, error) {
+	if !s.Bulk {
+		return &gormWriter{tx: tx, s: s, chunk: chunk}, nil
+	}
+	p := &bulkProgress{table: s.Table, start: time.Now()}
+	p.last = p.start
+	switch tx.Dialector.Name() {
+	case "postgres":
+		if conn == nil {
+			return nil, errors.New("bulk: no pinned connection")
+		}
+		return &copyWriter{tx: tx, conn: conn, s: s, progress: p}, nil
+	case "sqlite":
+		w := &stmtWriter{tx: tx, s: s, maxVars: sqliteMaxVars, stmts: map[string]*sql.Stmt{}, progress: p}
+		// One transaction already avoids a sync per row; a larger page cache
+		// keeps index updates in memory.
+		if err := tx.Raw("PRAGMA cache_size").Row().Scan(&w.cacheSize); err != nil {
+			return nil, err
+		}
+		if err := tx.Exec("PRAGMA cache_size = -262144").Error; err != nil {
+			return nil, err
+		}
+		return w, nil
+	case "mysql":
+		return &stmtWriter{tx: tx, s: s, maxVars: mysqlMaxVars, stmts: map[string]*sql.Stmt{}, progress: p}, nil
+	}
+	return nil, fmt.Errorf("bulk loading is not supported on %s", tx.Dialector.Name())
+}
+
+type gormWriter struct {
+	tx    *gorm.DB
+	s     YAMLSeed
+	chunk int
+}
+
+func (w *gormWriter) write(rows []map[string]any) error {
+	_, err := insertFixtureRows(w.tx, w.s, rows, w.chunk)
+	return err
+}
+
+func (w *gormWriter) done(bool) error { return nil }
+
+// bulkProgress prints the rows/s of a bulk load.
+type bulkProgress struct {
+	table       string
+	rows        int64
+	start, last time.Time
+}
+
+func (p *bulkProgress) add(n int) {
+	p.rows += int64(n)
+	if now := time.Now(); now.Sub(p.last) >= bulkProgressEvery {
+		p.last = now
+		fmt.Printf("  %s: %d rows (%.0f rows/s)\n", p.table, p.rows, p.rate())
+	}
+}
+
+func (p *bulkProgress) rate() float64 {
+	if d := time.Since(p.start).Seconds(); d > 0 {
+		return float64(p.rows) / d
+	}
+	return 0
+}
+
+func (p *bulkProgress) finish() {
+	fmt.Printf("Loaded %d rows into %s in %s (%.0f rows/s)\n", p.rows, p.table, time.Since(p.start).Round(time.Millisecond), p.rate())
+}
+
+// rowColumns returns the sorted union of the columns of rows.
+func rowColumns(rows []map[string]any) []string {
+	seen := map[string]bool{}
+	var cols []string
+	for _, r := range rows {
+		for k := range r {
+			if !seen[k] {
+				seen[k] = true
+				cols = append(cols, k)
+			}
+		}
+	}
+	sort.Strings(cols)
+	return cols
+}
+
+// bulkValue unwraps the casts normalizeRows adds for postgres JSON columns.
+func bulkValue(v any) any {
+	if e, ok := v.(clause.Expr); ok && len(e.Vars) == 1 {
+		return e.Vars[0]
+	}
+	return v
+}
+
+// conflictSQL renders the on_conflict clause of the seed for cols.
+func conflictSQL(driver string, s YAMLSeed, cols []string) string {
+	q := schema.Quoter(driver)
+	var updates []string
+	for _, c := range cols {
+		if !strIn(s.ConflictKey, c) {
+			updates = append(updates, c)
+		}
+	}
+	mode := strings.ToLower(s.OnConflict)
+	if mode == "update_all" && len(updates) == 0 {
+		mode = "do_nothing"
+	}
+	switch {
+	case mode == "do_nothing" && driver == "mysql":
+		return fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s = %s", q(cols[0]), q(cols[0]))
+	case mode == "do_nothing":
+		return " ON CONFLICT DO NOTHING"
+	case mode == "update_all" && driver == "mysql":
+		sets := make([]string, len(updates))
+		for i, c := range updates {
+			sets[i] = fmt.Sprintf("%s = VALUES(%s)", q(c), q(c))
+		}
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	case mode == "update_all":
+		sets := make([]string, len(updates))
+		for i, c := range updates {
+			sets[i] = fmt.Sprintf("%s = excluded.%s", q(c), q(c))
+		}
+		return fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", quoteCols(q, s.ConflictKey), strings.Join(sets, ", "))
+	}
+	return ""
+}
+
+// stmtWriter inserts with multi-row prepared statements, one per column set
+// and batch size, reused across batches.
+type stmtWriter struct {
+	tx        *gorm.DB
+	s         YAMLSeed
+	maxVars   int
+	stmts     map[string]*sql.Stmt
+	progress  *bulkProgress
+	cacheSize *int64
+}
+
+func (w *stmtWriter) write(rows []map[string]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	cols := rowColumns(rows)
+	per := w.maxVars / len(cols)
+	if per < 1 {
+		return fmt.Errorf("bulk: %d columns exceed the parameter limit", len(cols))
+	}
+	args := make([]any, 0, per*len(cols))
+	for i := 0; i < len(rows); i += per {
+		end := min(i+per, len(rows))
+		stmt, err := w.prepare(cols, end-i)
+		if err != nil {
+			return err
+		}
+		args = args[:0]
+		for _, r := range rows[i:end] {
+			for _, c := range cols {
+				args = append(args, bulkValue(r[c]))
+			}
+		}
+		if _, err := stmt.Exec(args...); err != nil {
+			return err
+		}
+		w.progress.add(end - i)
+	}
+	return nil
+}
+
+func (w *stmtWriter) prepare(cols []string, n int) (*sql.Stmt, error) {
+	key := strings.Join(cols, ",") + "#" + strconv.Itoa(n)
+	if st, ok := w.stmts[key]; ok {
+		return st, nil
+	}
+	driver := w.tx.Dialector.Name()
+	q := schema.Quoter(driver)
+	one := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", ") + ")"
+	var b strings.Builder
+	fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES ", q(w.s.Table), quoteCols(q, cols))
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(one)
+	}
+	b.WriteString(conflictSQL(driver, w.s, cols))
+	st, err := w.tx.Statement.ConnPool.PrepareContext(context.Background(), b.String())
+	if err != nil {
+		return nil, err
+	}
+	w.stmts[key] = st
+	return st, nil
+}
+
+func (w *stmtWriter) done(ok bool) error {
+	for _, st := range w.stmts {
+		st.Close()
+	}
+	// pragmas outlive the transaction
+	if w.cacheSize != nil {
+		if err := w.tx.Exec(fmt.Sprintf("PRAGMA cache_size = %d", *w.cacheSize)).Error; err != nil {
+			return err
+		}
+	}
+	if ok {
+		w.progress.finish()
+	}
+	return nil
+}
+
+// copyWriter loads rows on postgres with COPY in CSV format, which leaves
+// parsing every value to the server as for a literal.
+type copyWriter struct {
+	tx       *gorm.DB
+	conn     *sql.Conn
+	s        YAMLSeed
+	progress *bulkProgress
+	staged   bool
+}
+
+// copyStage is the temporary table used when on_conflict is set.
+const copyStage = "forge_bulk_stage"
+
+func (w *copyWriter) write(rows []map[string]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	q := schema.Quoter("postgres")
+	cols := rowColumns(rows)
+	target := w.s.Table
+	if w.s.OnConflict != "" {
+		// COPY has no ON CONFLICT: stage the rows and insert from there.
+		target = copyStage
+		if err := w.tx.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", q(copyStage))).Error; err != nil {
+			return err
+		}
+		if err := w.tx.Exec(fmt.Sprintf("CREATE TEMP TABLE %s AS SELECT %s FROM %s WITH NO DATA",
+			q(copyStage), quoteCols(q, cols), q(w.s.Table))).Error; err != nil {
+			return err
+		}
+		w.staged = true
+	}
+
+	var buf bytes.Buffer
+	for _, r := range rows {
+		for i, c := range cols {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCopyValue(&buf, bulkValue(r[c]))
+		}
+		buf.WriteByte('\n')
+	}
+	copySQL := fmt.Sprintf("COPY %s (%s) FROM STDIN WITH (FORMAT csv)", q(target), quoteCols(q, cols))
+	err := w.conn.Raw(func(dc any) error {
+		c, ok := dc.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("bulk: unexpected postgres driver %T", dc)
+		}
+		_, err := c.Conn().PgConn().CopyFrom(context.Background(), &buf, copySQL)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if target == copyStage {
+		err := w.tx.Exec(fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s%s",
+			q(w.s.Table), quoteCols(q, cols), quoteCols(q, cols), q(copyStage), conflictSQL("postgres", w.s, cols))).Error
+		if err != nil {
+			return err
+		}
+	}
+	w.progress.add(len(rows))
+	return nil
+}
+
+func (w *copyWriter) done(ok bool) error {
+	if !ok {
+		// the rollback drops the staging table
+		return nil
+	}
+	if w.staged {
+		if err := w.tx.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", schema.Quoter("postgres")(copyStage))).Error; err != nil {
+			return err
+		}
+	}
+	w.progress.finish()
+	return nil
+}
+
+// writeCopyValue writes v as a CSV field of COPY: an unquoted empty field is
+// NULL, anything else is quoted.
+func writeCopyValue(b *bytes.Buffer, v any) {
+	var s string
+	switch x := v.(type) {
+	case nil:
+		return
+	case string:
+		s = x
+	case []byte:
+		s = `\x` + hex.EncodeToString(x)
+	case bool:
+		s = strconv.FormatBool(x)
+	case time.Time:
+		s = x.Format(time.RFC3339Nano)
+	case map[string]any, []any:
+		raw, _ := json.Marshal(x)
+		s = string(raw)
+	default:
+		s = fmt.Sprint(x)
+	}
+	b.WriteByte('"')
+	b.WriteString(strings.ReplaceAll(s, `"`, `""`))
+	b.WriteByte('"')
+}
@@ -0,0 +1,57 @@
+package seeders
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBulkFixture(t *testing.T) {
+	db := openSeedDB(t, `CREATE TABLE items (id INTEGER PRIMARY KEY, code TEXT NOT NULL UNIQUE, name TEXT, price REAL)`)
+
+	// more rows than fit into one statement, so the batches are split
+	load := YAMLSeed{Name: "load", Type: "fixture", Table: "items", Bulk: true, Count: 25000, ChunkSize: 25000,
+		Template: map[string]any{"code": "c{{ .Number }}", "name": "Item {{ .Number }}", "price": 1.5}}
+	if err := runFixture(db, load, 1); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	var n int64
+	db.Table("items").Count(&n)
+	if n != 25000 {
+		t.Fatalf("rows = %d", n)
+	}
+
+	rows := func(names ...string) []map[string]any {
+		var out []map[string]any
+		for i, name := range names {
+			out = append(out, map[string]any{"code": fmt.Sprintf("c%d", i+1), "name": name})
+		}
+		return out
+	}
+	skip := YAMLSeed{Name: "skip", Type: "fixture", Table: "items", Bulk: true, OnConflict: "do_nothing", Rows: rows("A", "B")}
+	update := YAMLSeed{Name: "update", Type: "fixture", Table: "items", Bulk: true, OnConflict: "update_all",
+		ConflictKey: []string{"code"}, ChunkSize: 1, Rows: append(rows("One"), map[string]any{"code": "new", "name": "New"})}
+	for _, s := range []YAMLSeed{skip, update} {
+		if err := runFixture(db, s, 2); err != nil {
+			t.Fatalf("%s: %v", s.Name, err)
+		}
+	}
+	var names []string
+	db.Table("items").Where("code IN ?", []string{"c1", "c2", "new"}).Order("id").Pluck("name", &names)
+	if fmt.Sprint(names) != "[One Item 2 New]" {
+		t.Errorf("names = %v", names)
+	}
+
+	// a failing batch leaves nothing behind
+	dup := YAMLSeed{Name: "dup", Type: "fixture", Table: "items", Bulk: true, Rows: rows("x")}
+	if err := runFixture(db, dup, 3); err == nil {
+		t.Fatal("duplicate code inserted")
+	}
+
+	if err := RollbackAll(db); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	db.Table("items").Count(&n)
+	if n != 0 {
+		t.Fatalf("%d rows left after rollback", n)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"forge/internal/config"
 	"forge/internal/database"
+	"forge/internal/guard"
 	"github.com/spf13/cobra"
 	"os"
 	"strings"
@@ -28,22 +30,9 @@ Supported seed types:
   - sql
   - go
 
-Fixture scaffolds support count/template generation and fake tokens.
-
-Fake tokens:
-  - fake:first_name
-  - fake:last_name
-  - fake:full_name
-  - fake:email
-  - fake:company
-  - fake:phone
-  - fake:sentence
-  - fake:uuid
-  - fake:bool
-  - fake:int:min:max
-
-  fake:datetime
-  fake:date
+Fixture scaffolds support count/template generation and fake tokens such as
+fake:full_name, fake:email, fake:int:18:65 or fake:pick:draft|published; run
+forge seed fake:list for the full catalogue and the available locales.
 
 Example:
   forge seed make users
@@ -101,31 +90,141 @@ Example:
 	makeCmd.Flags().StringVar(&fromTable, "from-table", "", "Generate a fixture from an existing table's schema")
 	makeCmd.Flags().IntVar(&count, "count", 10, "Number of rows to generate (with --from-table)")
 
+	fakeListCmd := &cobra.Command{
+		Use:   "fake:list",
+		Short: "List the fake: tokens available in fixtures",
+		Args:  cobra.NoArgs,
+		Run: func(*cobra.Command, []string) {
+			fmt.Print(RenderFakeProviders())
+		},
+	}
+
+	var env string
+	var tags []string
+	var randomSeed int64
+	var fakeNow string
+	var noLint bool
+	var dryRun bool
+	var previewFormat string
+	var previewLimit int
+	apply := func(opts ApplyOptions) error {
+		if dryRun {
+			// InitDB would create the migrations table; a dry run writes nothing.
+			settings, err := config.CurrentSettings()
+			if err != nil {
+				return err
+			}
+			db, err := database.ConnectSettings(settings)
+			if err != nil {
+				return err
+			}
+			return Preview(db, os.Stdout, opts, previewFormat, previewLimit)
+		}
+		db, err := database.InitDB()
+		if err != nil {
+			return err
+		}
+		return Apply(db, opts)
+	}
 	upCmd := &cobra.Command{
-		Use: "up", Short: "Run all pending seeders",
-		RunE: func(*cobra.Command, []string) error {
-			db, err := database.InitDB()
+		Use:   "up",
+		Short: "Run all pending seeders",
+		Long: `Run every pending seed, dependencies first.
+
+Seeds can be limited to environments and tagged:
+
+  name: demo_posts
+  type: fixture
+  table: posts
+  env: [dev, test]        # skipped unless --env / FORGE_ENV is dev or test
+  tags: [demo]            # selected by --tag demo
+  depends_on: [users]     # runs after the seed named users
+
+Seeds with env: do not run when no environment is known. depends_on may name
+seeds in other files; pending dependencies run even when --tag leaves them out.
+
+Fake values are random unless a random seed is given, with --seed or a
+random_seed: in the file; then every fake token, UUIDs and dates included,
+produces the same data on every run. Dates then count from 2024-01-01 instead
+of today; --fake-now or fake_now: in the file moves that date.
+
+--dry-run shows what would happen without writing anything: the pending seeds
+per batch, fixture rows with fake values, ref: lookups and password hashes
+resolved, and the SQL of sql seeds. Values that refer to rows another pending
+seed would insert stay unresolved.`,
+		Example: `  forge seed up
+  forge seed up --env dev
+  forge seed up --env test --tag demo
+  forge seed up --seed 42
+  forge seed up --dry-run
+  forge seed up --dry-run --format sql --limit 0 > seeds.sql`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			opts, err := applyOptions(cmd, env, tags, randomSeed, fakeNow, noLint)
 			if err != nil {
 				return err
 			}
-			return ApplyAll(db)
+			return apply(opts)
 		},
 	}
+	upCmd.Flags().StringVar(&env, "env", "", "environment to select env: seeds for (default "+config.ForgeEnvKey+")")
+	upCmd.Flags().StringSliceVar(&tags, "tag", nil, "run only seeds with one of these tags")
+	upCmd.Flags().BoolVar(&noLint, "no-lint", false, "skip the schema check that runs before inserting")
 
 	runCmd := &cobra.Command{
 		Use: "run", Short: "Run specific seeders",
-		RunE: func(*cobra.Command, []string) error {
+		RunE: func(cmd *cobra.Command, _ []string) error {
 			if only == "" {
 				return fmt.Errorf("--only=plans,tenants_accounts_users")
 			}
-			db, err := database.InitDB()
+			opts, err := applyOptions(cmd, env, nil, randomSeed, fakeNow, noLint)
 			if err != nil {
 				return err
 			}
-			return ApplyOnly(db, strings.Split(only, ","))
+			opts.Only = strings.Split(only, ",")
+			return apply(opts)
 		},
 	}
 	runCmd.Flags().StringVar(&only, "only", "", "Comma-separated seeder names to run")
+	runCmd.Flags().StringVar(&env, "env", "", "environment to select env: seeds for (default "+config.ForgeEnvKey+")")
+	runCmd.Flags().BoolVar(&noLint, "no-lint", false, "skip the schema check that runs before inserting")
+	for _, c := range []*cobra.Command{upCmd, runCmd} {
+		c.Flags().Int64Var(&randomSeed, "seed", 0, "random seed for reproducible fake data, dated from --fake-now (overrides random_seed:)")
+		c.Flags().StringVar(&fakeNow, "fake-now", "", "date reproducible fake dates count from (default 2024-01-01, overrides fake_now:)")
+		c.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be inserted without writing anything")
+		c.Flags().StringVar(&previewFormat, "format", PreviewTable, "dry-run output: table, json or sql")
+		c.Flags().IntVar(&previewLimit, "limit", 20, "dry-run rows shown per fixture (0 = all)")
+	}
+
+	lintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check seeds against the database schema without inserting",
+		Long: `Load every seed, expand its templates and check it against the live schema:
+unknown tables and columns, NOT NULL columns without a default that rows leave
+out, literals the column type cannot take, ref: targets and conflict_key
+columns. ref: lookups also run against the data when no fixture writes to the
+referenced table.
+
+seed up and seed run do the same check, without the lookups, for the seeds
+they are about to run.`,
+		RunE: func(*cobra.Command, []string) error {
+			db, err := database.InitDB()
+			if err != nil {
+				return err
+			}
+			issues, err := Lint(db)
+			if err != nil {
+				return err
+			}
+			for _, is := range issues {
+				fmt.Println(is)
+			}
+			if len(issues) > 0 {
+				return fmt.Errorf("%d problem(s) found", len(issues))
+			}
+			fmt.Println("Seeds look good.")
+			return nil
+		},
+	}
 
 	statusCmd := &cobra.Command{
 		Use: "status", Short: "Show executed seeders",
@@ -138,9 +237,19 @@ Example:
 		},
 	}
 
+	var resetForce bool
+	var resetConfirmDB string
 	resetCmd := &cobra.Command{
-		Use: "reset", Short: "Clear seed status (does not delete data)",
+		Use: "reset", Short: "Clear seed status (does not delete data, see seed rollback)",
 		RunE: func(*cobra.Command, []string) error {
+			settings, err := config.CurrentSettings()
+			if err != nil {
+				return err
+			}
+			ok, err := guard.Confirm(settings, "This will clear the status of ALL seeds.", guard.Options{Force: resetForce, ConfirmDB: resetConfirmDB})
+			if err != nil || !ok {
+				return err
+			}
 			db, err := database.InitDB()
 			if err != nil {
 				return err
@@ -149,6 +258,181 @@ Example:
 		},
 	}
 
-	seedCmd.AddCommand(makeCmd, upCmd, runCmd, statusCmd, resetCmd)
+	resetCmd.Flags().BoolVar(&resetForce, "force", false, "skip confirmation prompt (does not bypass protected databases)")
+	resetCmd.Flags().StringVar(&resetConfirmDB, "confirm-db", "", "database name, required to run against a protected database non-interactively")
+
+	var rollbackStep int
+	var rollbackForce bool
+	var rollbackConfirmDB string
+	rollbackCmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Delete the rows of the last seed batch (use --step N for more)",
+		Long: `Undo the last seed batches, newest first.
+
+Fixture seeds delete the rows they inserted, found by primary key; rows that
+already existed and were only updated by on_conflict are left alone. sql and
+go seeds run their down: statement:
+
+  name: roles
+  type: sql
+  sql: INSERT INTO roles (name) VALUES ('admin'), ('editor');
+  down: DELETE FROM roles WHERE name IN ('admin', 'editor');
+
+A batch containing a seed that cannot be undone is not touched.`,
+		Example: `  forge seed rollback
+  forge seed rollback --step 3`,
+		RunE: func(*cobra.Command, []string) error {
+			settings, err := config.CurrentSettings()
+			if err != nil {
+				return err
+			}
+			ok, err := guard.Confirm(settings, "This will DELETE the seeded rows of the last seed batches.", guard.Options{Force: rollbackForce, ConfirmDB: rollbackConfirmDB})
+			if err != nil || !ok {
+				return err
+			}
+			db, err := database.InitDB()
+			if err != nil {
+				return err
+			}
+			return Rollback(db, rollbackStep)
+		},
+	}
+	rollbackCmd.Flags().IntVar(&rollbackStep, "step", 1, "number of batches to roll back")
+	rollbackCmd.Flags().BoolVar(&rollbackForce, "force", false, "skip confirmation prompt (does not bypass protected databases)")
+	rollbackCmd.Flags().StringVar(&rollbackConfirmDB, "confirm-db", "", "database name, required to run against a protected database non-interactively")
+
+	var refreshForce bool
+	var refreshConfirmDB string
+	refreshCmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "Roll back ALL seeds and run them again",
+		RunE: func(*cobra.Command, []string) error {
+			settings, err := config.CurrentSettings()
+			if err != nil {
+				return err
+			}
+			ok, err := guard.Confirm(settings, "This will DELETE the rows of ALL seeds and re-run them.", guard.Options{Force: refreshForce, ConfirmDB: refreshConfirmDB})
+			if err != nil || !ok {
+				return err
+			}
+			db, err := database.InitDB()
+			if err != nil {
+				return err
+			}
+			if err := RollbackAll(db); err != nil {
+				return err
+			}
+			return Apply(db, ApplyOptions{Env: settings.Env})
+		},
+	}
+	refreshCmd.Flags().BoolVar(&refreshForce, "force", false, "skip confirmation prompt (does not bypass protected databases)")
+	refreshCmd.Flags().StringVar(&refreshConfirmDB, "confirm-db", "", "database name, required to run against a protected database non-interactively")
+
+	var exportOpts ExportOptions
+	var exportOut, exportAnonymize string
+	exportCmd := &cobra.Command{
+		Use:   "export <table>...",
+		Short: "Export table rows into a fixture seed",
+		Long: `Dump real rows into a type: fixture seed that seed up can replay.
+
+Several tables are written to one file, as a seeds: list with parents before
+the tables that reference them. With --refs, foreign-key values become ref:
+shortcuts that find the parent by a natural key: the column given with
+--ref-key, or else the parent's first single-column unique index.
+
+--anonymize rewrites personal data using per table/column rules from a YAML
+file (default database/anonymize.yaml):
+
+  salt: change-me            # or FORGE_ANONYMIZE_SALT
+  tables:
+    users:
+      email: fake:email      # any fake: token
+      password: hash         # salted sha256 (hash:N keeps N hex chars)
+      phone: null
+      bio: truncate:40       # keep the first 40 characters
+      city: shuffle          # permute values across rows
+      id: keep
+    sessions: truncate       # export no rows
+
+The same input always maps to the same output, so values shared between
+tables (and ref: natural keys) stay consistent.`,
+		Example: `  forge seed export users
+  forge seed export users posts --where "users:active = 1" --limit 100
+  forge seed export posts --refs --ref-key users=email --skip-ids
+  forge seed export users --out -
+  forge seed export users posts --refs --anonymize`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if exportAnonymize != "" {
+				anon, err := LoadAnonymizer(exportAnonymize)
+				if err != nil {
+					return err
+				}
+				exportOpts.Anonymize = anon
+			}
+			db, err := database.InitDB()
+			if err != nil {
+				return err
+			}
+			content, err := ExportFixtures(db, args, exportOpts)
+			if err != nil {
+				return err
+			}
+			switch exportOut {
+			case "":
+				name := exportOpts.Name
+				if name == "" {
+					name = "export_" + strings.Join(args, "_")
+				}
+				path, err := writeSeed(name, content)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Created %s\n", path)
+			case "-":
+				fmt.Print(content)
+			default:
+				if err := os.WriteFile(exportOut, []byte(content), 0o644); err != nil {
+					return err
+				}
+				fmt.Printf("Created %s\n", exportOut)
+			}
+			return nil
+		},
+	}
+	exportCmd.Flags().StringArrayVar(&exportOpts.Where, "where", nil, "SQL condition; prefix with \"table:\" to apply it to one table")
+	exportCmd.Flags().IntVar(&exportOpts.Limit, "limit", 0, "maximum rows per table (0 = all)")
+	exportCmd.Flags().BoolVar(&exportOpts.Refs, "refs", false, "turn foreign keys into ref: shortcuts resolved by natural keys")
+	exportCmd.Flags().StringToStringVar(&exportOpts.RefKeys, "ref-key", nil, "natural key column per parent table, e.g. users=email")
+	exportCmd.Flags().BoolVar(&exportOpts.SkipIDs, "skip-ids", false, "leave out auto-increment ids")
+	exportCmd.Flags().StringVar(&exportOpts.Name, "name", "", "seed name (default: export_<tables>)")
+	exportCmd.Flags().StringVarP(&exportOut, "out", "o", "", "output file, or - for stdout (default: a new file in database/seeds)")
+	exportCmd.Flags().StringVar(&exportAnonymize, "anonymize", "", "anonymize rows with the rules in this file (default "+DefaultAnonymizeFile+")")
+	exportCmd.Flags().Lookup("anonymize").NoOptDefVal = DefaultAnonymizeFile
+
+	seedCmd.AddCommand(makeCmd, fakeListCmd, upCmd, runCmd, lintCmd, statusCmd, rollbackCmd, refreshCmd, resetCmd, exportCmd)
 	rootCmd.AddCommand(seedCmd)
 }
+
+// applyOptions builds the options of seed up / seed run; --env defaults to
+// FORGE_ENV.
+func applyOptions(cmd *cobra.Command, env string, tags []string, randomSeed int64, fakeNow string, noLint bool) (ApplyOptions, error) {
+	if !cmd.Flags().Changed("env") {
+		settings, err := config.CurrentSettings()
+		if err != nil {
+			return ApplyOptions{}, err
+		}
+		env = settings.Env
+	}
+	opts := ApplyOptions{Env: strings.TrimSpace(env), Tags: tags, SkipLint: noLint}
+	if cmd.Flags().Changed("seed") {
+		opts.RandomSeed = &randomSeed
+	}
+	if fakeNow != "" {
+		if _, err := parseFakeNow(fakeNow); err != nil {
+			return ApplyOptions{}, err
+		}
+		opts.FakeNow = fakeNow
+	}
+	return opts, nil
+}
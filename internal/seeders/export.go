@@ -0,0 +1,348 @@
+package seeders
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"forge/internal/schema"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// ExportOptions configure ExportFixtures.
+type ExportOptions struct {
+	// Where filters rows with an SQL condition. A "table:" prefix limits a
+	// condition to one table; without it the condition applies to every table.
+	Where []string
+	// Limit caps the number of rows per table (0 = all).
+	Limit int
+	// Refs turns foreign-key values into ref: shortcuts that look the parent
+	// up by a natural key instead of its id.
+	Refs bool
+	// RefKeys names the natural key column per parent table (table -> column).
+	// Tables without an entry use their first single-column unique index.
+	RefKeys map[string]string
+	// SkipIDs leaves out single integer primary keys so the database assigns
+	// fresh ids on replay.
+	SkipIDs bool
+	// Name is the seed name (default: export_<tables>).
+	Name string
+	// Anonymize, when set, rewrites personal data on the way out.
+	Anonymize *Anonymizer
+}
+
+// ExportFixtures dumps real rows of the given tables as fixture seed YAML that
+// `seed up` can replay. Several tables become a `seeds:` list ordered so that
+// parents come before the tables referencing them.
+func ExportFixtures(db *gorm.DB, tables []string, opts ExportOptions) (string, error) {
+	if len(tables) == 0 {
+		return "", fmt.Errorf("no tables to export")
+	}
+	m, err := schema.Introspect(db)
+	if err != nil {
+		return "", err
+	}
+	var selected []schema.Table
+	for _, name := range tables {
+		t := m.Table(name)
+		if t == nil {
+			return "", fmt.Errorf("table %q not found in current database", name)
+		}
+		selected = append(selected, *t)
+	}
+	selected = schema.DependencyOrder(selected)
+
+	name := opts.Name
+	if name == "" {
+		name = "export_" + strings.Join(tables, "_")
+	}
+
+	x := &exporter{db: db, model: m, opts: opts, keyCache: map[string]map[string]string{}}
+	var seeds []*yaml.Node
+	for _, t := range selected {
+		if opts.Anonymize.SkipTable(t.Name) {
+			continue
+		}
+		rows, err := x.tableRows(t)
+		if err != nil {
+			return "", fmt.Errorf("export %s: %w", t.Name, err)
+		}
+		seedName := name
+		if len(selected) > 1 {
+			seedName = name + "_" + t.Name
+		}
+		seeds = append(seeds, fixtureNode(seedName, t.Name, rows))
+	}
+
+	var doc *yaml.Node
+	if len(seeds) == 0 {
+		return "", fmt.Errorf("every table is excluded by the anonymization rules")
+	}
+	if len(seeds) == 1 {
+		doc = seeds[0]
+	} else {
+		doc = mapNode("seeds", &yaml.Node{Kind: yaml.SequenceNode, Content: seeds})
+	}
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	header := fmt.Sprintf("# Exported from %s on %s.\n", strings.Join(tables, ", "), time.Now().Format("2006-01-02 15:04"))
+	return header + string(out), nil
+}
+
+type exporter struct {
+	db    *gorm.DB
+	model *schema.Model
+	opts  ExportOptions
+	// keyCache maps "table.column" -> referenced value -> natural key value.
+	keyCache map[string]map[string]string
+}
+
+// exportRow keeps the column order of the table.
+type exportRow struct {
+	cols []string
+	vals []any
+}
+
+func (x *exporter) tableRows(t schema.Table) ([]exportRow, error) {
+	driver := x.db.Dialector.Name()
+	q := schema.Quoter(driver)
+
+	var cols []string
+	for _, c := range t.Columns {
+		if x.opts.SkipIDs && isAutoID(t, c) {
+			continue
+		}
+		cols = append(cols, c.Name)
+	}
+	quoted := make([]string, len(cols))
+	for i, c := range cols {
+		quoted[i] = q(c)
+	}
+
+	from := "FROM " + q(t.Name)
+	var conds []string
+	for _, w := range x.opts.Where {
+		if table, cond, ok := strings.Cut(w, ":"); ok && x.model.Table(strings.TrimSpace(table)) != nil {
+			if strings.TrimSpace(table) == t.Name {
+				conds = append(conds, "("+cond+")")
+			}
+			continue
+		}
+		conds = append(conds, "("+w+")")
+	}
+	if len(conds) > 0 {
+		from += " WHERE " + strings.Join(conds, " AND ")
+	}
+	if len(t.PrimaryKey) > 0 {
+		pk := make([]string, len(t.PrimaryKey))
+		for i, c := range t.PrimaryKey {
+			pk[i] = q(c)
+		}
+		from += " ORDER BY " + strings.Join(pk, ", ")
+	}
+	if x.opts.Limit > 0 {
+		from += fmt.Sprintf(" LIMIT %d", x.opts.Limit)
+	}
+
+	if err := x.opts.Anonymize.Prepare(x.db, t.Name, from); err != nil {
+		return nil, err
+	}
+	rows, err := x.db.Raw(fmt.Sprintf("SELECT %s %s", strings.Join(quoted, ", "), from)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refs := map[string]schema.ForeignKey{}
+	if x.opts.Refs {
+		for _, fk := range t.ForeignKeys {
+			if len(fk.Columns) == 1 {
+				refs[fk.Columns[0]] = fk
+			}
+		}
+	}
+
+	var out []exportRow
+	for rows.Next() {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		if err := x.opts.Anonymize.Apply(t.Name, cols, vals, int64(len(out))); err != nil {
+			return nil, err
+		}
+		for i, v := range vals {
+			vals[i] = exportValue(v)
+		}
+		out = append(out, exportRow{cols: cols, vals: vals})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	// Foreign keys -> ref: shortcuts, once the result set is closed.
+	for _, r := range out {
+		for i, c := range r.cols {
+			fk, ok := refs[c]
+			if !ok || r.vals[i] == nil {
+				continue
+			}
+			if ref, ok, err := x.refFor(fk, r.vals[i]); err != nil {
+				return nil, err
+			} else if ok {
+				r.vals[i] = ref
+			}
+		}
+	}
+	return out, nil
+}
+
+// refFor renders ref:<parent>|<key>=<value>|<column> for a foreign-key value,
+// or reports false when the parent has no usable natural key.
+func (x *exporter) refFor(fk schema.ForeignKey, v any) (string, bool, error) {
+	refCol := "id"
+	if len(fk.RefColumns) == 1 {
+		refCol = fk.RefColumns[0]
+	}
+	key := x.naturalKey(fk.RefTable, refCol)
+	if key == "" {
+		return "", false, nil
+	}
+
+	cacheKey := fk.RefTable + "." + refCol
+	cache := x.keyCache[cacheKey]
+	if cache == nil {
+		cache = map[string]string{}
+		x.keyCache[cacheKey] = cache
+	}
+	id := fmt.Sprint(v)
+	natural, seen := cache[id]
+	if !seen {
+		var vals []any
+		if err := x.db.Table(fk.RefTable).Where(fmt.Sprintf("%s = ?", refCol), v).Limit(1).Pluck(key, &vals).Error; err != nil {
+			return "", false, err
+		}
+		if len(vals) == 1 && vals[0] != nil {
+			// The parent is exported anonymized too; refer to it the same way.
+			if v, ok, err := x.opts.Anonymize.ApplyValue(fk.RefTable, key, vals[0]); err != nil {
+				return "", false, err
+			} else if ok && v != nil {
+				natural = fmt.Sprint(exportValue(v))
+			}
+		}
+		cache[id] = natural
+	}
+	// parseRefShortcut splits on | and =, so such values cannot be expressed.
+	if natural == "" || strings.ContainsAny(natural, "|=") {
+		return "", false, nil
+	}
+	return fmt.Sprintf("ref:%s|%s=%s|%s", fk.RefTable, key, natural, refCol), true, nil
+}
+
+// naturalKey picks the column identifying rows of table other than refCol.
+func (x *exporter) naturalKey(table, refCol string) string {
+	if k := x.opts.RefKeys[table]; k != "" {
+		return k
+	}
+	t := x.model.Table(table)
+	if t == nil {
+		return ""
+	}
+	var candidates []string
+	for _, ix := range t.Indexes {
+		if ix.Unique && len(ix.Columns) == 1 && !ix.IsExpression(0) && ix.Where == "" && ix.Columns[0] != refCol {
+			candidates = append(candidates, ix.Columns[0])
+		}
+	}
+	sort.Strings(candidates)
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[0]
+}
+
+func isAutoID(t schema.Table, c schema.Column) bool {
+	return len(t.PrimaryKey) == 1 && t.PrimaryKey[0] == c.Name && isIntType(c.Type)
+}
+
+// exportValue turns scanned driver values into plain YAML scalars.
+func exportValue(v any) any {
+	switch x := v.(type) {
+	case []byte:
+		return string(x)
+	case time.Time:
+		if x.Hour() == 0 && x.Minute() == 0 && x.Second() == 0 && x.Nanosecond() == 0 {
+			return x.Format("2006-01-02")
+		}
+		return x.Format("2006-01-02 15:04:05")
+	}
+	return v
+}
+
+func fixtureNode(name, table string, rows []exportRow) *yaml.Node {
+	seq := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, r := range rows {
+		row := &yaml.Node{Kind: yaml.MappingNode}
+		for i, c := range r.cols {
+			row.Content = append(row.Content, scalar(c), valueNode(r.vals[i]))
+		}
+		seq.Content = append(seq.Content, row)
+	}
+	n := mapNode("name", scalar(name), "type", scalar("fixture"), "table", scalar(table))
+	if len(rows) == 0 {
+		seq.Style = yaml.FlowStyle
+	}
+	n.Content = append(n.Content, scalar("rows"), seq)
+	return n
+}
+
+// mapNode builds a mapping node from alternating keys and value nodes.
+func mapNode(kv ...any) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(kv); i += 2 {
+		n.Content = append(n.Content, scalar(kv[i].(string)), kv[i+1].(*yaml.Node))
+	}
+	return n
+}
+
+func scalar(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+func valueNode(v any) *yaml.Node {
+	switch x := v.(type) {
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(x)}
+	case int64:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.FormatInt(x, 10)}
+	case float64:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: strconv.FormatFloat(x, 'g', -1, 64)}
+	default:
+		return scalar(literalFixtureString(fmt.Sprint(x)))
+	}
+}
+
+// literalFixtureString protects exported text that seed up would otherwise
+// interpret as a fake: token, an expression or an @alias reference.
+func literalFixtureString(s string) string {
+	if strings.HasPrefix(s, "@@") || aliasRefRe.MatchString(s) {
+		s = "@" + s
+	}
+	if strings.HasPrefix(strings.TrimSpace(s), "fake:") || strings.Contains(s, "{{") {
+		return "{{ " + strconv.Quote(s) + " }}"
+	}
+	return s
+}
@@ -0,0 +1,87 @@
+package seeders
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func openExportDB(t *testing.T, name string) *gorm.DB {
+	t.Helper()
+	return openTestDB(t, "file:"+name+"?mode=memory&cache=shared",
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL, active BOOLEAN)`,
+		`CREATE UNIQUE INDEX ux_users_email ON users (email)`,
+		`CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users (id), title TEXT)`,
+	)
+}
+
+func TestExportFixturesRoundTrip(t *testing.T) {
+	src := openExportDB(t, t.Name()+"_src")
+	if err := src.Exec(`INSERT INTO users (id, email, active) VALUES (5, 'a@x.io', 1), (9, 'b@x.io', 0);
+		INSERT INTO posts (id, user_id, title) VALUES (1, 9, 'hello'), (2, 5, 'it''s'), (3, NULL, 'orphan')`).Error; err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	out, err := ExportFixtures(src, []string{"posts", "users"}, ExportOptions{
+		Refs:    true,
+		SkipIDs: true,
+		Where:   []string{"posts:title <> 'orphan'"},
+	})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	for _, want := range []string{"seeds:", "table: users", "user_id: ref:users|email=b@x.io|id", "title: it's"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("export missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "orphan") || strings.Index(out, "table: users") > strings.Index(out, "table: posts") {
+		t.Fatalf("unexpected export:\n%s", out)
+	}
+
+	// Replay into an empty database: users get new ids, posts follow them.
+	path := filepath.Join(t.TempDir(), "export.yaml")
+	if err := os.WriteFile(path, []byte(out), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("load exported seed: %v", err)
+	}
+	dst := openExportDB(t, t.Name()+"_dst")
+	if err := ensureTable(dst); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range cfg.Seeds {
+		if err := runSeed(dst, filepath.Dir(path), s, 1); err != nil {
+			t.Fatalf("replay %s: %v", s.Name, err)
+		}
+	}
+	var email string
+	dst.Raw(`SELECT u.email FROM posts p JOIN users u ON u.id = p.user_id WHERE p.title = 'hello'`).Scan(&email)
+	if email != "b@x.io" {
+		t.Fatalf("replayed post belongs to %q, want b@x.io", email)
+	}
+}
+
+func TestExportShuffleKeepsToFilteredRows(t *testing.T) {
+	db := openExportDB(t, t.Name())
+	if err := db.Exec(`INSERT INTO users (id, email, active) VALUES (5, 'a@x.io', 1), (9, 'b@x.io', 0)`).Error; err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	a, err := ParseAnonymizer([]byte("tables: {users: {email: shuffle}}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only one row passes the filter, so the shuffle pool is that row alone.
+	out, err := ExportFixtures(db, []string{"users"}, ExportOptions{Where: []string{"users:id = 5"}, Anonymize: a})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if !strings.Contains(out, "a@x.io") || strings.Contains(out, "b@x.io") {
+		t.Fatalf("shuffle drew from rows outside --where:\n%s", out)
+	}
+}
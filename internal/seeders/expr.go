@@ -0,0 +1,366 @@
+package seeders
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// Fixture values containing {{ ... }} are text/template expressions,
+// evaluated per row after the fake: tokens:
+//
+//	email: "user{{ .Number }}@example.com"
+//	slug:  "{{ .Row.name | slug }}"
+//	due:   "{{ now | addDays 14 | date }}"
+//
+// A value that is a single action keeps the type of its result, so
+// "{{ .Index }}" is an integer rather than a string.
+
+// exprData is the dot of a fixture expression.
+type exprData struct {
+	Index  int            // 0-based row index
+	Number int            // 1-based row number
+	Row    map[string]any // the row's other fields
+	Parent map[string]any // the parent row, in children
+}
+
+// isExpr reports whether a fixture value is a template expression.
+func isExpr(v any) bool {
+	s, ok := v.(string)
+	return ok && strings.Contains(s, "{{")
+}
+
+// exprFuncs are the functions available in fixture expressions.
+func (f *faker) exprFuncs() template.FuncMap {
+	now := func() time.Time {
+		if f.now.IsZero() {
+			return time.Now()
+		}
+		return f.now
+	}
+	return template.FuncMap{
+		// fake "email", fake "int" 1 10: any fake: token as a function.
+		"fake": func(name string, args ...any) (any, error) {
+			token := "fake:" + name
+			for _, a := range args {
+				token += ":" + fmt.Sprint(a)
+			}
+			return f.value(token)
+		},
+		"env": func(name string, def ...string) string {
+			if v, ok := os.LookupEnv(name); ok {
+				return v
+			}
+			if len(def) > 0 {
+				return def[0]
+			}
+			return ""
+		},
+		"now":     now,
+		"addDays": func(n int, t time.Time) time.Time { return t.AddDate(0, 0, n) },
+		"addMonths": func(n int, t time.Time) time.Time {
+			return t.AddDate(0, n, 0)
+		},
+		"addDuration": func(d string, t time.Time) (time.Time, error) {
+			dur, err := time.ParseDuration(d)
+			return t.Add(dur), err
+		},
+		"format":   func(layout string, t time.Time) string { return t.Format(layout) },
+		"date":     func(t time.Time) string { return t.Format("2006-01-02") },
+		"datetime": func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+		"lower":    strings.ToLower,
+		"upper":    strings.ToUpper,
+		"trim":     strings.TrimSpace,
+		"replace":  func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"slug":     f.slugify,
+		"add":      func(a, b int) int { return a + b },
+		"sub":      func(a, b int) int { return a - b },
+		"mul":      func(a, b int) int { return a * b },
+		"mod": func(a, b int) (int, error) {
+			if b == 0 {
+				return 0, fmt.Errorf("mod by zero")
+			}
+			return a % b, nil
+		},
+	}
+}
+
+// slugify turns arbitrary text into a lower-case ASCII slug: "Anna Müller" ->
+// "anna-mueller".
+func (f *faker) slugify(v any) string {
+	var words []string
+	for _, w := range strings.FieldsFunc(fmt.Sprint(v), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '.' || r == '/'
+	}) {
+		if w = f.slug(w); w != "" {
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, "-")
+}
+
+// compiledExpr is a parsed fixture expression.
+type compiledExpr struct {
+	tmpl *template.Template
+	// single is set when the value is one action whose result is kept as is.
+	single  bool
+	capture *any
+	// deps are the row fields the expression reads.
+	deps []string
+}
+
+func (f *faker) compileExpr(src string) (*compiledExpr, error) {
+	if c, ok := f.exprs[src]; ok {
+		return c, nil
+	}
+	t, err := template.New("expr").Option("missingkey=error").Funcs(f.exprFuncs()).Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	c := &compiledExpr{tmpl: t, deps: exprDeps(t.Tree.Root)}
+
+	// A lone action is re-parsed to capture its value instead of its text.
+	if nodes := t.Tree.Root.Nodes; len(nodes) == 1 {
+		if a, ok := nodes[0].(*parse.ActionNode); ok && len(a.Pipe.Decl) == 0 {
+			var captured any
+			funcs := f.exprFuncs()
+			funcs["capture"] = func(v any) string { captured = v; return "" }
+			single, err := template.New("expr").Option("missingkey=error").Funcs(funcs).Parse("{{ capture (" + a.Pipe.String() + ") }}")
+			if err == nil {
+				c.tmpl, c.single = single, true
+				c.capture = &captured
+			}
+		}
+	}
+	f.exprs[src] = c
+	return c, nil
+}
+
+func (c *compiledExpr) eval(data exprData) (any, error) {
+	var b strings.Builder
+	if err := c.tmpl.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	if c.single {
+		v := *c.capture
+		*c.capture = nil
+		return v, nil
+	}
+	return b.String(), nil
+}
+
+// exprDeps lists the fields an expression reads via .Row.x or index .Row "x".
+func exprDeps(root parse.Node) []string {
+	seen := map[string]bool{}
+	var walk func(n parse.Node)
+	walk = func(n parse.Node) {
+		switch x := n.(type) {
+		case *parse.ListNode:
+			if x == nil {
+				return
+			}
+			for _, c := range x.Nodes {
+				walk(c)
+			}
+		case *parse.ActionNode:
+			walk(x.Pipe)
+		case *parse.PipeNode:
+			if x == nil {
+				return
+			}
+			for _, c := range x.Cmds {
+				walk(c)
+			}
+		case *parse.CommandNode:
+			if len(x.Args) == 3 {
+				if id, ok := x.Args[0].(*parse.IdentifierNode); ok && id.Ident == "index" {
+					if fn, ok := x.Args[1].(*parse.FieldNode); ok && len(fn.Ident) == 1 && fn.Ident[0] == "Row" {
+						if s, ok := x.Args[2].(*parse.StringNode); ok {
+							seen[s.Text] = true
+						}
+					}
+				}
+			}
+			for _, a := range x.Args {
+				walk(a)
+			}
+		case *parse.FieldNode:
+			if len(x.Ident) >= 2 && x.Ident[0] == "Row" {
+				seen[x.Ident[1]] = true
+			}
+		case *parse.IfNode:
+			walk(x.Pipe)
+			walk(x.List)
+			walk(x.ElseList)
+		case *parse.RangeNode:
+			walk(x.Pipe)
+			walk(x.List)
+			walk(x.ElseList)
+		case *parse.WithNode:
+			walk(x.Pipe)
+			walk(x.List)
+			walk(x.ElseList)
+		}
+	}
+	walk(root)
+	deps := make([]string, 0, len(seen))
+	for d := range seen {
+		deps = append(deps, d)
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+// resolveExprs evaluates the expressions of a row. Fields are evaluated after
+// the fields they read, so one expression can build on another; nested
+// values see the finished top-level fields.
+func (f *faker) resolveExprs(row map[string]any, index int) error {
+	return f.resolveExprsWith(row, exprData{Index: index, Number: index + 1})
+}
+
+// resolveExprsWith is resolveExprs with the dot given; its Row is set to row.
+func (f *faker) resolveExprsWith(row map[string]any, data exprData) error {
+	compiled := map[string]*compiledExpr{}
+	var nested []string
+	for k, v := range row {
+		switch {
+		case isExpr(v):
+			c, err := f.compileExpr(v.(string))
+			if err != nil {
+				return fmt.Errorf("field %s: %w", k, err)
+			}
+			compiled[k] = c
+		case hasNestedExpr(v):
+			nested = append(nested, k)
+		}
+	}
+	if len(compiled) == 0 && len(nested) == 0 {
+		return nil
+	}
+	data.Row = row
+
+	order, err := exprOrder(compiled)
+	if err != nil {
+		return err
+	}
+	for _, k := range order {
+		v, err := compiled[k].eval(data)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", k, err)
+		}
+		row[k] = v
+	}
+
+	sort.Strings(nested)
+	for _, k := range nested {
+		v, err := f.resolveNestedExprs(row[k], data)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", k, err)
+		}
+		row[k] = v
+	}
+	return nil
+}
+
+// exprOrder sorts expression fields so that each comes after the expression
+// fields it reads.
+func exprOrder(compiled map[string]*compiledExpr) ([]string, error) {
+	keys := make([]string, 0, len(compiled))
+	for k := range compiled {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	state := map[string]int{} // 1 visiting, 2 done
+	var order, stack []string
+	var visit func(k string) error
+	visit = func(k string) error {
+		switch state[k] {
+		case 1:
+			return fmt.Errorf("expressions depend on each other: %s -> %s", strings.Join(stack, " -> "), k)
+		case 2:
+			return nil
+		}
+		state[k] = 1
+		stack = append(stack, k)
+		for _, d := range compiled[k].deps {
+			if _, ok := compiled[d]; ok {
+				if err := visit(d); err != nil {
+					return err
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[k] = 2
+		order = append(order, k)
+		return nil
+	}
+	for _, k := range keys {
+		if err := visit(k); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+func hasNestedExpr(v any) bool {
+	switch x := v.(type) {
+	case map[string]any:
+		for _, c := range x {
+			if isExpr(c) || hasNestedExpr(c) {
+				return true
+			}
+		}
+	case []any:
+		for _, c := range x {
+			if isExpr(c) || hasNestedExpr(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f *faker) resolveNestedExprs(v any, data exprData) (any, error) {
+	switch x := v.(type) {
+	case string:
+		if !isExpr(x) {
+			return x, nil
+		}
+		c, err := f.compileExpr(x)
+		if err != nil {
+			return nil, err
+		}
+		return c.eval(data)
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			r, err := f.resolveNestedExprs(x[k], data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			out[k] = r
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(x))
+		for i := range x {
+			r, err := f.resolveNestedExprs(x[i], data)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	}
+	return v, nil
+}
@@ -0,0 +1,65 @@
+package seeders
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFixtureExpressions(t *testing.T) {
+	t.Setenv("FORGE_TEST_DOMAIN", "corp.test")
+	seed := int64(3)
+
+	rows, err := expandFixtureRows(YAMLSeed{
+		Name: "users", Table: "users", Count: 3, RandomSeed: &seed,
+		Template: map[string]any{
+			"name":     "fake:full_name",
+			"slug":     "{{ .Row.name | slug }}",
+			"email":    "{{ .Row.slug }}{{ .Number }}@{{ env \"FORGE_TEST_DOMAIN\" }}",
+			"backup":   "{{ index .Row \"email\" | upper }}",
+			"position": "{{ mul .Index 10 }}",
+			"region":   "{{ env \"FORGE_TEST_MISSING\" \"eu\" }}",
+			"due":      "{{ now | addDays 14 | date }}",
+			"score":    "{{ fake \"int\" 5 5 }}",
+			"meta":     map[string]any{"owner": "{{ .Row.email }}"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expandFixtureRows: %v", err)
+	}
+	for i, r := range rows {
+		slug := r["slug"].(string)
+		if slug != strings.ReplaceAll(strings.ToLower(r["name"].(string)), " ", "-") {
+			t.Fatalf("slug %q does not follow name %q", slug, r["name"])
+		}
+		wantEmail := slug + string(rune('1'+i)) + "@corp.test"
+		if r["email"] != wantEmail || r["backup"] != strings.ToUpper(wantEmail) {
+			t.Fatalf("email = %v / %v, want %s", r["email"], r["backup"], wantEmail)
+		}
+		if r["position"] != i*10 || r["score"] != 5 {
+			t.Fatalf("single actions should keep their type: %#v %#v", r["position"], r["score"])
+		}
+		if r["region"] != "eu" || r["due"] != "2024-01-15" {
+			t.Fatalf("region/due = %v / %v", r["region"], r["due"])
+		}
+		if owner := r["meta"].(map[string]any)["owner"]; owner != wantEmail {
+			t.Fatalf("nested expression = %v", owner)
+		}
+	}
+
+	// Exported text that looks like a token or an expression replays verbatim.
+	literal := "{{ not a template }} fake:email"
+	rows, err = expandFixtureRows(YAMLSeed{Name: "lit", Rows: []map[string]any{{"a": literalFixtureString(literal), "b": literalFixtureString("fake:uuid")}}})
+	if err != nil || rows[0]["a"] != literal || rows[0]["b"] != "fake:uuid" {
+		t.Fatalf("literal = %#v, %v", rows, err)
+	}
+
+	for name, tmpl := range map[string]map[string]any{
+		"cycle":   {"a": "{{ .Row.b }}", "b": "{{ .Row.a }}"},
+		"missing": {"a": "{{ .Row.nope }}"},
+		"syntax":  {"a": "{{ .Row. }}"},
+	} {
+		if _, err := expandFixtureRows(YAMLSeed{Name: name, Count: 1, Template: tmpl}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package seeders
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"forge/internal/schema"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// A fixture's children generate related rows for every row it inserts, and
+// may have children of their own:
+//
+//	table: users
+//	count: 10
+//	template: { email: "fake:email" }
+//	children:
+//	  - table: posts
+//	    count: 1-5
+//	    template: { title: "fake:sentence" }
+//	    children:
+//	      - table: comments
+//	        count: 0-3
+//	        template: { body: "Re: {{ .Parent.title }}" }
+//
+// The child's foreign key to its parent comes from the schema; foreign_key
+// names the column when the schema declares none or several.
+
+// CountRange is a number of child rows: 3, or a random count in "1-5".
+type CountRange struct {
+	Min, Max int
+}
+
+func (c *CountRange) UnmarshalYAML(n *yaml.Node) error {
+	var s string
+	if err := n.Decode(&s); err != nil {
+		return err
+	}
+	lo, hi, isRange := strings.Cut(strings.TrimSpace(s), "-")
+	if !isRange {
+		hi = lo
+	}
+	var err error
+	if c.Min, err = strconv.Atoi(strings.TrimSpace(lo)); err == nil {
+		c.Max, err = strconv.Atoi(strings.TrimSpace(hi))
+	}
+	if err != nil || c.Min < 0 || c.Max < c.Min {
+		return fmt.Errorf("invalid count %q (want a number or a range like 1-5)", s)
+	}
+	return nil
+}
+
+func (c *CountRange) pick(f *faker) int {
+	if c == nil {
+		return 1
+	}
+	if c.Max <= c.Min {
+		return c.Min
+	}
+	return c.Min + f.rand.Intn(c.Max-c.Min+1)
+}
+
+// childFactory inserts the children of a fixture's rows.
+type childFactory struct {
+	tx      *gorm.DB
+	seed    YAMLSeed
+	model   *schema.Model
+	aliases *aliasSet
+	inserts *insertLog
+	chunk   int
+	fields  []string
+	cost    int
+}
+
+// insert generates and inserts children for each of parents, rows of the
+// table parent. path names the position in the tree for the random streams.
+func (c *childFactory) insert(parent string, parents []*insertedRow, children []YAMLChild, path string) error {
+	for i, ch := range children {
+		if err := c.insertChild(parent, parents, ch, fmt.Sprintf("%s/%d:%s", path, i, ch.Table)); err != nil {
+			return fmt.Errorf("children of %s: %s: %w", parent, ch.Table, err)
+		}
+	}
+	return nil
+}
+
+func (c *childFactory) insertChild(parent string, parents []*insertedRow, ch YAMLChild, path string) error {
+	if ch.Table == "" {
+		return fmt.Errorf("table is required")
+	}
+	if _, ok := ch.Template[aliasField]; ok {
+		return fmt.Errorf("%s is not supported in children", aliasField)
+	}
+	fk, ref, err := childForeignKey(c.model, ch, parent)
+	if err != nil {
+		return err
+	}
+	f, err := YAMLSeed{Name: c.seed.Name + "/" + path, RandomSeed: c.seed.RandomSeed, FakeNow: c.seed.FakeNow, Locale: c.seed.Locale}.faker()
+	if err != nil {
+		return err
+	}
+
+	var rows []map[string]any
+	for _, p := range parents {
+		refCols := ref
+		if len(refCols) == 0 {
+			refCols = p.cols
+		}
+		if len(refCols) != len(fk) {
+			return fmt.Errorf("foreign key %s does not match the primary key of %s", strings.Join(fk, ", "), parent)
+		}
+		n := ch.Count.pick(f)
+		for k := 0; k < n; k++ {
+			row := cloneRow(ch.Template)
+			if err := f.resolveValues(row); err != nil {
+				return err
+			}
+			for j, col := range fk {
+				if row[col], err = p.column(c.tx, refCols[j]); err != nil {
+					return err
+				}
+			}
+			if err := f.resolveExprsWith(row, exprData{Index: k, Number: k + 1, Parent: p.values}); err != nil {
+				return err
+			}
+			if err := c.aliases.resolveRow(row); err != nil {
+				return err
+			}
+			if err := resolveRowRefs(c.tx, row); err != nil {
+				return fmt.Errorf("resolve $ref failed: %w", err)
+			}
+			if err := hashPasswordFieldsIfPresent(row, c.fields, c.cost); err != nil {
+				return fmt.Errorf("hash password failed: %w", err)
+			}
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := normalizeRows(c.tx, ch.Table, rows); err != nil {
+		return fmt.Errorf("normalize rows failed: %w", err)
+	}
+	if err := c.inserts.expect(c.tx, ch.Table, rows); err != nil {
+		return fmt.Errorf("track inserted rows failed: %w", err)
+	}
+
+	target := YAMLSeed{Table: ch.Table}
+	if len(ch.Children) == 0 {
+		_, err := insertFixtureRows(c.tx, target, rows, c.chunk)
+		return err
+	}
+	// Rows with children of their own go one by one to read back their keys.
+	next := make([]*insertedRow, len(rows))
+	for i, r := range rows {
+		affected, err := insertFixtureRows(c.tx, target, rows[i:i+1], 1)
+		if err != nil {
+			return err
+		}
+		if next[i], err = readInserted(c.tx, target, r, affected); err != nil {
+			return err
+		}
+	}
+	return c.insert(ch.Table, next, ch.Children, path)
+}
+
+// childForeignKey returns the child's columns referencing parent and the
+// parent columns they reference (nil for the primary key).
+func childForeignKey(model *schema.Model, ch YAMLChild, parent string) (cols, ref []string, err error) {
+	t := model.Table(ch.Table)
+	if t == nil {
+		return nil, nil, fmt.Errorf("table %s not found", ch.Table)
+	}
+	var candidates []schema.ForeignKey
+	for _, fk := range t.ForeignKeys {
+		if strings.EqualFold(fk.RefTable, parent) {
+			candidates = append(candidates, fk)
+		}
+	}
+	refCols := func(fk schema.ForeignKey) []string {
+		for _, c := range fk.RefColumns {
+			if c == "" {
+				return nil // sqlite: REFERENCES parent without columns
+			}
+		}
+		return fk.RefColumns
+	}
+
+	if ch.ForeignKey != "" {
+		for _, fk := range candidates {
+			if len(fk.Columns) == 1 && strings.EqualFold(fk.Columns[0], ch.ForeignKey) {
+				return fk.Columns, refCols(fk), nil
+			}
+		}
+		return []string{ch.ForeignKey}, nil, nil
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, nil, fmt.Errorf("%s has no foreign key to %s; set foreign_key", ch.Table, parent)
+	case 1:
+		return candidates[0].Columns, refCols(candidates[0]), nil
+	}
+	names := make([]string, len(candidates))
+	for i, fk := range candidates {
+		names[i] = strings.Join(fk.Columns, "+")
+	}
+	return nil, nil, fmt.Errorf("%s has several foreign keys to %s (%s); set foreign_key", ch.Table, parent, strings.Join(names, ", "))
+}
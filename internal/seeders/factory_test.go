@@ -0,0 +1,94 @@
+package seeders
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFixtureChildren(t *testing.T) {
+	db := openSeedDB(t,
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT)`,
+		`CREATE TABLE posts (id INTEGER PRIMARY KEY, author_id INTEGER REFERENCES users(id), title TEXT)`,
+		`CREATE TABLE comments (id INTEGER PRIMARY KEY, post_id INTEGER REFERENCES posts, body TEXT)`,
+		`CREATE TABLE reviews (id INTEGER PRIMARY KEY, author_id INTEGER REFERENCES users(id), editor_id INTEGER REFERENCES users(id))`,
+	)
+
+	var seed YAMLSeed
+	if err := yaml.Unmarshal([]byte(`
+name: users
+type: fixture
+table: users
+count: 3
+random_seed: 1
+template: { email: "fake:email" }
+children:
+  - table: posts
+    count: 1-2
+    template: { title: "Post {{ .Number }} by {{ .Parent.email }}" }
+    children:
+      - table: comments
+        count: 2
+        template: { body: "Re: {{ .Parent.title }}" }
+`), &seed); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if c := seed.Children[0].Count; c == nil || c.Min != 1 || c.Max != 2 {
+		t.Fatalf("count = %+v", c)
+	}
+	if err := runFixture(db, seed, 1); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var posts []struct {
+		ID       int
+		AuthorID int
+		Title    string
+		Email    string
+	}
+	db.Raw(`SELECT p.id, p.author_id, p.title, u.email FROM posts p JOIN users u ON u.id = p.author_id ORDER BY p.id`).Scan(&posts)
+	perUser := map[int]int{}
+	for _, p := range posts {
+		perUser[p.AuthorID]++
+		if !strings.HasSuffix(p.Title, " by "+p.Email) {
+			t.Errorf("post title %q does not name %s", p.Title, p.Email)
+		}
+	}
+	if len(perUser) != 3 {
+		t.Fatalf("posts per user = %v", perUser)
+	}
+	for u, n := range perUser {
+		if n < 1 || n > 2 {
+			t.Errorf("user %d has %d posts", u, n)
+		}
+	}
+	var orphans, comments int64
+	db.Table("comments").Count(&comments)
+	db.Raw(`SELECT COUNT(*) FROM comments c JOIN posts p ON p.id = c.post_id WHERE c.body <> 'Re: ' || p.title`).Scan(&orphans)
+	if comments != int64(2*len(posts)) || orphans != 0 {
+		t.Fatalf("comments = %d for %d posts, %d mismatched", comments, len(posts), orphans)
+	}
+
+	// Two foreign keys to users: the column must be named.
+	reviews := YAMLSeed{Name: "reviews", Type: "fixture", Table: "users", Rows: []map[string]any{{"email": "r@example.com"}},
+		Children: []YAMLChild{{Table: "reviews"}}}
+	if err := runFixture(db, reviews, 2); err == nil || !strings.Contains(err.Error(), "several foreign keys") {
+		t.Fatalf("ambiguous foreign key: %v", err)
+	}
+	reviews.Children[0].ForeignKey = "editor_id"
+	if err := runFixture(db, reviews, 2); err != nil {
+		t.Fatalf("reviews: %v", err)
+	}
+
+	if err := RollbackAll(db); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	for _, table := range []string{"users", "posts", "comments", "reviews"} {
+		var n int64
+		db.Table(table).Count(&n)
+		if n != 0 {
+			t.Errorf("%s has %d rows after rollback", table, n)
+		}
+	}
+}
@@ -1,34 +1,96 @@
 package seeders
 
 import (
-	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"hash/fnv"
 	mathrand "math/rand"
-	"strconv"
+	"sort"
 	"strings"
 	"time"
 )
 
-var fakeRand = mathrand.New(mathrand.NewSource(time.Now().UnixNano()))
+// faker generates fake values from its own random source, so a caller can make
+// the output reproducible by seeding it.
+type faker struct {
+	rand *mathrand.Rand
+	// now anchors generated dates; zero means the current time.
+	now    time.Time
+	locale *fakeLocale
+	// seq holds the next index of each fake:seq token.
+	seq map[string]int
+	// exprs caches parsed fixture expressions.
+	exprs map[string]*compiledExpr
+}
+
+func newFaker(seed int64) *faker {
+	return &faker{
+		rand:   mathrand.New(mathrand.NewSource(seed)),
+		locale: fakeLocales[defaultFakeLocale],
+		seq:    map[string]int{},
+		exprs:  map[string]*compiledExpr{},
+	}
+}
 
-var fakeFirstNames = []string{"Liam", "Olivia", "Noah", "Emma", "Mason", "Sophia", "Ethan", "Mia"}
-var fakeLastNames = []string{"Smith", "Johnson", "Brown", "Taylor", "Anderson", "Martin", "Walker", "Hall"}
-var fakeCompanies = []string{"Bookly", "Northwind", "Acme", "Nimbus", "Atlas", "Bluepeak"}
-var fakeDomains = []string{"example.com", "mail.test", "bookly.app", "forge.dev"}
-var fakeSentences = []string{
-	"Sample text generated by Forge.",
-	"Another seed row ready for local development.",
-	"Generated fixture content for testing flows.",
+// fakeEpoch replaces the current time for reproducible fakers unless the seed
+// sets fake_now:, so generated dates do not change from one day to the next.
+var fakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// faker returns the generator for the seed's fake values, in the seed's
+// locale. With a random seed the values are reproducible: each seed gets its
+// own stream derived from the random seed and its name, so adding a seed does
+// not change the others, and "now" is fake_now: or fakeEpoch.
+func (s YAMLSeed) faker() (*faker, error) {
+	locale, err := fakeLocaleFor(s.Locale)
+	if err != nil {
+		return nil, err
+	}
+	now, err := parseFakeNow(s.FakeNow)
+	if err != nil {
+		return nil, err
+	}
+	if s.RandomSeed == nil {
+		f := newFaker(time.Now().UnixNano())
+		f.locale = locale
+		return f, nil
+	}
+	h := fnv.New64a()
+	h.Write([]byte(s.Name))
+	f := newFaker(*s.RandomSeed ^ int64(h.Sum64()))
+	f.now = now
+	f.locale = locale
+	return f, nil
+}
+
+// parseFakeNow reads fake_now: as a date or an RFC 3339 time; empty means
+// fakeEpoch.
+func parseFakeNow(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return fakeEpoch, nil
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid fake_now %q (use YYYY-MM-DD or RFC 3339)", s)
 }
 
 func expandFixtureRows(s YAMLSeed) ([]map[string]any, error) {
+	f, err := s.faker()
+	if err != nil {
+		return nil, err
+	}
 	if len(s.Rows) > 0 {
 		rows := cloneRows(s.Rows)
 		for i := range rows {
-			if err := resolveFakeValues(rows[i]); err != nil {
+			if err := f.resolveValues(rows[i]); err != nil {
 				return nil, err
 			}
+			if err := f.resolveExprs(rows[i], i); err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
 		}
 		return rows, nil
 	}
@@ -40,17 +102,27 @@ func expandFixtureRows(s YAMLSeed) ([]map[string]any, error) {
 	rows := make([]map[string]any, 0, s.Count)
 	for i := 0; i < s.Count; i++ {
 		row := cloneRow(s.Template)
-		if err := resolveFakeValues(row); err != nil {
+		if err := f.resolveValues(row); err != nil {
 			return nil, err
 		}
+		if err := f.resolveExprs(row, i); err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
 		rows = append(rows, row)
 	}
 	return rows, nil
 }
 
-func resolveFakeValues(row map[string]any) error {
-	for key, value := range row {
-		resolved, err := resolveFakeValue(value)
+// resolveValues replaces fake tokens in row. Keys are visited in sorted order
+// so a seeded faker hands out its values the same way on every run.
+func (f *faker) resolveValues(row map[string]any) error {
+	keys := make([]string, 0, len(row))
+	for key := range row {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		resolved, err := f.resolveValue(row[key])
 		if err != nil {
 			return fmt.Errorf("field %s: %w", key, err)
 		}
@@ -59,20 +131,20 @@ func resolveFakeValues(row map[string]any) error {
 	return nil
 }
 
-func resolveFakeValue(value any) (any, error) {
+func (f *faker) resolveValue(value any) (any, error) {
 	switch v := value.(type) {
 	case string:
-		return fakeValue(v)
+		return f.value(v)
 	case map[string]any:
 		cloned := cloneRow(v)
-		if err := resolveFakeValues(cloned); err != nil {
+		if err := f.resolveValues(cloned); err != nil {
 			return nil, err
 		}
 		return cloned, nil
 	case []any:
 		out := make([]any, len(v))
 		for i := range v {
-			item, err := resolveFakeValue(v[i])
+			item, err := f.resolveValue(v[i])
 			if err != nil {
 				return nil, err
 			}
@@ -84,77 +156,35 @@ func resolveFakeValue(value any) (any, error) {
 	}
 }
 
-func fakeValue(input string) (any, error) {
+func (f *faker) value(input string) (any, error) {
 	trimmed := strings.TrimSpace(input)
 	if !strings.HasPrefix(trimmed, "fake:") {
 		return input, nil
 	}
 
 	token := strings.TrimPrefix(trimmed, "fake:")
-	switch {
-	case token == "first_name":
-		return pick(fakeFirstNames), nil
-	case token == "last_name":
-		return pick(fakeLastNames), nil
-	case token == "full_name":
-		return fmt.Sprintf("%s %s", pick(fakeFirstNames), pick(fakeLastNames)), nil
-	case token == "email":
-		return fmt.Sprintf("%s.%s%d@%s",
-			strings.ToLower(pick(fakeFirstNames)),
-			strings.ToLower(pick(fakeLastNames)),
-			fakeRand.Intn(1000),
-			pick(fakeDomains),
-		), nil
-	case token == "company":
-		return pick(fakeCompanies), nil
-	case token == "phone":
-		return fmt.Sprintf("+1-555-%03d-%04d", fakeRand.Intn(1000), fakeRand.Intn(10000)), nil
-	case token == "sentence":
-		return pick(fakeSentences), nil
-	case token == "uuid":
-		return fakeUUID(), nil
-	case token == "datetime":
-		return fakeDateTime().Format("2006-01-02 15:04:05"), nil
-	case token == "date":
-		return fakeDateTime().Format("2006-01-02"), nil
-	case token == "bool":
-		return fakeRand.Intn(2) == 0, nil
-	case strings.HasPrefix(token, "int:"):
-		return fakeInt(token)
-	default:
-		return nil, fmt.Errorf("unsupported fake token %q", trimmed)
+	name, rest, hasArgs := strings.Cut(token, ":")
+	p := fakeProviderIndex[name]
+	if p == nil {
+		return nil, fmt.Errorf("unsupported fake token %q (see forge seed fake:list)", trimmed)
 	}
-}
-
-func fakeInt(token string) (int, error) {
-	parts := strings.Split(token, ":")
-	if len(parts) != 3 {
-		return 0, fmt.Errorf("invalid fake int token %q", token)
-	}
-	min, err := strconv.Atoi(parts[1])
-	if err != nil {
-		return 0, fmt.Errorf("invalid fake int min %q", parts[1])
+	var args []string
+	switch {
+	case p.raw:
+		args = []string{rest}
+	case hasArgs:
+		args = strings.Split(rest, ":")
 	}
-	max, err := strconv.Atoi(parts[2])
+	v, err := p.gen(f, args)
 	if err != nil {
-		return 0, fmt.Errorf("invalid fake int max %q", parts[2])
+		return nil, fmt.Errorf("%s: %w", trimmed, err)
 	}
-	if max < min {
-		return 0, fmt.Errorf("invalid fake int range %d..%d", min, max)
-	}
-	return min + fakeRand.Intn(max-min+1), nil
-}
-
-// fakeDateTime returns a random instant within roughly the past year.
-func fakeDateTime() time.Time {
-	days := fakeRand.Intn(365)
-	secs := fakeRand.Intn(86400)
-	return time.Now().AddDate(0, 0, -days).Add(-time.Duration(secs) * time.Second)
+	return v, nil
 }
 
-func fakeUUID() string {
+func (f *faker) uuid() string {
 	var b [16]byte
-	_, _ = rand.Read(b[:])
+	_, _ = f.rand.Read(b[:])
 	b[6] = (b[6] & 0x0f) | 0x40
 	b[8] = (b[8] & 0x3f) | 0x80
 	buf := make([]byte, 36)
@@ -170,8 +200,8 @@ func fakeUUID() string {
 	return string(buf)
 }
 
-func pick(values []string) string {
-	return values[fakeRand.Intn(len(values))]
+func (f *faker) pick(values []string) string {
+	return values[f.rand.Intn(len(values))]
 }
 
 func cloneRows(rows []map[string]any) []map[string]any {
@@ -0,0 +1,171 @@
+package seeders
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// fakeLocale is the data behind the locale-aware fake providers.
+type fakeLocale struct {
+	name        string
+	maleFirst   []string
+	femaleFirst []string
+	lastNames   []string
+	// femaleLast derives the female form of a last name, where the language
+	// has one.
+	femaleLast func(string) string
+	companies  []string
+	domains    []string
+	cities     []string
+	countries  []string
+	streets    []string
+	// address renders a street and house number.
+	address func(street string, number int) string
+	// phone renders a phone number from random digits.
+	phone func(f *faker) string
+	// postcodeDigits is the length of a postal code.
+	postcodeDigits int
+	words          []string
+	// ascii transliterates names for emails, usernames and URLs.
+	ascii func(string) string
+}
+
+const defaultFakeLocale = "en"
+
+var fakeLocales = map[string]*fakeLocale{
+	"en": {
+		name:        "en",
+		maleFirst:   []string{"Liam", "Noah", "Mason", "Ethan", "James", "Oliver", "Lucas", "Henry", "Jack", "Daniel", "Samuel", "Owen"},
+		femaleFirst: []string{"Olivia", "Emma", "Sophia", "Mia", "Ava", "Isabella", "Charlotte", "Amelia", "Harper", "Ella", "Grace", "Chloe"},
+		lastNames: []string{"Smith", "Johnson", "Brown", "Taylor", "Anderson", "Martin", "Walker", "Hall", "Williams", "Jones",
+			"Miller", "Davis", "Wilson", "Moore", "Clark", "Lewis", "Young", "King", "Wright", "Scott"},
+		companies: []string{"Bookly", "Northwind", "Acme", "Nimbus", "Atlas", "Bluepeak", "Globex", "Initech", "Vandelay", "Brightline"},
+		domains:   []string{"example.com", "mail.test", "bookly.app", "forge.dev"},
+		cities: []string{"New York", "Los Angeles", "Chicago", "Houston", "Phoenix", "Seattle", "Denver", "Boston",
+			"Austin", "Portland", "Atlanta", "Miami", "San Diego", "Dallas", "Detroit"},
+		countries: []string{"United States", "Canada", "United Kingdom", "Germany", "France", "Spain", "Italy", "Japan",
+			"Brazil", "Australia", "India", "Mexico", "Netherlands", "Sweden", "Poland"},
+		streets: []string{"Main St", "Oak Ave", "Maple Dr", "Cedar Ln", "Pine St", "Elm St", "Washington Blvd", "Lake Rd",
+			"Hill St", "Park Ave", "Sunset Blvd", "River Rd"},
+		address: func(street string, n int) string { return fmt.Sprintf("%d %s", n, street) },
+		phone: func(f *faker) string {
+			return fmt.Sprintf("+1-555-%03d-%04d", f.rand.Intn(1000), f.rand.Intn(10000))
+		},
+		postcodeDigits: 5,
+		words: []string{"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+			"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim", "ad", "minim",
+			"veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip", "ex", "ea", "commodo",
+			"consequat", "duis", "aute", "irure", "in", "reprehenderit", "voluptate", "velit", "esse", "cillum",
+			"fugiat", "nulla", "pariatur", "excepteur", "sint", "occaecat", "cupidatat", "non", "proident", "sunt",
+			"culpa", "qui", "officia", "deserunt", "mollit", "anim", "id", "est", "laborum"},
+	},
+	"ru": {
+		name:        "ru",
+		maleFirst:   []string{"Александр", "Дмитрий", "Сергей", "Иван", "Андрей", "Михаил", "Алексей", "Николай", "Павел", "Максим", "Артём", "Егор"},
+		femaleFirst: []string{"Мария", "Анна", "Елена", "Ольга", "Наталья", "Татьяна", "Ирина", "Екатерина", "Светлана", "Юлия", "Дарья", "Софья"},
+		lastNames: []string{"Иванов", "Смирнов", "Кузнецов", "Попов", "Соколов", "Лебедев", "Козлов", "Новиков", "Морозов", "Петров",
+			"Волков", "Соловьёв", "Васильев", "Зайцев", "Павлов", "Семёнов", "Голубев", "Виноградов", "Богданов", "Воробьёв"},
+		// Every last name above ends in -ов/-ев/-ёв.
+		femaleLast: func(s string) string { return s + "а" },
+		companies: []string{"ООО «Ромашка»", "ООО «Вектор»", "АО «Горизонт»", "ООО «Альфа-Строй»", "ООО «Северный ветер»",
+			"АО «ТехноПарк»", "ООО «Меридиан»", "ООО «Кедр»"},
+		domains: []string{"example.ru", "mail.test", "forge.dev"},
+		cities: []string{"Москва", "Санкт-Петербург", "Новосибирск", "Екатеринбург", "Казань", "Нижний Новгород", "Самара",
+			"Омск", "Ростов-на-Дону", "Уфа", "Красноярск", "Пермь", "Воронеж", "Волгоград", "Калининград"},
+		countries: []string{"Россия", "Казахстан", "Беларусь", "Германия", "Франция", "Италия", "Испания", "Япония",
+			"Китай", "Канада", "Бразилия", "Индия", "Турция", "Польша", "Финляндия"},
+		streets: []string{"Ленина", "Мира", "Советская", "Гагарина", "Пушкина", "Садовая", "Лесная", "Центральная",
+			"Молодёжная", "Школьная", "Набережная", "Победы"},
+		address: func(street string, n int) string { return fmt.Sprintf("ул. %s, д. %d", street, n) },
+		phone: func(f *faker) string {
+			return fmt.Sprintf("+7 9%02d %03d-%02d-%02d", f.rand.Intn(100), f.rand.Intn(1000), f.rand.Intn(100), f.rand.Intn(100))
+		},
+		postcodeDigits: 6,
+		words: []string{"дом", "город", "время", "работа", "день", "жизнь", "человек", "дело", "мир", "вопрос", "место",
+			"система", "проект", "задача", "решение", "данные", "команда", "результат", "процесс", "план", "новый",
+			"большой", "важный", "простой", "быстрый", "хороший", "последний", "открытый", "делать", "знать", "строить",
+			"видеть", "найти", "помогать", "начинать", "писать", "читать", "проверять", "сегодня", "всегда", "снова", "вместе"},
+		ascii: transliterateRU,
+	},
+	"de": {
+		name:        "de",
+		maleFirst:   []string{"Lukas", "Leon", "Finn", "Paul", "Jonas", "Felix", "Maximilian", "Elias", "Noah", "Ben", "Tim", "Jan"},
+		femaleFirst: []string{"Mia", "Emma", "Hannah", "Sophia", "Lena", "Lea", "Marie", "Anna", "Laura", "Lina", "Clara", "Johanna"},
+		lastNames: []string{"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner", "Becker", "Schulz", "Hoffmann",
+			"Schäfer", "Koch", "Bauer", "Richter", "Klein", "Wolf", "Schröder", "Neumann", "Schwarz", "Zimmermann"},
+		companies: []string{"Müller GmbH", "Schneider & Söhne KG", "Nordlicht AG", "Bergmann Logistik GmbH", "Sonnenhof GmbH",
+			"Fischer Technik AG", "Alpenblick GmbH", "Rheinwerk KG"},
+		domains: []string{"example.de", "mail.test", "forge.dev"},
+		cities: []string{"Berlin", "Hamburg", "München", "Köln", "Frankfurt am Main", "Stuttgart", "Düsseldorf", "Leipzig",
+			"Dortmund", "Essen", "Bremen", "Dresden", "Hannover", "Nürnberg", "Freiburg"},
+		countries: []string{"Deutschland", "Österreich", "Schweiz", "Frankreich", "Italien", "Spanien", "Niederlande", "Belgien",
+			"Polen", "Dänemark", "Schweden", "Japan", "Kanada", "Brasilien", "Vereinigte Staaten"},
+		streets: []string{"Hauptstraße", "Bahnhofstraße", "Gartenstraße", "Schulstraße", "Dorfstraße", "Bergstraße",
+			"Lindenstraße", "Kirchstraße", "Waldstraße", "Ringstraße", "Schillerstraße", "Goethestraße"},
+		address: func(street string, n int) string { return fmt.Sprintf("%s %d", street, n) },
+		phone: func(f *faker) string {
+			return fmt.Sprintf("+49 15%d %07d", f.rand.Intn(10), f.rand.Intn(10000000))
+		},
+		postcodeDigits: 5,
+		words: []string{"Haus", "Stadt", "Zeit", "Arbeit", "Tag", "Leben", "Mensch", "Welt", "Frage", "Platz", "System",
+			"Projekt", "Aufgabe", "Lösung", "Daten", "Team", "Ergebnis", "Prozess", "Plan", "neu", "groß", "wichtig",
+			"einfach", "schnell", "gut", "letzte", "offen", "machen", "wissen", "bauen", "sehen", "finden", "helfen",
+			"beginnen", "schreiben", "lesen", "prüfen", "heute", "immer", "wieder", "zusammen"},
+		ascii: strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "Ä", "Ae", "Ö", "Oe", "Ü", "Ue", "ß", "ss").Replace,
+	},
+}
+
+// fakeLocaleFor returns the locale pack for name ("" is the default).
+func fakeLocaleFor(name string) (*fakeLocale, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = defaultFakeLocale
+	}
+	l, ok := fakeLocales[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown fake locale %q (available: %s)", name, strings.Join(fakeLocaleNames(), ", "))
+	}
+	return l, nil
+}
+
+func fakeLocaleNames() []string {
+	names := make([]string, 0, len(fakeLocales))
+	for n := range fakeLocales {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// toASCII transliterates s for identifiers such as emails and usernames.
+func (l *fakeLocale) toASCII(s string) string {
+	if l.ascii != nil {
+		s = l.ascii(s)
+	}
+	return s
+}
+
+var ruTranslit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+func transliterateRU(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		lower := []rune(strings.ToLower(string(r)))[0]
+		t, ok := ruTranslit[lower]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if lower != r && t != "" {
+			t = strings.ToUpper(t[:1]) + t[1:]
+		}
+		b.WriteString(t)
+	}
+	return b.String()
+}
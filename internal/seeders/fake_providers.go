@@ -0,0 +1,529 @@
+package seeders
+
+import (
+	"fmt"
+	"math"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// fakeProvider generates the values of one fake:<name> token.
+type fakeProvider struct {
+	name  string
+	args  string // argument syntax after the name, shown by seed fake:list
+	group string
+	doc   string
+	// raw passes everything after "fake:<name>:" as a single argument, for
+	// providers whose argument may contain colons.
+	raw bool
+	gen func(f *faker, args []string) (any, error)
+}
+
+// fakeProviders is the catalogue behind fake: tokens, in the order seed
+// fake:list documents it.
+var fakeProviders = []fakeProvider{
+	{name: "first_name", group: "People", doc: "first name", gen: func(f *faker, _ []string) (any, error) {
+		first, _ := f.person()
+		return first, nil
+	}},
+	{name: "last_name", group: "People", doc: "last name", gen: func(f *faker, _ []string) (any, error) {
+		_, last := f.person()
+		return last, nil
+	}},
+	{name: "full_name", group: "People", doc: "first and last name", gen: func(f *faker, _ []string) (any, error) {
+		first, last := f.person()
+		return first + " " + last, nil
+	}},
+	{name: "username", group: "People", doc: "login name, e.g. olivia.smith", gen: func(f *faker, _ []string) (any, error) {
+		return f.username(), nil
+	}},
+	{name: "email", group: "People", doc: "email address", gen: func(f *faker, _ []string) (any, error) {
+		first, last := f.person()
+		return fmt.Sprintf("%s.%s%d@%s", f.slug(first), f.slug(last), f.rand.Intn(1000), f.pick(f.locale.domains)), nil
+	}},
+	{name: "phone", group: "People", doc: "phone number in the locale's format", gen: func(f *faker, _ []string) (any, error) {
+		return f.locale.phone(f), nil
+	}},
+	{name: "company", group: "People", doc: "company name", gen: func(f *faker, _ []string) (any, error) {
+		return f.pick(f.locale.companies), nil
+	}},
+
+	{name: "address", group: "Address", doc: "street and house number", gen: func(f *faker, _ []string) (any, error) {
+		return f.locale.address(f.pick(f.locale.streets), 1+f.rand.Intn(199)), nil
+	}},
+	{name: "street", group: "Address", doc: "street name", gen: func(f *faker, _ []string) (any, error) {
+		return f.pick(f.locale.streets), nil
+	}},
+	{name: "city", group: "Address", doc: "city", gen: func(f *faker, _ []string) (any, error) {
+		return f.pick(f.locale.cities), nil
+	}},
+	{name: "country", group: "Address", doc: "country name", gen: func(f *faker, _ []string) (any, error) {
+		return f.pick(f.locale.countries), nil
+	}},
+	{name: "postcode", group: "Address", doc: "postal code", gen: func(f *faker, _ []string) (any, error) {
+		return f.digits(f.locale.postcodeDigits), nil
+	}},
+
+	{name: "word", group: "Text", doc: "single word", gen: func(f *faker, _ []string) (any, error) {
+		return f.pick(f.locale.words), nil
+	}},
+	{name: "words", args: "[:n]", group: "Text", doc: "n words (default 3)", gen: func(f *faker, args []string) (any, error) {
+		n, err := intArgs(args, 0, 1, 3)
+		if err != nil {
+			return nil, err
+		}
+		return strings.Join(f.words(n[0]), " "), nil
+	}},
+	{name: "sentence", args: "[:words]", group: "Text", doc: "sentence (default 6-12 words)", gen: func(f *faker, args []string) (any, error) {
+		n, err := intArgs(args, 0, 1, 0)
+		if err != nil {
+			return nil, err
+		}
+		return f.sentence(n[0]), nil
+	}},
+	{name: "paragraph", args: "[:sentences]", group: "Text", doc: "paragraph (default 3-6 sentences)", gen: func(f *faker, args []string) (any, error) {
+		n, err := intArgs(args, 0, 1, 0)
+		if err != nil {
+			return nil, err
+		}
+		count := n[0]
+		if count <= 0 {
+			count = 3 + f.rand.Intn(4)
+		}
+		out := make([]string, count)
+		for i := range out {
+			out[i] = f.sentence(0)
+		}
+		return strings.Join(out, " "), nil
+	}},
+
+	{name: "url", group: "Internet", doc: "https URL", gen: func(f *faker, _ []string) (any, error) {
+		return fmt.Sprintf("https://%s/%s", f.domain(), strings.Join(f.slugWords(1+f.rand.Intn(2)), "-")), nil
+	}},
+	{name: "domain", group: "Internet", doc: "domain name", gen: func(f *faker, _ []string) (any, error) {
+		return f.domain(), nil
+	}},
+	{name: "ipv4", group: "Internet", doc: "IPv4 address", gen: func(f *faker, _ []string) (any, error) {
+		return fmt.Sprintf("%d.%d.%d.%d", 1+f.rand.Intn(223), f.rand.Intn(256), f.rand.Intn(256), 1+f.rand.Intn(254)), nil
+	}},
+	{name: "ipv6", group: "Internet", doc: "IPv6 address", gen: func(f *faker, _ []string) (any, error) {
+		groups := make([]string, 8)
+		for i := range groups {
+			groups[i] = strconv.FormatInt(int64(f.rand.Intn(0x10000)), 16)
+		}
+		return strings.Join(groups, ":"), nil
+	}},
+	{name: "uuid", group: "Internet", doc: "random (v4) UUID", gen: func(f *faker, _ []string) (any, error) {
+		return f.uuid(), nil
+	}},
+
+	{name: "int", args: ":min:max", group: "Numbers", doc: "integer in [min, max]", gen: func(f *faker, args []string) (any, error) {
+		return f.int(args)
+	}},
+	{name: "float", args: ":min:max[:decimals]", group: "Numbers", doc: "float in [min, max], rounded (default 2 decimals)", gen: func(f *faker, args []string) (any, error) {
+		v, decimals, err := f.float(args)
+		if err != nil {
+			return nil, err
+		}
+		p := math.Pow10(decimals)
+		return math.Round(v*p) / p, nil
+	}},
+	{name: "decimal", args: ":min:max[:scale]", group: "Numbers", doc: "decimal string with a fixed scale (default 2), for DECIMAL columns", gen: func(f *faker, args []string) (any, error) {
+		v, scale, err := f.float(args)
+		if err != nil {
+			return nil, err
+		}
+		return strconv.FormatFloat(v, 'f', scale, 64), nil
+	}},
+	{name: "bool", group: "Numbers", doc: "true or false", gen: func(f *faker, _ []string) (any, error) {
+		return f.rand.Intn(2) == 0, nil
+	}},
+	{name: "seq", args: "[:start[:step]]", group: "Numbers", doc: "sequence start, start+step, ... across the rows of a seed (default 1:1)", gen: func(f *faker, args []string) (any, error) {
+		n, err := intArgs(args, 0, 2, 1, 1)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.Join(args, ":")
+		i := f.seq[key]
+		f.seq[key] = i + 1
+		return n[0] + i*n[1], nil
+	}},
+
+	{name: "pick", args: ":a|b|c", group: "Choice", doc: "one of the listed values", raw: true, gen: func(f *faker, args []string) (any, error) {
+		if len(args) == 0 || args[0] == "" {
+			return nil, fmt.Errorf("pick needs values, e.g. fake:pick:draft|published")
+		}
+		return f.pick(strings.Split(args[0], "|")), nil
+	}},
+	{name: "regex", args: ":pattern", group: "Choice", doc: `string matching the pattern, e.g. fake:regex:[A-Z]{3}-\d{4}`, raw: true, gen: func(f *faker, args []string) (any, error) {
+		if len(args) == 0 || args[0] == "" {
+			return nil, fmt.Errorf("regex needs a pattern")
+		}
+		return f.regex(args[0])
+	}},
+
+	{name: "date", args: "[:from:to]", group: "Dates", doc: "date (YYYY-MM-DD) within the past year or the range", gen: func(f *faker, args []string) (any, error) {
+		t, err := f.timeIn(args)
+		if err != nil {
+			return nil, err
+		}
+		return t.Format("2006-01-02"), nil
+	}},
+	{name: "datetime", args: "[:from:to]", group: "Dates", doc: "timestamp within the past year or the range", gen: func(f *faker, args []string) (any, error) {
+		t, err := f.timeIn(args)
+		if err != nil {
+			return nil, err
+		}
+		return t.Format("2006-01-02 15:04:05"), nil
+	}},
+
+	{name: "json", args: "[:keys]", group: "Structured", doc: "JSON object with random keys and values (default 3 keys)", gen: func(f *faker, args []string) (any, error) {
+		n, err := intArgs(args, 0, 1, 3)
+		if err != nil {
+			return nil, err
+		}
+		return f.object(n[0]), nil
+	}},
+}
+
+var fakeProviderIndex = func() map[string]*fakeProvider {
+	m := make(map[string]*fakeProvider, len(fakeProviders))
+	for i := range fakeProviders {
+		m[fakeProviders[i].name] = &fakeProviders[i]
+	}
+	return m
+}()
+
+// RenderFakeProviders documents the fake: tokens and locales for seed fake:list.
+func RenderFakeProviders() string {
+	var b strings.Builder
+	group := ""
+	for _, p := range fakeProviders {
+		if p.group != group {
+			if group != "" {
+				b.WriteString("\n")
+			}
+			group = p.group
+			b.WriteString(group + ":\n")
+		}
+		fmt.Fprintf(&b, "  %-34s %s\n", "fake:"+p.name+p.args, p.doc)
+	}
+	fmt.Fprintf(&b, "\nLocales: %s (locale: in a fixture, default %s)\n", strings.Join(fakeLocaleNames(), ", "), defaultFakeLocale)
+	b.WriteString("Date ranges take YYYY-MM-DD, now, or days relative to now (-30d, +7d).\n")
+	return b.String()
+}
+
+// intArgs parses between min and max integer arguments; missing optional ones
+// take the defaults, which are given for positions min..max-1.
+func intArgs(args []string, min, max int, defaults ...int) ([]int, error) {
+	if len(args) < min || len(args) > max {
+		return nil, fmt.Errorf("expected %d to %d arguments, got %d", min, max, len(args))
+	}
+	out := make([]int, max)
+	for i := range out {
+		if i >= len(args) {
+			out[i] = defaults[i-min]
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(args[i]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", args[i])
+		}
+		out[i] = n
+	}
+	return out, nil
+}
+
+func (f *faker) int(args []string) (int, error) {
+	if len(args) != 2 {
+		return 0, fmt.Errorf("invalid fake int token, expected fake:int:min:max")
+	}
+	min, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid fake int min %q", args[0])
+	}
+	max, err := strconv.Atoi(args[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid fake int max %q", args[1])
+	}
+	if max < min {
+		return 0, fmt.Errorf("invalid fake int range %d..%d", min, max)
+	}
+	return min + f.rand.Intn(max-min+1), nil
+}
+
+// float draws from [min, max] and returns the requested number of decimals.
+func (f *faker) float(args []string) (float64, int, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return 0, 0, fmt.Errorf("expected min:max[:decimals]")
+	}
+	min, err1 := strconv.ParseFloat(args[0], 64)
+	max, err2 := strconv.ParseFloat(args[1], 64)
+	if err1 != nil || err2 != nil || max < min {
+		return 0, 0, fmt.Errorf("invalid range %s..%s", args[0], args[1])
+	}
+	decimals := 2
+	if len(args) == 3 {
+		d, err := strconv.Atoi(args[2])
+		if err != nil || d < 0 || d > 12 {
+			return 0, 0, fmt.Errorf("invalid decimals %q", args[2])
+		}
+		decimals = d
+	}
+	return min + f.rand.Float64()*(max-min), decimals, nil
+}
+
+// timeIn returns a random instant within the past year, or within [from, to].
+func (f *faker) timeIn(args []string) (time.Time, error) {
+	now := f.now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	switch len(args) {
+	case 0:
+		days := f.rand.Intn(365)
+		secs := f.rand.Intn(86400)
+		return now.AddDate(0, 0, -days).Add(-time.Duration(secs) * time.Second), nil
+	case 2:
+		from, err := parseFakeTime(args[0], now)
+		if err != nil {
+			return time.Time{}, err
+		}
+		to, err := parseFakeTime(args[1], now)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if to.Before(from) {
+			return time.Time{}, fmt.Errorf("empty date range %s..%s", args[0], args[1])
+		}
+		span := to.Sub(from)
+		if span == 0 {
+			return from, nil
+		}
+		return from.Add(time.Duration(f.rand.Int63n(int64(span)))), nil
+	default:
+		return time.Time{}, fmt.Errorf("expected from:to")
+	}
+}
+
+// parseFakeTime understands YYYY-MM-DD, now and relative days like -30d.
+func parseFakeTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "now":
+		return now, nil
+	case strings.HasSuffix(s, "d") && (strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+")):
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid relative date %q", s)
+		}
+		return now.AddDate(0, 0, days), nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD, now or -30d)", s)
+	}
+	return t, nil
+}
+
+// person picks a first and last name of the same gender.
+func (f *faker) person() (string, string) {
+	l := f.locale
+	last := f.pick(l.lastNames)
+	if f.rand.Intn(2) == 0 {
+		return f.pick(l.maleFirst), last
+	}
+	if l.femaleLast != nil {
+		last = l.femaleLast(last)
+	}
+	return f.pick(l.femaleFirst), last
+}
+
+func (f *faker) username() string {
+	first, last := f.person()
+	first, last = f.slug(first), f.slug(last)
+	switch f.rand.Intn(3) {
+	case 0:
+		return first + "." + last
+	case 1:
+		return fmt.Sprintf("%s_%s%d", first, last, f.rand.Intn(100))
+	default:
+		return fmt.Sprintf("%s%d", first, 1+f.rand.Intn(999))
+	}
+}
+
+func (f *faker) domain() string {
+	tlds := []string{"com", "net", "org", "io", "dev"}
+	return fmt.Sprintf("%s-%s.%s", f.slugWords(1)[0], f.slugWords(1)[0], f.pick(tlds))
+}
+
+// slug lower-cases and transliterates s for use in identifiers.
+func (f *faker) slug(s string) string {
+	s = strings.ToLower(f.locale.toASCII(s))
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+func (f *faker) slugWords(n int) []string {
+	out := f.words(n)
+	for i := range out {
+		out[i] = f.slug(out[i])
+	}
+	return out
+}
+
+func (f *faker) words(n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = f.pick(f.locale.words)
+	}
+	return out
+}
+
+func (f *faker) sentence(n int) string {
+	if n <= 0 {
+		n = 6 + f.rand.Intn(7)
+	}
+	w := f.words(n)
+	r := []rune(w[0])
+	r[0] = unicode.ToUpper(r[0])
+	w[0] = string(r)
+	return strings.Join(w, " ") + "."
+}
+
+func (f *faker) digits(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + f.rand.Intn(10))
+	}
+	return string(b)
+}
+
+// object builds a flat JSON object; normalizeRows serializes it per driver.
+func (f *faker) object(keys int) map[string]any {
+	out := make(map[string]any, keys)
+	for tries := 0; len(out) < keys && tries < keys*10; tries++ {
+		k := f.slugWords(1)[0]
+		if _, dup := out[k]; dup || k == "" {
+			continue
+		}
+		switch f.rand.Intn(3) {
+		case 0:
+			out[k] = f.rand.Intn(1000)
+		case 1:
+			out[k] = f.pick(f.locale.words)
+		default:
+			out[k] = f.rand.Intn(2) == 0
+		}
+	}
+	return out
+}
+
+// regexMaxRepeat bounds *, + and open-ended {n,} repetitions.
+const regexMaxRepeat = 8
+
+// regex generates a string matching pattern.
+func (f *faker) regex(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("invalid regex %q: %w", pattern, err)
+	}
+	var b strings.Builder
+	if err := f.writeRegex(&b, re.Simplify()); err != nil {
+		return "", fmt.Errorf("regex %q: %w", pattern, err)
+	}
+	return b.String(), nil
+}
+
+func (f *faker) writeRegex(b *strings.Builder, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && f.rand.Intn(2) == 0 {
+				r = unicode.SimpleFold(r)
+			}
+			b.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		b.WriteRune(f.classRune(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune(f.classRune([]rune{'0', '9', 'A', 'Z', 'a', 'z'}))
+	case syntax.OpCapture:
+		return f.writeRegex(b, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := f.writeRegex(b, sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		return f.writeRegex(b, re.Sub[f.rand.Intn(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, regexMaxRepeat
+		case syntax.OpPlus:
+			min, max = 1, regexMaxRepeat
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 {
+			max = min + regexMaxRepeat
+		}
+		for i, n := 0, min+f.rand.Intn(max-min+1); i < n; i++ {
+			if err := f.writeRegex(b, re.Sub[0]); err != nil {
+				return err
+			}
+		}
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+	default:
+		return fmt.Errorf("unsupported construct %s", re)
+	}
+	return nil
+}
+
+// classRune picks a rune from a character class given as lo-hi pairs,
+// preferring printable ASCII for negated or very wide classes.
+func (f *faker) classRune(ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < 0x20 {
+			lo = 0x20
+		}
+		if hi > 0x7e {
+			hi = 0x7e
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	var total int
+	for i := 0; i+1 < len(ranges); i += 2 {
+		total += int(ranges[i+1]-ranges[i]) + 1
+	}
+	if total == 0 {
+		return '?'
+	}
+	n := f.rand.Intn(total)
+	for i := 0; i+1 < len(ranges); i += 2 {
+		size := int(ranges[i+1]-ranges[i]) + 1
+		if n < size {
+			return ranges[i] + rune(n)
+		}
+		n -= size
+	}
+	return ranges[0]
+}
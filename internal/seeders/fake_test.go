@@ -0,0 +1,113 @@
+package seeders
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFakeProviders(t *testing.T) {
+	t.Parallel()
+
+	// Every provider in the catalogue works with its documented arguments.
+	sample := map[string]string{
+		"int": ":1:10", "float": ":0:1:3", "decimal": ":10:20:4", "pick": ":a|b",
+		"regex": `:[a-f]{2}\d`, "date": ":2024-01-01:2024-01-31", "datetime": ":-7d:now",
+	}
+	for _, locale := range fakeLocaleNames() {
+		f := newFaker(1)
+		f.locale = fakeLocales[locale]
+		for _, p := range fakeProviders {
+			token := "fake:" + p.name + sample[p.name]
+			v, err := f.value(token)
+			if err != nil || v == nil {
+				t.Errorf("%s %s: %v, %v", locale, token, v, err)
+			}
+		}
+	}
+
+	f := newFaker(7)
+	check := func(token, pattern string) {
+		t.Helper()
+		for i := 0; i < 20; i++ {
+			v, err := f.value(token)
+			if err != nil {
+				t.Fatalf("%s: %v", token, err)
+			}
+			if s, _ := v.(string); !regexp.MustCompile(pattern).MatchString(s) {
+				t.Fatalf("%s = %#v, want %s", token, v, pattern)
+			}
+		}
+	}
+	check(`fake:regex:^[A-Z]{3}-\d{4}(x|y)?$`, `^[A-Z]{3}-\d{4}(x|y)?$`)
+	check("fake:pick:draft|published", `^(draft|published)$`)
+	check("fake:decimal:1:2:3", `^1\.\d{3}$`)
+	check("fake:date:2024-02-01:2024-02-10", `^2024-02-0\d$`)
+	check("fake:ipv4", `^\d+\.\d+\.\d+\.\d+$`)
+	check("fake:url", `^https://[a-z]+-[a-z]+\.[a-z]+/[a-z-]+$`)
+
+	for want := 100; want <= 120; want += 10 {
+		if v, _ := f.value("fake:seq:100:10"); v != want {
+			t.Fatalf("seq = %v, want %d", v, want)
+		}
+	}
+	if v, _ := f.value("fake:float:5:5"); v != 5.0 {
+		t.Fatalf("float = %v", v)
+	}
+	if obj, _ := f.value("fake:json:2"); len(obj.(map[string]any)) != 2 {
+		t.Fatalf("json = %v", obj)
+	}
+
+	for _, bad := range []string{"fake:nope", "fake:int:5:1", "fake:regex:(", "fake:date:2024-02-10:2024-02-01", "fake:words:x", "fake:pick:"} {
+		if _, err := f.value(bad); err == nil {
+			t.Errorf("expected %s to fail", bad)
+		}
+	}
+}
+
+func TestFakeLocales(t *testing.T) {
+	t.Parallel()
+
+	rows, err := expandFixtureRows(YAMLSeed{
+		Name: "users", Table: "users", Count: 20, Locale: "ru",
+		Template: map[string]any{"name": "fake:full_name", "email": "fake:email", "city": "fake:city"},
+	})
+	if err != nil {
+		t.Fatalf("expandFixtureRows: %v", err)
+	}
+	ascii := regexp.MustCompile(`^[a-z]+\.[a-z]+\d*@[a-z.]+$`)
+	for _, r := range rows {
+		name := r["name"].(string)
+		first, last, _ := strings.Cut(name, " ")
+		female := false
+		for _, n := range fakeLocales["ru"].femaleFirst {
+			female = female || n == first
+		}
+		if female != strings.HasSuffix(last, "а") {
+			t.Fatalf("last name does not agree with first name: %s", name)
+		}
+		if !ascii.MatchString(r["email"].(string)) {
+			t.Fatalf("email not transliterated: %v", r["email"])
+		}
+	}
+
+	if _, err := expandFixtureRows(YAMLSeed{Name: "x", Count: 1, Locale: "xx", Template: map[string]any{"a": "fake:city"}}); err == nil {
+		t.Fatal("expected an unknown locale error")
+	}
+}
+
+func TestFakeNow(t *testing.T) {
+	seed := int64(42)
+	for fakeNow, want := range map[string]string{"": "2024-01-01", "2030-05-01": "2030-05-01", "2030-05-01T12:00:00Z": "2030-05-01"} {
+		f, err := YAMLSeed{Name: "s", RandomSeed: &seed, FakeNow: fakeNow}.faker()
+		if err != nil {
+			t.Fatalf("%q: %v", fakeNow, err)
+		}
+		if got := f.now.Format("2006-01-02"); got != want {
+			t.Errorf("fake_now %q: now = %s, want %s", fakeNow, got, want)
+		}
+	}
+	if _, err := (YAMLSeed{Name: "s", RandomSeed: &seed, FakeNow: "tomorrow"}).faker(); err == nil {
+		t.Fatal("expected an error for an invalid fake_now")
+	}
+}
@@ -0,0 +1,347 @@
+package seeders
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// A fixture can read its rows from a data file, relative to the seed file,
+// instead of rows:. The file is streamed in chunk_size batches:
+//
+//	type: fixture
+//	table: cities
+//	file: data/cities.csv       # .csv, .tsv, .json (an array), .ndjson or .jsonl
+//	columns: { "City name": name, Notes: "-" }
+//	null: ["", "NULL"]
+//
+// CSV values are converted to the types of the table's columns; JSON values
+// keep their JSON types. Values are inserted as they are: no fake tokens,
+// expressions or references.
+
+// rowSource yields the rows of a data file; next returns io.EOF after the last.
+type rowSource interface {
+	next() (map[string]any, error)
+}
+
+func fixtureFormat(s YAMLSeed) (string, error) {
+	format := strings.ToLower(strings.TrimSpace(s.Format))
+	if format == "" {
+		switch ext := strings.ToLower(filepath.Ext(s.File)); ext {
+		case ".csv", ".tsv", ".json", ".ndjson":
+			format = ext[1:]
+		case ".jsonl":
+			format = "ndjson"
+		default:
+			return "", fmt.Errorf("cannot tell the format of %s; set format: csv, tsv, json or ndjson", s.File)
+		}
+	}
+	switch format {
+	case "csv", "tsv", "json", "ndjson":
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown fixture file format %q (csv, tsv, json, ndjson)", format)
+}
+
+func openRowSource(r io.Reader, s YAMLSeed) (rowSource, error) {
+	format, err := fixtureFormat(s)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "csv", "tsv":
+		return newCSVSource(r, s, format)
+	default:
+		dec := json.NewDecoder(bufio.NewReader(r))
+		dec.UseNumber()
+		src := &jsonSource{dec: dec, columns: s.Columns}
+		if format == "json" {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			if d, ok := tok.(json.Delim); !ok || d != '[' {
+				return nil, errors.New("a json fixture file must hold an array of objects")
+			}
+			src.array = true
+		}
+		return src, nil
+	}
+}
+
+// mapColumn renames a file column through columns:; "" means skip it.
+func mapColumn(columns map[string]string, name string) string {
+	if to, ok := columns[name]; ok {
+		if to = strings.TrimSpace(to); to == "-" {
+			return ""
+		}
+		return to
+	}
+	return name
+}
+
+type csvSource struct {
+	r      *csv.Reader
+	header []string
+	null   map[string]bool
+}
+
+func newCSVSource(r io.Reader, s YAMLSeed, format string) (*csvSource, error) {
+	cr := csv.NewReader(bufio.NewReader(r))
+	cr.ReuseRecord = true
+	switch {
+	case s.Delimiter != "":
+		d := []rune(s.Delimiter)
+		if len(d) != 1 {
+			return nil, fmt.Errorf("delimiter must be one character, got %q", s.Delimiter)
+		}
+		cr.Comma = d[0]
+	case format == "tsv":
+		cr.Comma = '\t'
+	}
+	head, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("csv fixture file has no header")
+	}
+	if err != nil {
+		return nil, err
+	}
+	src := &csvSource{r: cr, null: map[string]bool{}}
+	for i, h := range head {
+		if i == 0 {
+			h = strings.TrimPrefix(h, "\ufeff")
+		}
+		src.header = append(src.header, mapColumn(s.Columns, strings.TrimSpace(h)))
+	}
+	markers := s.Null
+	if markers == nil {
+		markers = []string{""}
+	}
+	for _, m := range markers {
+		src.null[m] = true
+	}
+	return src, nil
+}
+
+func (c *csvSource) next() (map[string]any, error) {
+	rec, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	row := make(map[string]any, len(c.header))
+	for i, col := range c.header {
+		if col == "" {
+			continue
+		}
+		if c.null[rec[i]] {
+			row[col] = nil
+		} else {
+			row[col] = rec[i]
+		}
+	}
+	return row, nil
+}
+
+type jsonSource struct {
+	dec     *json.Decoder
+	array   bool
+	columns map[string]string
+}
+
+func (j *jsonSource) next() (map[string]any, error) {
+	if j.array && !j.dec.More() {
+		if _, err := j.dec.Token(); err != nil { // the closing ]
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	var obj map[string]any
+	if err := j.dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+	row := make(map[string]any, len(obj))
+	for k, v := range obj {
+		if col := mapColumn(j.columns, k); col != "" {
+			if n, ok := v.(json.Number); ok {
+				v = keyValue(n)
+			}
+			row[col] = v
+		}
+	}
+	return row, nil
+}
+
+// coerceCSV converts the text values of a CSV row to the column types.
+func coerceCSV(row map[string]any, types map[string]string) error {
+	for col, v := range row {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		t := types[col]
+		var err error
+		switch {
+		case t == "":
+		case isBoolType(t):
+			row[col], err = strconv.ParseBool(strings.TrimSpace(s))
+		case isIntType(t):
+			row[col], err = strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		case strings.Contains(t, "float") || strings.Contains(t, "double") || strings.Contains(t, "real"):
+			row[col], err = strconv.ParseFloat(strings.TrimSpace(s), 64)
+		}
+		if err != nil {
+			return fmt.Errorf("field %s: %q is not a valid %s", col, s, t)
+		}
+	}
+	return nil
+}
+
+// columnTypes maps the columns of table to their lower-case database types.
+func columnTypes(tx *gorm.DB, table string) (map[string]string, error) {
+	cts, err := tx.Migrator().ColumnTypes(table)
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]string, len(cts))
+	for _, ct := range cts {
+		types[ct.Name()] = strings.ToLower(ct.DatabaseTypeName())
+	}
+	return types, nil
+}
+
+// openFixtureFile opens the data file of a fixture; the caller closes fh.
+func openFixtureFile(baseDir string, s YAMLSeed) (fh *os.File, src rowSource, err error) {
+	full := s.File
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(baseDir, s.File)
+	}
+	if fh, err = os.Open(full); err != nil {
+		return nil, nil, err
+	}
+	if src, err = openRowSource(fh, s); err != nil {
+		fh.Close()
+		return nil, nil, fmt.Errorf("%s: %w", s.File, err)
+	}
+	return fh, src, nil
+}
+
+// runFixtureFile loads a fixture from its data file in chunks, in a single
+// transaction.
+func runFixtureFile(db *gorm.DB, baseDir string, s YAMLSeed, batch int) error {
+	if s.Table == "" {
+		return errors.New("fixture seed: table is required")
+	}
+	if len(s.Rows) > 0 || len(s.Template) > 0 || len(s.Children) > 0 {
+		return errors.New("fixture seed: file cannot be combined with rows, template or children")
+	}
+	fh, src, err := openFixtureFile(baseDir, s)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+	_, isCSV := src.(*csvSource)
+
+	chunk := s.ChunkSize
+	if chunk <= 0 {
+		chunk = defaultChunkSize
+	}
+
+	tx, conn, err := beginFixture(db, s)
+	if err != nil {
+		return err
+	}
+	if conn != nil {
+		defer conn.Close()
+	}
+	fail := func(err error) error {
+		tx.Rollback()
+		return err
+	}
+	if err := prepareConflict(tx, s); err != nil {
+		return fail(err)
+	}
+	writer, err := newRowWriter(tx, conn, s, chunk)
+	if err != nil {
+		return fail(err)
+	}
+	fail = func(err error) error {
+		writer.done(false)
+		tx.Rollback()
+		return err
+	}
+	var inserts *insertLog
+	if s.Down == "" {
+		inserts = newInsertLog()
+	}
+	var types map[string]string
+	if isCSV {
+		if types, err = columnTypes(tx, s.Table); err != nil {
+			return fail(err)
+		}
+	}
+
+	rows := make([]map[string]any, 0, chunk)
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := normalizeRows(tx, s.Table, rows); err != nil {
+			return fmt.Errorf("normalize rows failed: %w", err)
+		}
+		if err := inserts.expect(tx, s.Table, rows); err != nil {
+			return fmt.Errorf("track inserted rows failed: %w", err)
+		}
+		if err := writer.write(rows); err != nil {
+			return err
+		}
+		rows = make([]map[string]any, 0, chunk)
+		return nil
+	}
+	for n := 1; ; n++ {
+		row, err := src.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail(fmt.Errorf("%s: row %d: %w", s.File, n, err))
+		}
+		if isCSV {
+			if err := coerceCSV(row, types); err != nil {
+				return fail(fmt.Errorf("%s: row %d: %w", s.File, n, err))
+			}
+		}
+		rows = append(rows, row)
+		if len(rows) == chunk {
+			if err := flush(); err != nil {
+				return fail(err)
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return fail(err)
+	}
+	if err := writer.done(true); err != nil {
+		return fail(err)
+	}
+
+	undo := undoLog{SQL: s.Down}
+	if inserts != nil {
+		if undo, err = inserts.undo(tx); err != nil {
+			return fail(fmt.Errorf("track inserted rows failed: %w", err))
+		}
+	}
+	if err := tx.Create(&Seed{Name: s.Name, Batch: batch, RanAt: time.Now(), Undo: undo.encode()}).Error; err != nil {
+		return fail(err)
+	}
+	return tx.Commit().Error
+}
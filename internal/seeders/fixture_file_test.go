@@ -0,0 +1,83 @@
+package seeders
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFixtureFiles(t *testing.T) {
+	db := openSeedDB(t, `CREATE TABLE cities (id INTEGER PRIMARY KEY, name TEXT, population INTEGER, area REAL, capital BOOLEAN, meta TEXT)`)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"cities.csv": "\ufeffCity name,population,area,capital,Notes\n" +
+			"Berlin,3645000,891.8,true,x\n" +
+			"\"Washington, D.C.\",689545,177,1,\n" +
+			"Nowhere,NULL,,false,\n",
+		"cities.json":   `[{"name": "Tokyo", "population": 13960000, "meta": {"jp": true}}, {"name": "Osaka", "area": 225.2}]`,
+		"cities.ndjson": "{\"name\": \"Lyon\", \"capital\": false}\n{\"name\": \"Nice\", \"skip\": 1}\n",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seeds := []YAMLSeed{
+		{Name: "csv", Type: "fixture", Table: "cities", File: "cities.csv", ChunkSize: 2,
+			Columns: map[string]string{"City name": "name", "Notes": "-"}, Null: []string{"", "NULL"}},
+		{Name: "json", Type: "fixture", Table: "cities", File: "cities.json", ChunkSize: 1},
+		{Name: "ndjson", Type: "fixture", Table: "cities", File: "cities.ndjson", Columns: map[string]string{"skip": "-"}},
+	}
+	for _, s := range seeds {
+		if err := runSeed(db, dir, s, 1); err != nil {
+			t.Fatalf("%s: %v", s.Name, err)
+		}
+	}
+
+	var got []struct {
+		Name       string
+		Population *int64
+		Area       *float64
+		Capital    *bool
+		Meta       *string
+	}
+	db.Table("cities").Order("id").Find(&got)
+	if len(got) != 7 {
+		t.Fatalf("rows = %d", len(got))
+	}
+	if got[1].Name != "Washington, D.C." || *got[1].Population != 689545 || !*got[1].Capital || *got[1].Area != 177 {
+		t.Errorf("csv row = %+v", got[1])
+	}
+	if got[2].Population != nil || got[2].Area != nil || *got[2].Capital {
+		t.Errorf("null markers: %+v", got[2])
+	}
+	if got[3].Name != "Tokyo" || *got[3].Population != 13960000 || got[3].Meta == nil || *got[3].Meta != `{"jp":true}` {
+		t.Errorf("json row = %+v", got[3])
+	}
+	if got[6].Name != "Nice" {
+		t.Errorf("ndjson row = %+v", got[6])
+	}
+
+	var csvSeed Seed
+	db.Where("name = ?", "csv").First(&csvSeed)
+	if !strings.Contains(csvSeed.Undo, `"ranges":[[1,3]]`) {
+		t.Errorf("undo = %s", csvSeed.Undo)
+	}
+
+	bad := YAMLSeed{Name: "bad", Type: "fixture", Table: "cities", File: "cities.csv", Columns: map[string]string{"City name": "name", "Notes": "-"}}
+	if err := runSeed(db, dir, bad, 2); err == nil || !strings.Contains(err.Error(), "row 3") {
+		t.Errorf("bad integer: %v", err)
+	}
+
+	if err := RollbackAll(db); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	var n int64
+	db.Table("cities").Count(&n)
+	if n != 0 {
+		t.Fatalf("%d rows left after rollback", n)
+	}
+}
@@ -0,0 +1,278 @@
+package seeders
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"forge/internal/plugins"
+)
+
+// Go seeds of a project live next to the YAML files, in database/seeds/*.go:
+//
+//	func SeedUsers(tx *sql.Tx) error
+//
+// forge cannot load them into its own binary, so it generates a small main
+// package in .forge/seeders of the project's module, builds it like a source
+// plugin and runs it with the function name; the DSN is passed through the
+// environment.
+
+const (
+	goSeedsBuildDir = ".forge/seeders"
+	goSeedDriverEnv = "FORGE_SEED_DRIVER"
+	goSeedDSNEnv    = "FORGE_SEED_DSN"
+)
+
+// goSeedFuncs lists the exported func(*sql.Tx) error functions of the Go
+// package in dir and returns its name. A directory without Go files yields
+// no functions.
+func goSeedFuncs(dir string) (pkg string, funcs []string, err error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", nil, err
+	}
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return "", nil, err
+		}
+		if pkg != "" && f.Name.Name != pkg {
+			return "", nil, fmt.Errorf("%s: package %s, expected %s", path, f.Name.Name, pkg)
+		}
+		pkg = f.Name.Name
+		sqlName := importName(f, "database/sql")
+		for _, d := range f.Decls {
+			fn, ok := d.(*ast.FuncDecl)
+			if ok && fn.Recv == nil && fn.Name.IsExported() && isSeedFunc(fn.Type, sqlName) {
+				funcs = append(funcs, fn.Name.Name)
+			}
+		}
+	}
+	sort.Strings(funcs)
+	return pkg, funcs, nil
+}
+
+// importName returns the name path is imported under in f, or "".
+func importName(f *ast.File, path string) string {
+	for _, imp := range f.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p != path {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return filepath.Base(path)
+	}
+	return ""
+}
+
+// isSeedFunc reports whether t is func(*sql.Tx) error.
+func isSeedFunc(t *ast.FuncType, sqlName string) bool {
+	if sqlName == "" || t.TypeParams != nil || len(t.Params.List) != 1 || len(t.Params.List[0].Names) > 1 ||
+		t.Results == nil || len(t.Results.List) != 1 || len(t.Results.List[0].Names) > 1 {
+		return false
+	}
+	star, ok := t.Params.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Tx" {
+		return false
+	}
+	if x, ok := sel.X.(*ast.Ident); !ok || x.Name != sqlName {
+		return false
+	}
+	res, ok := t.Results.List[0].Type.(*ast.Ident)
+	return ok && res.Name == "error"
+}
+
+// goModule finds the go.mod above dir and returns its directory and module path.
+func goModule(dir string) (root, module string, err error) {
+	root, err = filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+	for {
+		raw, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			sc := bufio.NewScanner(bytes.NewReader(raw))
+			for sc.Scan() {
+				if rest, ok := strings.CutPrefix(strings.TrimSpace(sc.Text()), "module "); ok {
+					module = strings.Trim(strings.TrimSpace(rest), `"`)
+					return root, module, nil
+				}
+			}
+			return "", "", fmt.Errorf("%s: no module line", filepath.Join(root, "go.mod"))
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return "", "", fmt.Errorf("no go.mod found above %s: Go seeds must be part of a Go module (run go mod init)", dir)
+		}
+		root = parent
+	}
+}
+
+// goSeedsHelper writes the main package that runs the Go seeds in dir and
+// returns it as a source plugin, rebuilt when the seeds change.
+func goSeedsHelper(dir string) (plugins.Plugin, error) {
+	pkg, funcs, err := goSeedFuncs(dir)
+	if err != nil {
+		return plugins.Plugin{}, err
+	}
+	if pkg == "main" {
+		return plugins.Plugin{}, fmt.Errorf("%s: Go seeds must not be package main", dir)
+	}
+	root, module, err := goModule(dir)
+	if err != nil {
+		return plugins.Plugin{}, err
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return plugins.Plugin{}, err
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return plugins.Plugin{}, err
+	}
+
+	p := plugins.Plugin{
+		Manifest: plugins.PluginManifest{Name: "seeders", Lang: "go", Entry: "seeders", Source: "src", Watch: []string{abs}},
+		BaseDir:  filepath.Join(root, goSeedsBuildDir),
+	}
+	src, err := goSeedsMain(module+"/"+filepath.ToSlash(rel), funcs)
+	if err != nil {
+		return p, err
+	}
+	// rewrite only on change, so the binary stays fresh otherwise
+	mainPath := filepath.Join(p.SourcePath(), "main.go")
+	if old, err := os.ReadFile(mainPath); err == nil && bytes.Equal(old, src) {
+		return p, nil
+	}
+	if err := os.MkdirAll(p.SourcePath(), 0o755); err != nil {
+		return p, err
+	}
+	return p, os.WriteFile(mainPath, src, 0o644)
+}
+
+func goSeedsMain(importPath string, funcs []string) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, `// Code generated by forge from %s; DO NOT EDIT.
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	seeds %q
+)
+
+var funcs = map[string]func(*sql.Tx) error{
+`, importPath, importPath)
+	for _, fn := range funcs {
+		fmt.Fprintf(&b, "%q: seeds.%s,\n", fn, fn)
+	}
+	fmt.Fprintf(&b, `}
+
+// database/sql driver names per forge driver; the seeds package imports one.
+var drivers = map[string][]string{
+	"postgres": {"pgx", "postgres"},
+	"mysql":    {"mysql"},
+	"sqlite":   {"sqlite3", "sqlite"},
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if len(os.Args) != 2 {
+		return fmt.Errorf("usage: %%s FUNC", os.Args[0])
+	}
+	fn := funcs[os.Args[1]]
+	if fn == nil {
+		return fmt.Errorf("function %%s is not defined in %s", os.Args[1])
+	}
+	driver := os.Getenv(%q)
+	name := ""
+	for _, want := range drivers[driver] {
+		for _, d := range sql.Drivers() {
+			if d == want && name == "" {
+				name = d
+			}
+		}
+	}
+	if name == "" {
+		return fmt.Errorf("no database/sql driver for %%s is registered: import one in %s", driver)
+	}
+	db, err := sql.Open(name, os.Getenv(%q))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+`, importPath, goSeedDriverEnv, importPath, goSeedDSNEnv)
+	return format.Source([]byte(b.String()))
+}
+
+// runProjectGoSeed builds the Go seeds in dir if needed and runs fn against
+// the database. The function runs in its own transaction, in the helper.
+func runProjectGoSeed(dir, fn, driver, dsn string) error {
+	_, funcs, err := goSeedFuncs(dir)
+	if err != nil {
+		return err
+	}
+	if !strIn(funcs, fn) {
+		return fmt.Errorf("go seed: function %q is neither registered nor defined as func(*sql.Tx) error in %s", fn, dir)
+	}
+	p, err := goSeedsHelper(dir)
+	if err != nil {
+		return err
+	}
+	if err := plugins.EnsurePluginExecutable(p); err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(p.EntryPath(), fn)
+	cmd.Env = append(os.Environ(), goSeedDriverEnv+"="+driver, goSeedDSNEnv+"="+dsn)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		if msg := strings.TrimSpace(stderr.String()); errors.As(err, &exit) && msg != "" {
+			return fmt.Errorf("go seed %s: %s", fn, msg)
+		}
+		return fmt.Errorf("go seed %s: %w", fn, err)
+	}
+	return nil
+}
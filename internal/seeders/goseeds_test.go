@@ -0,0 +1,104 @@
+package seeders
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjectGoSeeds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a helper binary")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not found")
+	}
+
+	// a project module with the sqlite driver forge itself is built with
+	root := t.TempDir()
+	sum, err := os.ReadFile("../../go.sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(sum), "\n") {
+		if strings.HasPrefix(line, "github.com/mattn/go-sqlite3 ") {
+			lines = append(lines, line)
+		}
+	}
+	dir := filepath.Join(root, "database", "seeds")
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n\nrequire github.com/mattn/go-sqlite3 v1.14.22\n",
+		"go.sum": strings.Join(lines, "\n") + "\n",
+		"database/seeds/users.go": `package seeds
+
+import (
+	"database/sql"
+	"errors"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func SeedUsers(tx *sql.Tx) error {
+	_, err := tx.Exec("INSERT INTO users (email) VALUES ('a@example.com'), ('b@example.com')")
+	return err
+}
+
+func SeedBroken(tx *sql.Tx) error {
+	if _, err := tx.Exec("INSERT INTO users (email) VALUES ('c@example.com')"); err != nil {
+		return err
+	}
+	return errors.New("boom")
+}
+
+func helper(tx *sql.Tx) error       { return nil }
+func WrongSignature(db *sql.DB) error { return nil }
+`,
+	}
+	for name, body := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stub, err := writeGoSeedStub(dir, "roles", "SeedRoles")
+	if err != nil || stub == "" {
+		t.Fatalf("stub: %q, %v", stub, err)
+	}
+
+	pkg, funcs, err := goSeedFuncs(dir)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if pkg != "seeds" || strings.Join(funcs, ",") != "SeedBroken,SeedRoles,SeedUsers" {
+		t.Fatalf("funcs = %s %v", pkg, funcs)
+	}
+
+	dbPath := filepath.Join(root, "app.db")
+	db := openTestDB(t, dbPath, `CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT)`)
+
+	if err := runProjectGoSeed(dir, "SeedUsers", "sqlite", dbPath); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, goSeedsBuildDir, "src", "main.go")); err != nil {
+		t.Fatalf("helper source: %v", err)
+	}
+	err = runProjectGoSeed(dir, "SeedBroken", "sqlite", dbPath)
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("broken seed: %v", err)
+	}
+	if err := runProjectGoSeed(dir, "helper", "sqlite", dbPath); err == nil {
+		t.Error("unexported function ran")
+	}
+
+	var emails []string
+	db.Table("users").Order("id").Pluck("email", &emails)
+	if strings.Join(emails, ",") != "a@example.com,b@example.com" {
+		t.Errorf("emails = %v", emails)
+	}
+}
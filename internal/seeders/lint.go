@@ -0,0 +1,478 @@
+package seeders
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"forge/internal/schema"
+
+	"gorm.io/gorm"
+)
+
+// lintRows bounds the rows generated per template when linting.
+const lintRows = 100
+
+// LintIssue is a problem found in a seed before running it.
+type LintIssue struct {
+	File    string
+	Seed    string
+	Message string
+}
+
+func (i LintIssue) String() string {
+	if i.Seed == "" {
+		return fmt.Sprintf("%s: %s", i.File, i.Message)
+	}
+	return fmt.Sprintf("%s: seed %q: %s", i.File, i.Seed, i.Message)
+}
+
+// Lint checks every seed against the schema of db without writing anything:
+// tables, columns, NOT NULL columns, literal types, ref: targets and
+// conflict_key. ref: lookups are also run against the data when no fixture
+// writes to the referenced table.
+func Lint(db *gorm.DB) ([]LintIssue, error) {
+	all, err := collectSeeds(seedsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := checkSeedDeps(all); err != nil {
+		return []LintIssue{{File: seedsDir, Message: err.Error()}}, nil
+	}
+	return lintSeeds(db, all, true)
+}
+
+// lintSeeds lints the given seeds; lookups runs ref: queries.
+func lintSeeds(db *gorm.DB, seeds []seedEntry, lookups bool) ([]LintIssue, error) {
+	model, err := schema.Introspect(db)
+	if err != nil {
+		return nil, fmt.Errorf("introspect schema: %w", err)
+	}
+	l := &linter{db: db, model: model, lookups: lookups, written: map[string]bool{}}
+	for _, e := range seeds {
+		for _, t := range fixtureTables(e.seed) {
+			l.written[strings.ToLower(t)] = true
+		}
+	}
+	for _, e := range seeds {
+		l.cur = e
+		l.lintSeed(e)
+	}
+	return l.issues, nil
+}
+
+// fixtureTables lists the tables a fixture seed writes to.
+func fixtureTables(s YAMLSeed) []string {
+	if !strings.EqualFold(strings.TrimSpace(s.Type), "fixture") {
+		return nil
+	}
+	out := []string{s.Table}
+	var walk func([]YAMLChild)
+	walk = func(children []YAMLChild) {
+		for _, ch := range children {
+			out = append(out, ch.Table)
+			walk(ch.Children)
+		}
+	}
+	walk(s.Children)
+	return out
+}
+
+type linter struct {
+	db      *gorm.DB
+	model   *schema.Model
+	lookups bool
+	written map[string]bool // tables written by fixtures
+	cur     seedEntry
+	issues  []LintIssue
+
+	goFuncs  []string // functions of the project's Go seeds, parsed once
+	goErr    error
+	goParsed bool
+}
+
+func (l *linter) add(format string, args ...any) {
+	l.issues = append(l.issues, LintIssue{File: l.cur.file, Seed: l.cur.seed.Name, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) lintSeed(e seedEntry) {
+	s := e.seed
+	switch strings.ToLower(strings.TrimSpace(s.Type)) {
+	case "sql":
+		if s.File != "" && !filepath.IsAbs(s.File) {
+			if _, err := os.Stat(filepath.Join(e.dir, s.File)); err != nil {
+				l.add("sql file %s: %v", s.File, err)
+			}
+		}
+	case "fixture":
+		l.lintFixture(e)
+	case "go":
+		l.lintGo(s)
+	default:
+		l.add("unknown type %q", s.Type)
+	}
+}
+
+func (l *linter) lintGo(s YAMLSeed) {
+	if s.Func == "" {
+		l.add("func is required")
+		return
+	}
+	if goFuncs[s.Func] != nil {
+		return
+	}
+	if !l.goParsed {
+		_, l.goFuncs, l.goErr = goSeedFuncs(seedsDir)
+		l.goParsed = true
+	}
+	switch {
+	case l.goErr != nil:
+		l.add("go seeds: %v", l.goErr)
+	case !strIn(l.goFuncs, s.Func):
+		l.add("function %s is not defined as func(*sql.Tx) error in %s", s.Func, seedsDir)
+	}
+}
+
+func (l *linter) lintFixture(e seedEntry) {
+	s := e.seed
+	t := l.table(s.Table)
+	if t == nil {
+		return
+	}
+	switch strings.ToLower(s.OnConflict) {
+	case "", "do_nothing":
+	case "update_all":
+		if len(s.ConflictKey) == 0 {
+			l.add("on_conflict: update_all needs conflict_key")
+		}
+	default:
+		l.add("unknown on_conflict %q (do_nothing, update_all)", s.OnConflict)
+	}
+	for _, k := range s.ConflictKey {
+		if columnOf(t, k) == nil {
+			l.add("conflict_key column %s does not exist in %s", k, t.Name)
+		}
+	}
+
+	if s.File != "" {
+		l.lintFixtureFile(e, t)
+		return
+	}
+	sample := s
+	if sample.Count > lintRows {
+		sample.Count = lintRows
+	}
+	rows, err := expandFixtureRows(sample)
+	if err != nil {
+		l.add("%v", err)
+		return
+	}
+	if _, err := takeAliases(rows); err != nil {
+		l.add("%v", err)
+	}
+	l.lintRows(t, rows, nil, true)
+	l.lintChildren(t, s.Children)
+}
+
+func (l *linter) lintChildren(parent *schema.Table, children []YAMLChild) {
+	for _, ch := range children {
+		t := l.table(ch.Table)
+		if t == nil {
+			continue
+		}
+		fk, _, err := childForeignKey(l.model, ch, parent.Name)
+		if err != nil {
+			l.add("children of %s: %v", parent.Name, err)
+			continue
+		}
+		for _, c := range fk {
+			if columnOf(t, c) == nil {
+				l.add("foreign_key column %s does not exist in %s", c, t.Name)
+			}
+		}
+		f, err := l.cur.seed.faker()
+		if err != nil {
+			l.add("%v", err)
+			return
+		}
+		row := cloneRow(ch.Template)
+		if err := f.resolveValues(row); err != nil {
+			l.add("children %s: %v", t.Name, err)
+			continue
+		}
+		// Expressions may read the parent row, which does not exist yet.
+		for k, v := range row {
+			if isExpr(v) || hasNestedExpr(v) {
+				row[k] = nil
+			}
+		}
+		l.lintRows(t, []map[string]any{row}, fk, true)
+		l.lintChildren(t, ch.Children)
+	}
+}
+
+// lintFixtureFile checks the columns of a data file: the CSV header, or the
+// keys and values of the first JSON object.
+func (l *linter) lintFixtureFile(e seedEntry, t *schema.Table) {
+	s := e.seed
+	full := s.File
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(e.dir, s.File)
+	}
+	fh, err := os.Open(full)
+	if err != nil {
+		l.add("fixture file: %v", err)
+		return
+	}
+	defer fh.Close()
+	src, err := openRowSource(fh, s)
+	if err != nil {
+		l.add("%s: %v", s.File, err)
+		return
+	}
+	if c, ok := src.(*csvSource); ok {
+		row := map[string]any{}
+		for _, h := range c.header {
+			if h != "" {
+				row[h] = nil
+			}
+		}
+		l.lintRows(t, []map[string]any{row}, nil, false)
+		return
+	}
+	row, err := src.next()
+	if err != nil {
+		l.add("%s: row 1: %v", s.File, err)
+		return
+	}
+	l.lintRows(t, []map[string]any{row}, nil, true)
+}
+
+func (l *linter) table(name string) *schema.Table {
+	if strings.TrimSpace(name) == "" {
+		l.add("table is required")
+		return nil
+	}
+	t := l.model.Table(name)
+	if t == nil {
+		l.add("table %s does not exist", name)
+	}
+	return t
+}
+
+func columnOf(t *schema.Table, name string) *schema.Column {
+	for i := range t.Columns {
+		if strings.EqualFold(t.Columns[i].Name, name) {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// lintRows checks rows for table t; filled are columns forge sets itself.
+func (l *linter) lintRows(t *schema.Table, rows []map[string]any, filled []string, values bool) {
+	unknown := map[string]bool{}
+	missing := map[string]int{}
+	bad := map[string]string{} // column and kind -> first problem
+	badRows := map[string]int{}
+	autoKey := len(t.PrimaryKey) == 1 && isIntType(columnType(t, t.PrimaryKey[0]))
+	for i, row := range rows {
+		for k, v := range row {
+			c := columnOf(t, k)
+			if c == nil {
+				unknown[k] = true
+				continue
+			}
+			if !values {
+				continue
+			}
+			// Problems are reported once per column and kind, with a count.
+			key, msg := k+" ref", l.lintValue(v)
+			if msg != "" {
+				msg = k + ": " + msg
+			} else if msg = literalMismatch(c.Type, v); msg != "" {
+				key, msg = k+" type", fmt.Sprintf("%s (%s): %s", k, c.Type, msg)
+			}
+			if msg != "" {
+				if badRows[key]++; badRows[key] == 1 {
+					bad[key] = fmt.Sprintf("row %d: %s", i+1, msg)
+				}
+			}
+		}
+		for _, c := range t.Columns {
+			if c.Nullable || c.Default != "" || (autoKey && strings.EqualFold(c.Name, t.PrimaryKey[0])) || strIn(filled, c.Name) {
+				continue
+			}
+			if _, ok := row[c.Name]; !ok {
+				missing[c.Name]++
+			}
+		}
+	}
+	for _, k := range sortedKeys(bad) {
+		if n := badRows[k] - 1; n > 0 {
+			l.add("%s (and %d more row(s))", bad[k], n)
+		} else {
+			l.add("%s", bad[k])
+		}
+	}
+	for _, k := range sortedKeys(unknown) {
+		l.add("column %s does not exist in %s", k, t.Name)
+	}
+	for _, c := range sortedKeys(missing) {
+		l.add("column %s.%s is NOT NULL without a default but missing in %d of %d row(s)", t.Name, c, missing[c], len(rows))
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func columnType(t *schema.Table, name string) string {
+	if c := columnOf(t, name); c != nil {
+		return c.Type
+	}
+	return ""
+}
+
+// lintValue checks ref: shortcuts and $ref objects; it returns a problem or "".
+func (l *linter) lintValue(v any) string {
+	var spec refSpec
+	switch x := v.(type) {
+	case string:
+		if !strings.HasPrefix(x, "ref:") {
+			return ""
+		}
+		var ok bool
+		if spec, ok = parseRefShortcut(x); !ok {
+			return fmt.Sprintf("invalid ref shortcut %q", x)
+		}
+	case map[string]any:
+		inner, isRef := x["$ref"].(map[string]any)
+		if !isRef {
+			return ""
+		}
+		spec.Table, _ = inner["table"].(string)
+		spec.Select, _ = inner["select"].(string)
+		if spec.Select == "" {
+			spec.Select = "id"
+		}
+		spec.Where, _ = inner["where"].(map[string]any)
+		if inner["default"] != nil {
+			spec.Default = inner["default"]
+		}
+		if req, ok := inner["required"].(bool); ok {
+			spec.Required = &req
+		}
+	default:
+		return ""
+	}
+
+	t := l.model.Table(spec.Table)
+	if t == nil {
+		return fmt.Sprintf("ref: table %s does not exist", spec.Table)
+	}
+	if columnOf(t, spec.Select) == nil {
+		return fmt.Sprintf("ref: column %s.%s does not exist", t.Name, spec.Select)
+	}
+	for _, k := range sortedKeys(spec.Where) {
+		if columnOf(t, k) == nil {
+			return fmt.Sprintf("ref: column %s.%s does not exist", t.Name, k)
+		}
+	}
+	if !l.lookups || l.written[strings.ToLower(t.Name)] || len(spec.Where) == 0 {
+		return ""
+	}
+	for _, w := range spec.Where {
+		if _, nested := w.(map[string]any); nested {
+			return ""
+		}
+	}
+	_, found, err := fetchRefValue(l.db, spec)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("ref: %v", err)
+	case !found && spec.Default == nil && (spec.Required == nil || *spec.Required):
+		return fmt.Sprintf("ref: no row in %s where %v", t.Name, spec.Where)
+	}
+	return ""
+}
+
+// lintTimeLayouts are the date/time spellings the databases accept, with the
+// offsets postgres prints ("+03", "+05:30"). Fractional seconds parse with any
+// of them.
+var lintTimeLayouts = []string{
+	time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04:05-07",
+	"2006-01-02 15:04:05", "2006-01-02 15:04:05Z07:00", "2006-01-02 15:04:05-07", "2006-01-02 15:04:05 -0700",
+	"2006-01-02", "15:04:05", "15:04:05Z07:00", "15:04:05-07",
+}
+
+// literalMismatch reports a literal that the column type cannot take.
+func literalMismatch(typ string, v any) string {
+	s, isString := v.(string)
+	if isString && (strings.HasPrefix(s, "ref:") || strings.HasPrefix(s, "@")) {
+		return ""
+	}
+	t := strings.ToLower(typ)
+	switch {
+	case v == nil:
+	case isBoolType(t):
+		switch x := v.(type) {
+		case bool, int, int64:
+		case string:
+			if _, err := strconv.ParseBool(strings.TrimSpace(x)); err != nil {
+				return fmt.Sprintf("%q is not a boolean", x)
+			}
+		default:
+			return fmt.Sprintf("%v is not a boolean", v)
+		}
+	case isIntType(t) && !strings.Contains(t, "interval") && !strings.Contains(t, "point"):
+		switch x := v.(type) {
+		case int, int64, uint64, bool:
+		case float64:
+			if x != float64(int64(x)) {
+				return fmt.Sprintf("%v is not an integer", x)
+			}
+		case string:
+			if _, err := strconv.ParseInt(strings.TrimSpace(x), 10, 64); err != nil {
+				return fmt.Sprintf("%q is not an integer", x)
+			}
+		default:
+			return fmt.Sprintf("%v is not an integer", v)
+		}
+	case strings.Contains(t, "float") || strings.Contains(t, "double") || strings.Contains(t, "real") ||
+		strings.Contains(t, "numeric") || strings.Contains(t, "decimal"):
+		switch x := v.(type) {
+		case int, int64, uint64, float64:
+		case string:
+			if _, err := strconv.ParseFloat(strings.TrimSpace(x), 64); err != nil {
+				return fmt.Sprintf("%q is not a number", x)
+			}
+		default:
+			return fmt.Sprintf("%v is not a number", v)
+		}
+	case isDateType(t):
+		switch x := v.(type) {
+		case time.Time:
+		case string:
+			for _, layout := range lintTimeLayouts {
+				if _, err := time.Parse(layout, strings.TrimSpace(x)); err == nil {
+					return ""
+				}
+			}
+			return fmt.Sprintf("%q is not a date/time", x)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package seeders
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintSeeds(t *testing.T) {
+	db := openTestDB(t, "",
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL, age INTEGER, born DATE, active BOOLEAN NOT NULL DEFAULT 1)`,
+		`CREATE TABLE posts (id INTEGER PRIMARY KEY, author_id INTEGER NOT NULL REFERENCES users(id), title TEXT NOT NULL)`,
+		`CREATE TABLE roles (id INTEGER PRIMARY KEY, name TEXT)`,
+	)
+
+	fixture := func(name, table string, rows ...map[string]any) seedEntry {
+		return seedEntry{file: "seeds.yaml", seed: YAMLSeed{Name: name, Type: "fixture", Table: table, Rows: rows}}
+	}
+	good := fixture("good", "users",
+		map[string]any{"email": "a@example.com", "age": 30, "born": "1990-05-01", "role_id": nil},
+		map[string]any{"email": "tz@example.com", "born": "2024-01-01 10:00:00+03"},
+		map[string]any{"email": "tz2@example.com", "born": "2024-01-01T10:00:00.5+05:30"},
+	)
+	delete(good.seed.Rows[0], "role_id")
+	good.seed.Children = []YAMLChild{{Table: "posts", Template: map[string]any{"title": "fake:sentence"}}}
+
+	bad := fixture("bad", "users",
+		map[string]any{"email": "b@example.com", "age": "abc", "nickname": "b"},
+		map[string]any{"age": "x", "born": "yesterday", "active": "maybe"},
+		map[string]any{"email": "ref:users|email=a@example.com|idd"},
+		map[string]any{"email": "c@example.com", "age": "ref:roles|name=admin|id"},
+	)
+	bad.seed.OnConflict, bad.seed.ConflictKey = "update_all", []string{"login"}
+	children := fixture("children", "users", map[string]any{"email": "d@example.com"})
+	children.seed.Children = []YAMLChild{{Table: "posts"}}
+
+	issues, err := lintSeeds(db, []seedEntry{good, bad, children, fixture("missing", "nope", map[string]any{"a": 1})}, true)
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	var got []string
+	for _, is := range issues {
+		got = append(got, is.String())
+	}
+	text := strings.Join(got, "\n")
+	for _, want := range []string{
+		`seeds.yaml: seed "bad": conflict_key column login does not exist in users`,
+		`seed "bad": row 1: age (INTEGER): "abc" is not an integer (and 1 more row(s))`,
+		`seed "bad": row 2: born (DATE): "yesterday" is not a date/time`,
+		`seed "bad": row 2: active (BOOLEAN): "maybe" is not a boolean`,
+		`seed "bad": row 3: email: ref: column users.idd does not exist`,
+		`seed "bad": row 4: age: ref: no row in roles where map[name:admin]`,
+		`seed "bad": column nickname does not exist in users`,
+		`seed "bad": column users.email is NOT NULL without a default but missing in 1 of 4 row(s)`,
+		`seed "children": column posts.title is NOT NULL without a default but missing in 1 of 1 row(s)`,
+		`seed "missing": table nope does not exist`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing issue %q in:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, `"good"`) || len(got) != 10 {
+		t.Errorf("unexpected issues (%d):\n%s", len(got), text)
+	}
+}
@@ -0,0 +1,227 @@
+package seeders
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// seedEntry is a seed together with the file it was read from.
+type seedEntry struct {
+	seed  YAMLSeed
+	dir   string // directory of the file, for relative sql file: paths
+	file  string // file name, for messages
+	batch *int   // batch: of the file, if set
+}
+
+// ApplyOptions select the seeds to run and how.
+type ApplyOptions struct {
+	// Env is the current environment. Seeds with an env: list only run when
+	// it is listed; with no environment they do not run at all.
+	Env string
+	// Tags, when set, limits the run to seeds with at least one of these tags.
+	Tags []string
+	// Only, when set, limits the run to these seed names.
+	Only []string
+	// RandomSeed, when set, makes fake values reproducible for every seed,
+	// overriding random_seed: in the files.
+	RandomSeed *int64
+	// FakeNow, when set, is the date reproducible fake values count from
+	// (YYYY-MM-DD or RFC 3339), overriding fake_now: in the files.
+	FakeNow string
+	// SkipLint turns off the schema check of the planned seeds that runs
+	// before anything is inserted.
+	SkipLint bool
+}
+
+// collectSeeds loads every seed of the YAML files in dir, in file order, gives
+// unnamed seeds their file#NNN name and adds the dependencies implied by
+// @alias references.
+func collectSeeds(dir string) ([]seedEntry, error) {
+	files, err := listYAML(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []seedEntry
+	where := map[string]string{}
+	for _, path := range files {
+		cfg, single, err := loadConfig(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		base := filepath.Base(path)
+
+		var seeds []YAMLSeed
+		var batch *int
+		var randomSeed *int64
+		var fakeNow string
+		switch {
+		case cfg != nil && len(cfg.Seeds) > 0:
+			seeds, batch, randomSeed, fakeNow = cfg.Seeds, cfg.Batch, cfg.RandomSeed, cfg.FakeNow
+		case single != nil:
+			seeds = []YAMLSeed{*single}
+		default:
+			continue
+		}
+		for i, s := range seeds {
+			if strings.TrimSpace(s.Name) == "" {
+				s.Name = fmt.Sprintf("%s#%03d", base, i+1)
+			}
+			if s.RandomSeed == nil {
+				s.RandomSeed = randomSeed
+			}
+			if s.FakeNow == "" {
+				s.FakeNow = fakeNow
+			}
+			if prev, dup := where[s.Name]; dup {
+				return nil, fmt.Errorf("seed %q is defined in both %s and %s", s.Name, prev, base)
+			}
+			where[s.Name] = base
+			out = append(out, seedEntry{seed: s, dir: filepath.Dir(path), file: base, batch: batch})
+		}
+	}
+	linkAliasDeps(out)
+	return out, nil
+}
+
+// enabledFor reports whether the seed may run in env.
+func (s YAMLSeed) enabledFor(env string) bool {
+	if len(s.Env) == 0 {
+		return true
+	}
+	for _, e := range s.Env {
+		if env != "" && strings.EqualFold(strings.TrimSpace(e), env) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s YAMLSeed) hasAnyTag(tags []string) bool {
+	for _, want := range tags {
+		for _, t := range s.Tags {
+			if strings.EqualFold(strings.TrimSpace(t), strings.TrimSpace(want)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// planSeeds picks the pending seeds matching f and orders them so that every
+// seed runs after its depends_on. Pending dependencies of a selected seed are
+// pulled in even when the tag or name filter left them out; the environment
+// filter always applies. skipped counts the pending seeds disabled for f.Env.
+func planSeeds(all []seedEntry, done map[string]bool, f ApplyOptions) (plan []seedEntry, skipped int, err error) {
+	if err := checkSeedDeps(all); err != nil {
+		return nil, 0, err
+	}
+	byName := seedIndex(all)
+
+	only := map[string]bool{}
+	for _, n := range f.Only {
+		if n = strings.TrimSpace(n); n != "" {
+			only[n] = true
+		}
+	}
+	selected := func(s YAMLSeed) bool {
+		if len(only) > 0 && !only[s.Name] {
+			return false
+		}
+		return len(f.Tags) == 0 || s.hasAnyTag(f.Tags)
+	}
+
+	// Depth-first in file order: dependencies are emitted before dependents,
+	// and otherwise the file order is kept.
+	state := make([]int, len(all)) // 0 new, 1 emitted
+	var visit func(i int, dependent string) error
+	visit = func(i int, dependent string) error {
+		e := all[i]
+		if state[i] == 1 || done[e.seed.Name] {
+			return nil
+		}
+		if !e.seed.enabledFor(f.Env) {
+			if dependent != "" {
+				return fmt.Errorf("seed %q depends on %q, which is not enabled for env %q", dependent, e.seed.Name, f.Env)
+			}
+			return nil
+		}
+		state[i] = 1
+		for _, dep := range e.seed.DependsOn {
+			if err := visit(byName[strings.TrimSpace(dep)], e.seed.Name); err != nil {
+				return err
+			}
+		}
+		plan = append(plan, e)
+		return nil
+	}
+	for i, e := range all {
+		if done[e.seed.Name] || !selected(e.seed) {
+			continue
+		}
+		if !e.seed.enabledFor(f.Env) {
+			skipped++
+			continue
+		}
+		if err := visit(i, ""); err != nil {
+			return nil, 0, err
+		}
+	}
+	return plan, skipped, nil
+}
+
+func seedIndex(all []seedEntry) map[string]int {
+	byName := make(map[string]int, len(all))
+	for i, e := range all {
+		byName[e.seed.Name] = i
+	}
+	return byName
+}
+
+// checkSeedDeps reports depends_on entries naming unknown seeds and the first
+// dependency cycle, e.g. "a -> b -> a".
+func checkSeedDeps(all []seedEntry) error {
+	byName := seedIndex(all)
+	for _, e := range all {
+		for _, dep := range e.seed.DependsOn {
+			if _, ok := byName[strings.TrimSpace(dep)]; !ok {
+				return fmt.Errorf("seed %q (%s) depends on unknown seed %q", e.seed.Name, e.file, dep)
+			}
+		}
+	}
+
+	state := make([]int, len(all)) // 0 new, 1 on the stack, 2 done
+	var stack []string
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case 1:
+			start := 0
+			for j, n := range stack {
+				if n == all[i].seed.Name {
+					start = j
+				}
+			}
+			cycle := append(append([]string{}, stack[start:]...), all[i].seed.Name)
+			return fmt.Errorf("seed dependency cycle: %s", strings.Join(cycle, " -> "))
+		case 2:
+			return nil
+		}
+		state[i] = 1
+		stack = append(stack, all[i].seed.Name)
+		for _, dep := range all[i].seed.DependsOn {
+			if err := visit(byName[strings.TrimSpace(dep)]); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[i] = 2
+		return nil
+	}
+	for i := range all {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package seeders
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func planNames(plan []seedEntry) string {
+	names := make([]string, len(plan))
+	for i, e := range plan {
+		names[i] = e.seed.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func TestPlanSeeds(t *testing.T) {
+	t.Parallel()
+
+	all := []seedEntry{
+		{seed: YAMLSeed{Name: "posts", DependsOn: []string{"users"}, Tags: []string{"demo"}}},
+		{seed: YAMLSeed{Name: "roles"}},
+		{seed: YAMLSeed{Name: "users", DependsOn: []string{"roles"}}},
+		{seed: YAMLSeed{Name: "demo_users", Env: []string{"dev", "test"}, Tags: []string{"demo"}}},
+	}
+
+	cases := []struct {
+		name    string
+		done    map[string]bool
+		filter  ApplyOptions
+		want    string
+		skipped int
+	}{
+		{name: "dependencies first", filter: ApplyOptions{Env: "dev"}, want: "roles,users,posts,demo_users"},
+		{name: "no env skips env seeds", want: "roles,users,posts", skipped: 1},
+		{name: "other env", filter: ApplyOptions{Env: "production"}, want: "roles,users,posts", skipped: 1},
+		{name: "tag pulls in dependencies", filter: ApplyOptions{Env: "test", Tags: []string{"demo"}}, want: "roles,users,posts,demo_users"},
+		{name: "done seeds are satisfied", done: map[string]bool{"roles": true, "users": true}, filter: ApplyOptions{Tags: []string{"demo"}}, want: "posts", skipped: 1},
+		{name: "only", filter: ApplyOptions{Only: []string{"users"}}, want: "roles,users"},
+	}
+	for _, tc := range cases {
+		plan, skipped, err := planSeeds(all, tc.done, tc.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got := planNames(plan); got != tc.want || skipped != tc.skipped {
+			t.Errorf("%s: plan = %s (skipped %d), want %s (skipped %d)", tc.name, got, skipped, tc.want, tc.skipped)
+		}
+	}
+
+	// A dependency disabled for the environment cannot be satisfied.
+	envDep := append(all, seedEntry{seed: YAMLSeed{Name: "comments", DependsOn: []string{"demo_users"}}})
+	if _, _, err := planSeeds(envDep, nil, ApplyOptions{Env: "production"}); err == nil || !strings.Contains(err.Error(), "demo_users") {
+		t.Fatalf("expected an env dependency error, got %v", err)
+	}
+
+	cyclic := []seedEntry{
+		{seed: YAMLSeed{Name: "a", DependsOn: []string{"b"}}},
+		{seed: YAMLSeed{Name: "b", DependsOn: []string{"c"}}},
+		{seed: YAMLSeed{Name: "c", DependsOn: []string{"b"}}},
+	}
+	if _, _, err := planSeeds(cyclic, nil, ApplyOptions{}); err == nil || !strings.Contains(err.Error(), "b -> c -> b") {
+		t.Fatalf("expected a cycle error, got %v", err)
+	}
+	unknown := []seedEntry{{seed: YAMLSeed{Name: "a", DependsOn: []string{"missing"}}, file: "a.yaml"}}
+	if _, _, err := planSeeds(unknown, nil, ApplyOptions{}); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("expected an unknown dependency error, got %v", err)
+	}
+}
+
+func TestCollectSeedsAcrossFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("1_posts.yaml", "name: posts\ntype: sql\nsql: SELECT 1\ndepends_on: [users]\n")
+	write("2_users.yaml", "batch: 7\nrandom_seed: 5\nseeds:\n  - name: users\n    type: sql\n    sql: SELECT 1\n  - type: sql\n    sql: SELECT 2\n    env: [dev]\n")
+
+	all, err := collectSeeds(dir)
+	if err != nil {
+		t.Fatalf("collectSeeds: %v", err)
+	}
+	if got := planNames(all); got != "posts,users,2_users.yaml#002" {
+		t.Fatalf("seeds = %s", got)
+	}
+	if all[1].batch == nil || *all[1].batch != 7 || all[0].batch != nil {
+		t.Fatalf("file batch not kept: %+v", all)
+	}
+	if all[2].seed.RandomSeed == nil || *all[2].seed.RandomSeed != 5 || all[0].seed.RandomSeed != nil {
+		t.Fatalf("file random_seed not inherited: %+v", all)
+	}
+	plan, _, err := planSeeds(all, nil, ApplyOptions{Env: "dev"})
+	if err != nil {
+		t.Fatalf("planSeeds: %v", err)
+	}
+	if got := planNames(plan); got != "users,posts,2_users.yaml#002" {
+		t.Fatalf("plan = %s", got)
+	}
+
+	write("3_dup.yaml", "name: users\ntype: sql\nsql: SELECT 3\n")
+	if _, err := collectSeeds(dir); err == nil || !strings.Contains(err.Error(), "defined in both") {
+		t.Fatalf("expected a duplicate name error, got %v", err)
+	}
+}
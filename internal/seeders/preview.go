@@ -0,0 +1,391 @@
+package seeders
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Preview formats of seed up --dry-run.
+const (
+	PreviewTable = "table"
+	PreviewJSON  = "json"
+	PreviewSQL   = "sql"
+)
+
+// previewCell bounds the width of a table cell.
+const previewCell = 48
+
+// seedPreview is what one pending seed would do.
+type seedPreview struct {
+	Batch int    `json:"batch"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	File  string `json:"file"`
+
+	Table    string           `json:"table,omitempty"`
+	Rows     []map[string]any `json:"rows,omitempty"`
+	Total    int              `json:"total_rows,omitempty"` // rows before the limit
+	Children []string         `json:"children,omitempty"`
+	SQL      string           `json:"sql,omitempty"`
+	Func     string           `json:"func,omitempty"`
+	Notes    []string         `json:"notes,omitempty"`
+
+	inserts []string // INSERT statements of Rows
+}
+
+func (p *seedPreview) note(format string, args ...any) {
+	p.Notes = append(p.Notes, fmt.Sprintf(format, args...))
+}
+
+// Preview writes what Apply would do with f to w, without writing to db:
+// the pending seeds per batch, fixture rows after fake values, ref: lookups
+// and password hashing, and the SQL of sql seeds. limit caps the rows shown
+// per fixture (0 shows all).
+//
+// @alias and ref: values that point at rows a pending seed has yet to insert
+// cannot be resolved; they are shown as written, with a note.
+func Preview(db *gorm.DB, w io.Writer, f ApplyOptions, format string, limit int) error {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "":
+		format = PreviewTable
+	case PreviewTable, PreviewJSON, PreviewSQL:
+	default:
+		return fmt.Errorf("unknown --format %q (use: table, json, sql)", format)
+	}
+	p, err := planApply(db, f)
+	if err != nil {
+		return err
+	}
+	if p.found == 0 {
+		fmt.Fprintln(w, "No seed yaml files found.")
+		return nil
+	}
+
+	previews := make([]*seedPreview, 0, len(p.seeds))
+	for _, e := range p.seeds {
+		sp, err := previewSeed(db, e, p.batchOf(e), limit)
+		if err != nil {
+			return fmt.Errorf("seed %q (%s): %w", e.seed.Name, e.file, err)
+		}
+		previews = append(previews, sp)
+	}
+
+	switch format {
+	case PreviewJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(previews); err != nil {
+			return err
+		}
+	case PreviewSQL:
+		writeSQLPreview(w, previews)
+	default:
+		writeTablePreview(w, previews)
+	}
+
+	// The note must not break the output: a comment in SQL, stderr otherwise.
+	if msg := p.skippedNote(f); msg != "" {
+		if format == PreviewSQL {
+			fmt.Fprintf(w, "-- %s\n", msg)
+		} else {
+			fmt.Fprintln(os.Stderr, msg)
+		}
+	}
+	return nil
+}
+
+func previewSeed(db *gorm.DB, e seedEntry, batch, limit int) (*seedPreview, error) {
+	s := e.seed
+	p := &seedPreview{Batch: batch, Name: s.Name, Type: strings.ToLower(strings.TrimSpace(s.Type)), File: e.file}
+	switch p.Type {
+	case "sql":
+		body, err := sqlBody(e.dir, s)
+		if err != nil {
+			return nil, err
+		}
+		p.SQL = strings.TrimSpace(body)
+	case "go":
+		p.Func = s.Func
+		p.note("go seeds are not previewed")
+	case "fixture":
+		if err := previewFixture(db, e, limit, p); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown type %q", s.Type)
+	}
+	return p, nil
+}
+
+// previewFixture expands the rows of a fixture the way runFixture and
+// runFixtureFile do, up to their insert, and renders the INSERT statements.
+func previewFixture(db *gorm.DB, e seedEntry, limit int, p *seedPreview) error {
+	s := e.seed
+	if s.Table == "" {
+		return errors.New("fixture seed: table is required")
+	}
+	p.Table = s.Table
+
+	var rows []map[string]any
+	var err error
+	if s.File != "" {
+		rows, p.Total, err = previewFileRows(db, e.dir, s, limit)
+		if err != nil {
+			return err
+		}
+	} else {
+		if rows, err = expandFixtureRows(s); err != nil {
+			return fmt.Errorf("expand fixture rows: %w", err)
+		}
+		names, err := takeAliases(rows)
+		if err != nil {
+			return err
+		}
+		p.Total = len(rows)
+		if limit > 0 && len(rows) > limit {
+			rows = rows[:limit]
+		}
+
+		fields, cost := passwordSettings(s)
+		aliases := newAliasSet(db, e.seed, names)
+		if !db.Migrator().HasTable(&Seed{}) {
+			aliases.known, aliases.owner = map[string]*insertedRow{}, map[string]string{}
+		}
+		for i, row := range rows {
+			for k, v := range row {
+				if r, err := aliases.resolve(v); err != nil {
+					p.note("row %d: %s: %v", i+1, k, err)
+				} else {
+					row[k] = r
+				}
+				if r, wasRef, err := resolveAnyRef(db, row[k]); wasRef && err != nil {
+					p.note("row %d: %s: %v", i+1, k, err)
+				} else if wasRef {
+					row[k] = r
+				}
+			}
+			if err := hashPasswordFieldsIfPresent(row, fields, cost); err != nil {
+				return fmt.Errorf("hash password failed: %w", err)
+			}
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := normalizeRows(db, s.Table, rows); err != nil {
+		return fmt.Errorf("normalize rows failed: %w", err)
+	}
+
+	chunk := s.ChunkSize
+	if chunk <= 0 {
+		chunk = defaultChunkSize
+	}
+	dry := db.Session(&gorm.Session{DryRun: true, NewDB: true})
+	for i := 0; i < len(rows); i += chunk {
+		stmt := dry.Table(s.Table).Clauses(conflictClauses(s, rows)...).Create(rows[i:min(i+chunk, len(rows))]).Statement
+		if stmt.Error != nil {
+			return stmt.Error
+		}
+		p.inserts = append(p.inserts, explainSQL(db, stmt.SQL.String(), stmt.Vars)+";")
+	}
+
+	p.Rows = make([]map[string]any, len(rows))
+	for i, row := range rows {
+		shown := make(map[string]any, len(row))
+		for k, v := range row {
+			shown[k] = bulkValue(v)
+		}
+		p.Rows[i] = shown
+	}
+	p.Children = childSummary(s.Children, "")
+	return nil
+}
+
+// explainSQL inlines vars into sql. The sqlite dialector quotes strings with
+// double quotes, which sqlite reads as identifiers first.
+func explainSQL(db *gorm.DB, sql string, vars []any) string {
+	if db.Dialector.Name() == "sqlite" {
+		return logger.ExplainSQL(sql, nil, "'", vars...)
+	}
+	return db.Dialector.Explain(sql, vars...)
+}
+
+// previewFileRows reads the data file of a fixture, returning up to limit
+// rows and the number of rows in the file.
+func previewFileRows(db *gorm.DB, baseDir string, s YAMLSeed, limit int) ([]map[string]any, int, error) {
+	fh, src, err := openFixtureFile(baseDir, s)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer fh.Close()
+	var types map[string]string
+	if _, isCSV := src.(*csvSource); isCSV {
+		if types, err = columnTypes(db, s.Table); err != nil {
+			return nil, 0, err
+		}
+	}
+	var rows []map[string]any
+	n := 0
+	for ; ; n++ {
+		row, err := src.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: row %d: %w", s.File, n+1, err)
+		}
+		if limit > 0 && n >= limit {
+			continue
+		}
+		if types != nil {
+			if err := coerceCSV(row, types); err != nil {
+				return nil, 0, fmt.Errorf("%s: row %d: %w", s.File, n+1, err)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, n, nil
+}
+
+// childSummary describes the children factories, e.g. "posts: 1-2 per row".
+func childSummary(children []YAMLChild, parent string) []string {
+	var out []string
+	for _, ch := range children {
+		path := ch.Table
+		if parent != "" {
+			path = parent + " > " + ch.Table
+		}
+		count := "1"
+		if c := ch.Count; c != nil && c.Min == c.Max {
+			count = fmt.Sprint(c.Min)
+		} else if c != nil {
+			count = fmt.Sprintf("%d-%d", c.Min, c.Max)
+		}
+		out = append(out, fmt.Sprintf("%s: %s per row", path, count))
+		out = append(out, childSummary(ch.Children, path)...)
+	}
+	return out
+}
+
+func writeTablePreview(w io.Writer, previews []*seedPreview) {
+	if len(previews) == 0 {
+		fmt.Fprintln(w, "No pending seeds.")
+		return
+	}
+	batch := 0
+	for _, p := range previews {
+		if p.Batch != batch {
+			batch = p.Batch
+			fmt.Fprintf(w, "Batch %d\n\n", batch)
+		}
+		switch p.Type {
+		case "fixture":
+			fmt.Fprintf(w, "== %s (fixture into %s, %d row(s)) [%s]\n", p.Name, p.Table, p.Total, p.File)
+			if len(p.Rows) > 0 {
+				writeRowTable(w, p.Rows)
+			}
+			if more := p.Total - len(p.Rows); more > 0 {
+				fmt.Fprintf(w, "... %d more row(s)\n", more)
+			}
+			for _, c := range p.Children {
+				fmt.Fprintf(w, "children: %s\n", c)
+			}
+		case "sql":
+			fmt.Fprintf(w, "== %s (sql) [%s]\n%s\n", p.Name, p.File, p.SQL)
+		default:
+			fmt.Fprintf(w, "== %s (%s %s) [%s]\n", p.Name, p.Type, p.Func, p.File)
+		}
+		for _, n := range p.Notes {
+			fmt.Fprintf(w, "note: %s\n", n)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%d seed(s) pending (dry run, nothing was written).\n", len(previews))
+}
+
+func writeRowTable(w io.Writer, rows []map[string]any) {
+	cols := rowColumns(rows)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(cols, "\t"))
+	seps := make([]string, len(cols))
+	for i, c := range cols {
+		seps[i] = strings.Repeat("-", len(c))
+	}
+	fmt.Fprintln(tw, strings.Join(seps, "\t"))
+	cells := make([]string, len(cols))
+	for _, row := range rows {
+		for i, c := range cols {
+			v, ok := row[c]
+			if !ok {
+				cells[i] = ""
+				continue
+			}
+			cells[i] = previewValue(v)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	tw.Flush()
+}
+
+// previewValue renders v on one line of a table cell.
+func previewValue(v any) string {
+	var s string
+	switch x := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		s = fmt.Sprintf("<%d bytes>", len(x))
+	case time.Time:
+		s = x.Format(time.RFC3339)
+	case map[string]any, []any:
+		raw, _ := json.Marshal(x)
+		s = string(raw)
+	default:
+		s = fmt.Sprint(x)
+	}
+	s = strings.NewReplacer("\n", `\n`, "\t", " ").Replace(s)
+	if utf8.RuneCountInString(s) > previewCell {
+		s = string([]rune(s)[:previewCell-3]) + "..."
+	}
+	return s
+}
+
+func writeSQLPreview(w io.Writer, previews []*seedPreview) {
+	fmt.Fprintln(w, "-- dry run, nothing was written")
+	batch := 0
+	for _, p := range previews {
+		if p.Batch != batch {
+			batch = p.Batch
+			fmt.Fprintf(w, "\n-- batch %d\n", batch)
+		}
+		fmt.Fprintf(w, "\n-- %s (%s) [%s]\n", p.Name, p.Type, p.File)
+		for _, n := range p.Notes {
+			fmt.Fprintf(w, "-- note: %s\n", n)
+		}
+		switch p.Type {
+		case "fixture":
+			for _, stmt := range p.inserts {
+				fmt.Fprintln(w, stmt)
+			}
+			if more := p.Total - len(p.Rows); more > 0 {
+				fmt.Fprintf(w, "-- ... %d more row(s)\n", more)
+			}
+			for _, c := range p.Children {
+				fmt.Fprintf(w, "-- children: %s\n", c)
+			}
+		case "sql":
+			fmt.Fprintln(w, p.SQL)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package seeders
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPreviewSeeds(t *testing.T) {
+	db := openTestDB(t, "",
+		`CREATE TABLE roles (id INTEGER PRIMARY KEY, name TEXT)`,
+		`INSERT INTO roles (id, name) VALUES (7, 'admin')`,
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT UNIQUE, role_id INTEGER, password TEXT, manager_id INTEGER)`,
+	)
+
+	users := seedEntry{file: "users.yaml", seed: YAMLSeed{
+		Name: "users", Type: "fixture", Table: "users", OnConflict: "do_nothing", PasswordCost: 4,
+		Rows: []map[string]any{
+			{"_alias": "boss", "email": "boss@example.com", "role_id": "ref:roles|name=admin|id", "password": "secret"},
+			{"email": "x@example.com", "role_id": "ref:roles|name=editor|id", "manager_id": "@boss.id"},
+			{"email": "y@example.com"},
+		},
+		Children: []YAMLChild{{Table: "posts", Count: &CountRange{Min: 1, Max: 3}}},
+	}}
+	roles := seedEntry{file: "roles.yaml", seed: YAMLSeed{Name: "roles", Type: "sql", SQL: "INSERT INTO roles (name) VALUES ('editor');\n"}}
+
+	var previews []*seedPreview
+	for _, e := range []seedEntry{roles, users} {
+		p, err := previewSeed(db, e, 3, 2)
+		if err != nil {
+			t.Fatalf("%s: %v", e.seed.Name, err)
+		}
+		previews = append(previews, p)
+	}
+	p := previews[1]
+	if p.Total != 3 || len(p.Rows) != 2 {
+		t.Fatalf("rows = %d of %d", len(p.Rows), p.Total)
+	}
+	if p.Rows[0]["role_id"] != int64(7) || !strings.HasPrefix(p.Rows[0]["password"].(string), "$2a$04$") {
+		t.Errorf("row 1 = %v", p.Rows[0])
+	}
+	if p.Rows[1]["manager_id"] != "@boss.id" || len(p.Notes) != 2 {
+		t.Errorf("row 2 = %v, notes = %q", p.Rows[1], p.Notes)
+	}
+
+	var table, sql bytes.Buffer
+	writeTablePreview(&table, previews)
+	writeSQLPreview(&sql, previews)
+	for _, want := range []string{
+		"Batch 3",
+		"== users (fixture into users, 3 row(s)) [users.yaml]",
+		"boss@example.com",
+		"... 1 more row(s)",
+		"children: posts: 1-3 per row",
+		"note: row 2: role_id: ref not found (roles) where map[name:editor]",
+		"INSERT INTO roles (name) VALUES ('editor');",
+	} {
+		if !strings.Contains(table.String(), want) {
+			t.Errorf("table output misses %q:\n%s", want, table.String())
+		}
+	}
+	for _, want := range []string{
+		"-- batch 3",
+		"INSERT INTO `users` (`email`,`manager_id`,`password`,`role_id`) VALUES ('boss@example.com',NULL,'$2a$04$",
+		"ON CONFLICT DO NOTHING;",
+		"-- ... 1 more row(s)",
+	} {
+		if !strings.Contains(sql.String(), want) {
+			t.Errorf("sql output misses %q:\n%s", want, sql.String())
+		}
+	}
+
+	var n int64
+	db.Table("users").Count(&n)
+	if n != 0 || db.Migrator().HasTable(&Seed{}) {
+		t.Errorf("dry run wrote to the database: %d users", n)
+	}
+}
@@ -0,0 +1,491 @@
+package seeders
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"forge/internal/schema"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// deleteChunk bounds the number of keys per DELETE statement.
+const deleteChunk = 500
+
+// undoLog is stored with each executed seed and tells rollback how to remove
+// what the seed inserted.
+type undoLog struct {
+	// SQL is the seed's explicit down: statement; it replaces Tables.
+	SQL string `json:"sql,omitempty"`
+	// Tables lists the inserted primary keys, in insertion order.
+	Tables []undoTable `json:"tables,omitempty"`
+	// Irreversible explains why the seed cannot be rolled back.
+	Irreversible string `json:"irreversible,omitempty"`
+}
+
+type undoTable struct {
+	Table   string   `json:"table"`
+	Columns []string `json:"columns"`
+	Keys    [][]any  `json:"keys"`
+	// Ranges holds runs of integer keys, inclusive, so that large imports keep
+	// the log small.
+	Ranges [][2]int64 `json:"ranges,omitempty"`
+}
+
+func (u undoLog) encode() string {
+	b, _ := json.Marshal(u)
+	return string(b)
+}
+
+func decodeUndo(s string) (undoLog, error) {
+	var u undoLog
+	if strings.TrimSpace(s) == "" {
+		u.Irreversible = "it ran before forge recorded seeded rows"
+		return u, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.UseNumber()
+	if err := dec.Decode(&u); err != nil {
+		return u, fmt.Errorf("invalid undo log: %w", err)
+	}
+	return u, nil
+}
+
+// rows counts the recorded keys.
+func (u undoLog) rows() int {
+	n := 0
+	for _, t := range u.Tables {
+		n += len(t.Keys)
+		for _, r := range t.Ranges {
+			n += int(r[1] - r[0] + 1)
+		}
+	}
+	return n
+}
+
+// keyTracker finds out which rows a fixture inserted into a table. Keys given
+// in the rows count when they did not exist before; a single integer primary
+// key assigned by the database is found by looking above the previous maximum.
+// Any other key the database fills in cannot be found again, so such rows make
+// the seed irreversible.
+type keyTracker struct {
+	table    string
+	cols     []string
+	auto     bool
+	maxKey   sql.NullInt64
+	explicit [][]any
+	unkeyed  bool
+}
+
+// trackInserts prepares a keyTracker before rows are inserted into table. The
+// tracker is nil when the table has no primary key.
+func trackInserts(tx *gorm.DB, table string) (*keyTracker, error) {
+	cols, auto, err := primaryKey(tx, table)
+	if err != nil || len(cols) == 0 {
+		return nil, err
+	}
+	k := &keyTracker{table: table, cols: cols, auto: auto}
+	if k.auto {
+		q := schema.Quoter(tx.Dialector.Name())
+		if err := tx.Raw(fmt.Sprintf("SELECT MAX(%s) FROM %s", q(k.cols[0]), q(table))).Row().Scan(&k.maxKey); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// expect records the keys given in rows that are about to be inserted and do
+// not exist yet.
+func (k *keyTracker) expect(tx *gorm.DB, rows []map[string]any) error {
+	var given [][]any
+	for _, r := range rows {
+		if key, ok := rowKey(r, k.cols); ok {
+			given = append(given, key)
+		} else if !k.auto {
+			k.unkeyed = true
+		}
+	}
+	existing, err := k.existingKeys(tx, given)
+	if err != nil {
+		return err
+	}
+	for _, key := range given {
+		if !existing[keyString(key)] {
+			k.explicit = append(k.explicit, key)
+		}
+	}
+	return nil
+}
+
+// primaryKey returns the primary key columns of table and whether the key is
+// a single integer column, which the database may assign.
+func primaryKey(tx *gorm.DB, table string) ([]string, bool, error) {
+	types, err := tx.Migrator().ColumnTypes(table)
+	if err != nil {
+		return nil, false, err
+	}
+	var cols []string
+	var pkType string
+	for _, ct := range types {
+		if pk, ok := ct.PrimaryKey(); ok && pk {
+			cols = append(cols, ct.Name())
+			pkType = ct.DatabaseTypeName()
+		}
+	}
+	return cols, len(cols) == 1 && isIntType(pkType), nil
+}
+
+// collect returns the keys inserted since trackInserts.
+func (k *keyTracker) collect(tx *gorm.DB) (undoTable, error) {
+	out := undoTable{Table: k.table, Columns: k.cols}
+	seen := map[string]bool{}
+	add := func(key []any) {
+		if s := keyString(key); !seen[s] {
+			seen[s] = true
+			out.Keys = append(out.Keys, key)
+		}
+	}
+	if k.auto {
+		q := schema.Quoter(tx.Dialector.Name())
+		query := tx.Table(k.table).Order(q(k.cols[0]))
+		if k.maxKey.Valid {
+			query = query.Where(fmt.Sprintf("%s > ?", q(k.cols[0])), k.maxKey.Int64)
+		}
+		var ids []int64
+		if err := query.Pluck(k.cols[0], &ids).Error; err != nil {
+			return out, err
+		}
+		for _, id := range ids {
+			add([]any{id})
+		}
+	}
+	for _, key := range k.explicit {
+		add(key)
+	}
+	return out.compact(), nil
+}
+
+// compact turns integer keys of a single column into ranges.
+func (t undoTable) compact() undoTable {
+	if len(t.Columns) != 1 || len(t.Keys) == 0 {
+		return t
+	}
+	ids := make([]int64, len(t.Keys))
+	for i, key := range t.Keys {
+		id, ok := intKey(key[0])
+		if !ok {
+			return t
+		}
+		ids[i] = id
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	t.Keys = nil
+	for _, id := range ids {
+		if n := len(t.Ranges); n > 0 && t.Ranges[n-1][1]+1 >= id {
+			t.Ranges[n-1][1] = id
+			continue
+		}
+		t.Ranges = append(t.Ranges, [2]int64{id, id})
+	}
+	return t
+}
+
+func intKey(v any) (int64, bool) {
+	switch x := v.(type) {
+	case int:
+		return int64(x), true
+	case int32:
+		return int64(x), true
+	case int64:
+		return x, true
+	case uint:
+		return int64(x), true
+	case uint32:
+		return int64(x), true
+	case uint64:
+		return int64(x), true
+	case json.Number:
+		i, err := x.Int64()
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// insertLog tracks the keys a fixture inserts into each table it writes to. A
+// nil log tracks nothing.
+type insertLog struct {
+	tables   []string
+	trackers map[string]*keyTracker
+}
+
+func newInsertLog() *insertLog {
+	return &insertLog{trackers: map[string]*keyTracker{}}
+}
+
+// expect is called before rows are inserted into table.
+func (l *insertLog) expect(tx *gorm.DB, table string, rows []map[string]any) error {
+	if l == nil {
+		return nil
+	}
+	k, seen := l.trackers[table]
+	if !seen {
+		var err error
+		if k, err = trackInserts(tx, table); err != nil {
+			return err
+		}
+		l.trackers[table] = k
+		l.tables = append(l.tables, table)
+	}
+	if k == nil {
+		return nil
+	}
+	return k.expect(tx, rows)
+}
+
+// undo returns the undo log of the inserts, tables in first-insert order.
+func (l *insertLog) undo(tx *gorm.DB) (undoLog, error) {
+	var u undoLog
+	for _, table := range l.tables {
+		k := l.trackers[table]
+		if k == nil {
+			u.Irreversible = fmt.Sprintf("table %s has no primary key", table)
+			u.Tables = nil
+			return u, nil
+		}
+		if k.unkeyed {
+			u.Irreversible = fmt.Sprintf("rows were inserted into %s without their primary key (%s)", table, strings.Join(k.cols, ", "))
+			u.Tables = nil
+			return u, nil
+		}
+		inserted, err := k.collect(tx)
+		if err != nil {
+			return u, err
+		}
+		u.Tables = append(u.Tables, inserted)
+	}
+	return u, nil
+}
+
+// existingKeys reports which of keys are already present in the table.
+func (k *keyTracker) existingKeys(tx *gorm.DB, keys [][]any) (map[string]bool, error) {
+	out := map[string]bool{}
+	q := schema.Quoter(tx.Dialector.Name())
+	cols := quoteCols(q, k.cols)
+	for i := 0; i < len(keys); i += deleteChunk {
+		chunk := keys[i:min(i+deleteChunk, len(keys))]
+		where, args := keyCondition(q, k.cols, chunk)
+		rows, err := tx.Raw(fmt.Sprintf("SELECT %s FROM %s WHERE %s", cols, q(k.table), where), args...).Rows()
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			vals := make([]any, len(k.cols))
+			ptrs := make([]any, len(vals))
+			for j := range vals {
+				ptrs[j] = &vals[j]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			out[keyString(vals)] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// rowKey extracts the primary key of a fixture row when every column is given
+// as a plain value.
+func rowKey(row map[string]any, cols []string) ([]any, bool) {
+	key := make([]any, len(cols))
+	for i, c := range cols {
+		v, ok := row[c]
+		if !ok || v == nil {
+			return nil, false
+		}
+		if _, isExpr := v.(clause.Expr); isExpr {
+			return nil, false
+		}
+		key[i] = v
+	}
+	return key, true
+}
+
+// keyString makes keys comparable across the representations drivers and
+// YAML use (int vs int64 vs []byte vs json.Number).
+func keyString(key []any) string {
+	parts := make([]string, len(key))
+	for i, v := range key {
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// keyCondition renders a WHERE condition matching any of keys.
+func keyCondition(q func(string) string, cols []string, keys [][]any) (string, []any) {
+	var args []any
+	if len(cols) == 1 {
+		marks := make([]string, len(keys))
+		for i, key := range keys {
+			marks[i] = "?"
+			args = append(args, keyValue(key[0]))
+		}
+		return fmt.Sprintf("%s IN (%s)", q(cols[0]), strings.Join(marks, ", ")), args
+	}
+	conds := make([]string, len(keys))
+	for i, key := range keys {
+		parts := make([]string, len(cols))
+		for j, c := range cols {
+			parts[j] = q(c) + " = ?"
+			args = append(args, keyValue(key[j]))
+		}
+		conds[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+	return strings.Join(conds, " OR "), args
+}
+
+// keyValue turns a key read back from the undo log into a bind value.
+func keyValue(v any) any {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return n.String()
+}
+
+func quoteCols(q func(string) string, cols []string) string {
+	out := make([]string, len(cols))
+	for i, c := range cols {
+		out[i] = q(c)
+	}
+	return strings.Join(out, ", ")
+}
+
+// Rollback removes the data of the last steps seed batches, newest first, and
+// forgets that those seeds ran.
+func Rollback(db *gorm.DB, steps int) error {
+	if err := ensureTable(db); err != nil {
+		return err
+	}
+	if steps <= 0 {
+		steps = 1
+	}
+	for s := 0; s < steps; s++ {
+		batch, err := lastSeedBatch(db)
+		if err != nil {
+			return err
+		}
+		if batch == 0 {
+			if s == 0 {
+				fmt.Println("No seeders to roll back.")
+			}
+			return nil
+		}
+		if err := rollbackSeedBatch(db, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RollbackAll removes the data of every executed seed.
+func RollbackAll(db *gorm.DB) error {
+	if err := ensureTable(db); err != nil {
+		return err
+	}
+	for {
+		batch, err := lastSeedBatch(db)
+		if err != nil {
+			return err
+		}
+		if batch == 0 {
+			return nil
+		}
+		if err := rollbackSeedBatch(db, batch); err != nil {
+			return err
+		}
+	}
+}
+
+func lastSeedBatch(db *gorm.DB) (int, error) {
+	var batch int
+	if err := db.Model(&Seed{}).Select("COALESCE(MAX(batch), 0)").Scan(&batch).Error; err != nil {
+		return 0, fmt.Errorf("failed to get last seed batch: %w", err)
+	}
+	return batch, nil
+}
+
+// rollbackSeedBatch undoes the seeds of one batch in reverse order, in a
+// single transaction. Nothing is changed when one of them is irreversible.
+func rollbackSeedBatch(db *gorm.DB, batch int) error {
+	var seeds []Seed
+	if err := db.Where("batch = ?", batch).Order("id DESC").Find(&seeds).Error; err != nil {
+		return err
+	}
+	logs := make([]undoLog, len(seeds))
+	for i, s := range seeds {
+		u, err := decodeUndo(s.Undo)
+		if err != nil {
+			return fmt.Errorf("seed %q: %w", s.Name, err)
+		}
+		if u.Irreversible != "" {
+			return fmt.Errorf("seed %q (batch %d) cannot be rolled back: %s; add a down: statement or clear its status with `forge seed reset`",
+				s.Name, batch, u.Irreversible)
+		}
+		logs[i] = u
+	}
+
+	q := schema.Quoter(db.Dialector.Name())
+	return db.Transaction(func(tx *gorm.DB) error {
+		for i, s := range seeds {
+			u := logs[i]
+			if u.SQL != "" {
+				if err := tx.Exec(u.SQL).Error; err != nil {
+					return fmt.Errorf("seed %q: down: %w", s.Name, err)
+				}
+			}
+			for j := len(u.Tables) - 1; j >= 0; j-- {
+				t := u.Tables[j]
+				for k := 0; k < len(t.Keys); k += deleteChunk {
+					where, args := keyCondition(q, t.Columns, t.Keys[k:min(k+deleteChunk, len(t.Keys))])
+					if err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s", q(t.Table), where), args...).Error; err != nil {
+						return fmt.Errorf("seed %q: delete from %s: %w", s.Name, t.Table, err)
+					}
+				}
+				for _, r := range t.Ranges {
+					if err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s BETWEEN ? AND ?", q(t.Table), q(t.Columns[0])), r[0], r[1]).Error; err != nil {
+						return fmt.Errorf("seed %q: delete from %s: %w", s.Name, t.Table, err)
+					}
+				}
+			}
+			if err := tx.Unscoped().Delete(&s).Error; err != nil {
+				return err
+			}
+			if u.SQL != "" {
+				fmt.Printf("Rolled back seed %s (down SQL)\n", s.Name)
+			} else {
+				fmt.Printf("Rolled back seed %s (%d rows)\n", s.Name, u.rows())
+			}
+		}
+		return nil
+	})
+}
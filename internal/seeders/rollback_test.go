@@ -0,0 +1,114 @@
+package seeders
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm/schema"
+)
+
+func TestSeedUndoIsLongTextOnMySQL(t *testing.T) {
+	s, err := schema.Parse(&Seed{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := mysql.Dialector{Config: &mysql.Config{}}
+	for _, name := range []string{"Undo", "Aliases"} {
+		if got := d.DataTypeOf(s.LookUpField(name)); got != "longtext" {
+			t.Fatalf("%s on mysql = %s, want longtext (text stops at 64 KB)", name, got)
+		}
+	}
+}
+
+func TestRollbackRemovesSeededRows(t *testing.T) {
+	db := openSeedDB(t,
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT UNIQUE, name TEXT)`,
+		`CREATE TABLE roles (name TEXT)`,
+		`INSERT INTO users (id, email, name) VALUES (1, 'keep@example.com', 'Existing')`,
+	)
+
+	// Batch 1: an upsert touching the existing row plus one row with an explicit
+	// id and two with database-assigned ids.
+	if err := runSeed(db, ".", YAMLSeed{
+		Name: "users", Type: "fixture", Table: "users",
+		OnConflict: "update_all", ConflictKey: []string{"id"},
+		Rows: []map[string]any{
+			{"id": 1, "email": "keep@example.com", "name": "Updated"},
+			{"id": 10, "email": "ten@example.com", "name": "Ten"},
+		},
+	}, 1); err != nil {
+		t.Fatalf("users seed: %v", err)
+	}
+	if err := runSeed(db, ".", YAMLSeed{
+		Name: "more_users", Type: "fixture", Table: "users",
+		Rows: []map[string]any{{"email": "a@example.com"}, {"email": "b@example.com"}},
+	}, 1); err != nil {
+		t.Fatalf("more_users seed: %v", err)
+	}
+	// Batch 2: sql seeds, one with a down: statement.
+	if err := runSeed(db, ".", YAMLSeed{
+		Name: "roles", Type: "sql",
+		SQL:  `INSERT INTO roles (name) VALUES ('admin')`,
+		Down: `DELETE FROM roles WHERE name = 'admin'`,
+	}, 2); err != nil {
+		t.Fatalf("roles seed: %v", err)
+	}
+
+	count := func(table string) int64 {
+		var n int64
+		db.Table(table).Count(&n)
+		return n
+	}
+	if count("users") != 4 || count("roles") != 1 {
+		t.Fatalf("seeded users=%d roles=%d", count("users"), count("roles"))
+	}
+
+	if err := Rollback(db, 1); err != nil {
+		t.Fatalf("rollback batch 2: %v", err)
+	}
+	if count("roles") != 0 || count("users") != 4 {
+		t.Fatalf("after step 1: users=%d roles=%d", count("users"), count("roles"))
+	}
+
+	if err := Rollback(db, 1); err != nil {
+		t.Fatalf("rollback batch 1: %v", err)
+	}
+	var emails []string
+	db.Table("users").Order("id").Pluck("email", &emails)
+	if strings.Join(emails, ",") != "keep@example.com" {
+		t.Fatalf("users left = %v, want only the pre-existing row", emails)
+	}
+	if count("seeds") != 0 {
+		t.Fatalf("seed records left: %d", count("seeds"))
+	}
+
+	// A seed without a down: statement blocks its whole batch.
+	if err := runSeed(db, ".", YAMLSeed{Name: "roles_again", Type: "sql", SQL: `INSERT INTO roles (name) VALUES ('x')`}, 3); err != nil {
+		t.Fatalf("roles_again seed: %v", err)
+	}
+	if err := runSeed(db, ".", YAMLSeed{Name: "more", Type: "fixture", Table: "users", Rows: []map[string]any{{"email": "c@example.com"}}}, 3); err != nil {
+		t.Fatalf("more seed: %v", err)
+	}
+	err := Rollback(db, 1)
+	if err == nil || !strings.Contains(err.Error(), "roles_again") {
+		t.Fatalf("expected an irreversible seed error, got %v", err)
+	}
+	if count("users") != 2 || count("seeds") != 2 {
+		t.Fatalf("a refused rollback changed data: users=%d seeds=%d", count("users"), count("seeds"))
+	}
+
+	// A key the database fills in that is not an auto integer cannot be
+	// found again.
+	if err := db.Exec(`CREATE TABLE tokens (code TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(8)))), name TEXT)`).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := runSeed(db, ".", YAMLSeed{Name: "tokens", Type: "fixture", Table: "tokens", Rows: []map[string]any{{"name": "t"}}}, 4); err != nil {
+		t.Fatalf("tokens seed: %v", err)
+	}
+	err = Rollback(db, 1)
+	if err == nil || !strings.Contains(err.Error(), "without their primary key") {
+		t.Fatalf("expected a generated key to make the seed irreversible, got %v", err)
+	}
+}
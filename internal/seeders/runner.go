@@ -9,6 +9,10 @@ import (
 	"strings"
 	"time"
 
+	"forge/internal/config"
+	"forge/internal/database"
+	"forge/internal/schema"
+
 	"gopkg.in/yaml.v3"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -34,119 +38,113 @@ func executedMap(db *gorm.DB) (map[string]bool, int, error) {
 
 // API
 func ApplyAll(db *gorm.DB) error {
+	return Apply(db, ApplyOptions{})
+}
+
+func ApplyOnly(db *gorm.DB, names []string) error {
+	return Apply(db, ApplyOptions{Only: names})
+}
+
+// Apply runs the pending seeds selected by f in dependency order.
+func Apply(db *gorm.DB, f ApplyOptions) error {
 	if err := ensureTable(db); err != nil {
 		return err
 	}
-
-	files, err := listYAML(seedsDir)
+	p, err := planApply(db, f)
 	if err != nil {
 		return err
 	}
-	if len(files) == 0 {
+	if p.found == 0 {
 		fmt.Println("No seed yaml files found.")
 		return nil
 	}
 
-	done, last, err := executedMap(db)
-	if err != nil {
-		return err
-	}
-	defaultBatch := last + 1
-
-	for _, path := range files {
-		cfg, single, err := loadConfig(path)
-		if err != nil {
-			return fmt.Errorf("%s: %w", path, err)
-		}
-		base := filepath.Base(path)
-
-		fileBatch := defaultBatch
-		if cfg != nil && cfg.Batch != nil {
-			fileBatch = *cfg.Batch
-		}
-
-		var seeds []YAMLSeed
-		switch {
-		case cfg != nil && len(cfg.Seeds) > 0:
-			seeds = cfg.Seeds
-		case single != nil:
-			seeds = []YAMLSeed{*single}
-		default:
-			continue
-		}
-
-		for i := range seeds {
-			if strings.TrimSpace(seeds[i].Name) == "" {
-				seeds[i].Name = fmt.Sprintf("%s#%03d", base, i+1)
-			}
-		}
-
-		for _, s := range seeds {
-			if done[s.Name] {
-				continue
-			}
-			if err := runSeed(db, filepath.Dir(path), s, fileBatch); err != nil {
-				return fmt.Errorf("seed %q (%s): %w", s.Name, base, err)
-			}
+	for _, e := range p.seeds {
+		if err := runSeed(db, e.dir, e.seed, p.batchOf(e)); err != nil {
+			return fmt.Errorf("seed %q (%s): %w", e.seed.Name, e.file, err)
 		}
 	}
-	fmt.Println("YAML seeds applied.")
+	if msg := p.skippedNote(f); msg != "" {
+		fmt.Println(msg)
+	}
+	if len(f.Only) == 0 {
+		fmt.Println("YAML seeds applied.")
+	}
 	return nil
 }
 
-func ApplyOnly(db *gorm.DB, names []string) error {
-	if err := ensureTable(db); err != nil {
-		return err
-	}
-	files, err := listYAML(seedsDir)
+// applyPlan is what seed up is about to run.
+type applyPlan struct {
+	found   int // seeds in the files
+	seeds   []seedEntry
+	next    int // batch of seeds without batch:
+	skipped int // left out by env:
+}
+
+// planApply selects the pending seeds for f and checks them against the
+// schema unless f.SkipLint is set. It only reads from db.
+func planApply(db *gorm.DB, f ApplyOptions) (applyPlan, error) {
+	all, err := collectSeeds(seedsDir)
 	if err != nil {
-		return err
-	}
-	want := map[string]bool{}
-	for _, n := range names {
-		want[strings.TrimSpace(n)] = true
+		return applyPlan{}, err
 	}
-	done, last, err := executedMap(db)
-	if err != nil {
-		return err
+	p := applyPlan{found: len(all), next: 1}
+	if len(all) == 0 {
+		return p, nil
 	}
-	batch := last + 1
 
-	for _, path := range files {
-		cfg, single, err := loadConfig(path)
-		if err != nil {
-			return fmt.Errorf("%s: %w", path, err)
+	done := map[string]bool{}
+	if db.Migrator().HasTable(&Seed{}) {
+		var last int
+		if done, last, err = executedMap(db); err != nil {
+			return p, err
 		}
-		base := filepath.Base(path)
-
-		var seeds []YAMLSeed
-		switch {
-		case cfg != nil && len(cfg.Seeds) > 0:
-			seeds = cfg.Seeds
-		case single != nil:
-			seeds = []YAMLSeed{*single}
-		default:
-			continue
+		p.next = last + 1
+	}
+	if p.seeds, p.skipped, err = planSeeds(all, done, f); err != nil {
+		return p, err
+	}
+	for i := range p.seeds {
+		if f.RandomSeed != nil {
+			p.seeds[i].seed.RandomSeed = f.RandomSeed
 		}
-		for i := range seeds {
-			if strings.TrimSpace(seeds[i].Name) == "" {
-				seeds[i].Name = fmt.Sprintf("%s#%03d", base, i+1)
-			}
+		if f.FakeNow != "" {
+			p.seeds[i].seed.FakeNow = f.FakeNow
 		}
-		for _, s := range seeds {
-			if !want[s.Name] || done[s.Name] {
-				continue
-			}
-			useBatch := batch
-			if cfg != nil && cfg.Batch != nil {
-				useBatch = *cfg.Batch
-			}
-			if err := runSeed(db, filepath.Dir(path), s, useBatch); err != nil {
-				return fmt.Errorf("seed %q (%s): %w", s.Name, base, err)
+	}
+	if !f.SkipLint && len(p.seeds) > 0 {
+		issues, err := lintSeeds(db, p.seeds, false)
+		if err != nil {
+			return p, err
+		}
+		if len(issues) > 0 {
+			lines := make([]string, len(issues))
+			for i, is := range issues {
+				lines[i] = "  " + is.String()
 			}
+			return p, fmt.Errorf("seed check failed, nothing was inserted (see forge seed lint, or pass --no-lint):\n%s", strings.Join(lines, "\n"))
 		}
 	}
-	return nil
+	return p, nil
+}
+
+func (p applyPlan) batchOf(e seedEntry) int {
+	if e.batch != nil {
+		return *e.batch
+	}
+	return p.next
+}
+
+// skippedNote tells how many seeds env: left out, or is empty if none were.
+func (p applyPlan) skippedNote(f ApplyOptions) string {
+	if p.skipped == 0 {
+		return ""
+	}
+	env := f.Env
+	if env == "" {
+		env = "unset, see " + config.ForgeEnvKey
+	}
+	return fmt.Sprintf("Skipped %d seed(s) not enabled for env (%s).", p.skipped, env)
 }
 
 func Status(db *gorm.DB) error {
@@ -174,6 +172,43 @@ func Reset(db *gorm.DB) error {
 	return db.Exec("DELETE FROM seeds").Error
 }
 
+// CheckFiles parses every YAML seed file and returns the number of seeds found
+// plus one error per file that cannot be loaded. A missing seeds directory is
+// not an error.
+func CheckFiles() (int, []error) {
+	files, err := listYAML(seedsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, []error{err}
+	}
+	count := 0
+	var errs []error
+	for _, path := range files {
+		cfg, single, err := loadConfig(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		if cfg != nil {
+			count += len(cfg.Seeds)
+		} else if single != nil {
+			count++
+		}
+	}
+	if len(errs) == 0 {
+		all, err := collectSeeds(seedsDir)
+		if err == nil {
+			err = checkSeedDeps(all)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return count, errs
+}
+
 // загрузка YAML
 func listYAML(dir string) ([]string, error) {
 	ents, err := os.ReadDir(dir)
@@ -217,6 +252,9 @@ func runSeed(db *gorm.DB, baseDir string, s YAMLSeed, batch int) error {
 	case "sql":
 		return runSQL(db, baseDir, s, batch)
 	case "fixture":
+		if s.File != "" {
+			return runFixtureFile(db, baseDir, s, batch)
+		}
 		return runFixture(db, s, batch)
 	case "go":
 		return runGo(db, s, batch)
@@ -226,20 +264,9 @@ func runSeed(db *gorm.DB, baseDir string, s YAMLSeed, batch int) error {
 }
 
 func runSQL(db *gorm.DB, baseDir string, s YAMLSeed, batch int) error {
-	sqlText := strings.TrimSpace(s.SQL)
-	if s.File != "" {
-		full := s.File
-		if !filepath.IsAbs(full) {
-			full = filepath.Join(baseDir, s.File)
-		}
-		raw, err := os.ReadFile(full)
-		if err != nil {
-			return err
-		}
-		sqlText = string(raw)
-	}
-	if sqlText == "" {
-		return errors.New("sql seed: empty SQL")
+	sqlText, err := sqlBody(baseDir, s)
+	if err != nil {
+		return err
 	}
 
 	tx := db.Begin()
@@ -250,13 +277,33 @@ func runSQL(db *gorm.DB, baseDir string, s YAMLSeed, batch int) error {
 		tx.Rollback()
 		return err
 	}
-	if err := tx.Create(&Seed{Name: s.Name, Batch: batch, RanAt: time.Now()}).Error; err != nil {
+	if err := tx.Create(&Seed{Name: s.Name, Batch: batch, RanAt: time.Now(), Undo: downUndo(s).encode()}).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 	return tx.Commit().Error
 }
 
+// sqlBody returns the SQL of a sql seed, from sql: or its file.
+func sqlBody(baseDir string, s YAMLSeed) (string, error) {
+	sqlText := strings.TrimSpace(s.SQL)
+	if s.File != "" {
+		full := s.File
+		if !filepath.IsAbs(full) {
+			full = filepath.Join(baseDir, s.File)
+		}
+		raw, err := os.ReadFile(full)
+		if err != nil {
+			return "", err
+		}
+		sqlText = string(raw)
+	}
+	if sqlText == "" {
+		return "", errors.New("sql seed: empty SQL")
+	}
+	return sqlText, nil
+}
+
 func runFixture(db *gorm.DB, s YAMLSeed, batch int) error {
 	if s.Table == "" {
 		return errors.New("fixture seed: table is required")
@@ -266,28 +313,164 @@ func runFixture(db *gorm.DB, s YAMLSeed, batch int) error {
 		return fmt.Errorf("expand fixture rows: %w", err)
 	}
 	if len(rows) == 0 {
-		return db.Create(&Seed{Name: s.Name, Batch: batch, RanAt: time.Now()}).Error
+		return db.Create(&Seed{Name: s.Name, Batch: batch, RanAt: time.Now(), Undo: undoLog{SQL: s.Down}.encode()}).Error
+	}
+	names, err := takeAliases(rows)
+	if err != nil {
+		return err
+	}
+	groups, err := aliasGroups(rows, names)
+	if err != nil {
+		return err
 	}
 
 	chunk := s.ChunkSize
 	if chunk <= 0 {
 		chunk = defaultChunkSize
 	}
+	fields, cost := passwordSettings(s)
 
-	tx := db.Begin()
-	if tx.Error != nil {
-		return tx.Error
+	tx, conn, err := beginFixture(db, s)
+	if err != nil {
+		return err
+	}
+	if conn != nil {
+		defer conn.Close()
+	}
+	fail := func(err error) error {
+		tx.Rollback()
+		return err
+	}
+
+	if err := prepareConflict(tx, s); err != nil {
+		return fail(err)
+	}
+	writer, err := newRowWriter(tx, conn, s, chunk)
+	if err != nil {
+		return fail(err)
+	}
+	fail = func(err error) error {
+		writer.done(false)
+		tx.Rollback()
+		return err
+	}
+
+	// запоминаем, что уже есть в таблицах, чтобы seed rollback удалил только новые строки
+	var inserts *insertLog
+	if s.Down == "" {
+		inserts = newInsertLog()
 	}
 
-	// 1) $ref (включая вложенные в where)
-	for i := range rows {
-		if err := resolveRowRefs(tx, rows[i]); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("resolve $ref failed: %w", err)
+	// Строки, ссылающиеся на _alias других строк этой же фикстуры, вставляются
+	// следующими порциями, когда ключи тех строк уже известны.
+	aliases := newAliasSet(tx, s, names)
+	parents := make([]*insertedRow, len(rows))
+	for _, group := range groups {
+		part := make([]map[string]any, len(group))
+		for j, i := range group {
+			part[j] = rows[i]
+
+			// 1) @alias.column, затем $ref (включая вложенные в where)
+			if err := aliases.resolveRow(rows[i]); err != nil {
+				return fail(fmt.Errorf("row %d: %w", i+1, err))
+			}
+			if err := resolveRowRefs(tx, rows[i]); err != nil {
+				return fail(fmt.Errorf("resolve $ref failed: %w", err))
+			}
+			// 2) bcrypt
+			if err := hashPasswordFieldsIfPresent(rows[i], fields, cost); err != nil {
+				return fail(fmt.Errorf("hash password failed: %w", err))
+			}
+		}
+
+		// 3) нормализация под драйвер/типы колонок
+		if err := normalizeRows(tx, s.Table, part); err != nil {
+			return fail(fmt.Errorf("normalize rows failed: %w", err))
+		}
+		if err := inserts.expect(tx, s.Table, part); err != nil {
+			return fail(fmt.Errorf("track inserted rows failed: %w", err))
+		}
+
+		// 4) вставка / апсерт; строки с _alias и родители children по одной,
+		// чтобы прочитать их ключ
+		pending := 0
+		for j, i := range group {
+			if names[i] == "" && len(s.Children) == 0 {
+				continue
+			}
+			if err := writer.write(part[pending:j]); err != nil {
+				return fail(err)
+			}
+			affected, err := insertFixtureRows(tx, s, part[j:j+1], 1)
+			if err != nil {
+				return fail(err)
+			}
+			inserted, err := readInserted(tx, s, rows[i], affected)
+			if err != nil {
+				if names[i] != "" {
+					err = fmt.Errorf("alias %q: %w", names[i], err)
+				}
+				return fail(err)
+			}
+			if names[i] != "" {
+				if err := aliases.add(s, names[i], inserted); err != nil {
+					return fail(err)
+				}
+			}
+			parents[i] = inserted
+			pending = j + 1
+		}
+		if err := writer.write(part[pending:]); err != nil {
+			return fail(err)
 		}
 	}
+	if err := writer.done(true); err != nil {
+		return fail(err)
+	}
 
-	// 2) bcrypt
+	// 5) children: связанные строки для каждой строки фикстуры
+	if len(s.Children) > 0 {
+		model, err := schema.Introspect(tx)
+		if err != nil {
+			return fail(fmt.Errorf("children: %w", err))
+		}
+		c := &childFactory{tx: tx, seed: s, model: model, aliases: aliases, inserts: inserts, chunk: chunk, fields: fields, cost: cost}
+		if err := c.insert(s.Table, parents, s.Children, s.Table); err != nil {
+			return fail(err)
+		}
+	}
+
+	undo := undoLog{SQL: s.Down}
+	if inserts != nil {
+		if undo, err = inserts.undo(tx); err != nil {
+			return fail(fmt.Errorf("track inserted rows failed: %w", err))
+		}
+	}
+
+	if err := tx.Create(&Seed{Name: s.Name, Batch: batch, RanAt: time.Now(), Undo: undo.encode(), Aliases: encodeAliases(aliases.defined)}).Error; err != nil {
+		return fail(err)
+	}
+	return tx.Commit().Error
+}
+
+// prepareConflict checks the on_conflict settings of a fixture and creates the
+// unique index update_all relies on.
+func prepareConflict(tx *gorm.DB, s YAMLSeed) error {
+	if strings.ToLower(s.OnConflict) != "update_all" {
+		return nil
+	}
+	if len(s.ConflictKey) == 0 {
+		return errors.New("fixture seed: conflict_key is required for update_all")
+	}
+	// авто-UNIQUE-индекс (если нет)
+	if err := ensureUniqueForConflict(tx, s.Table, s.ConflictKey); err != nil {
+		return fmt.Errorf("ensure unique index for on_conflict failed: %w", err)
+	}
+	return nil
+}
+
+// passwordSettings returns the fields bcrypt-hashed in a fixture and the cost.
+func passwordSettings(s YAMLSeed) ([]string, int) {
 	fields := s.PasswordFields
 	if len(fields) == 0 {
 		fields = []string{"password"}
@@ -296,42 +479,36 @@ func runFixture(db *gorm.DB, s YAMLSeed, batch int) error {
 	if cost <= 0 {
 		cost = defaultBcryptCost
 	}
-	for i := range rows {
-		if err := hashPasswordFieldsIfPresent(rows[i], fields, cost); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("hash password failed: %w", err)
-		}
+	return fields, cost
+}
+
+// insertFixtureRows inserts rows in chunks according to the seed's
+// on_conflict mode and returns the number of affected rows.
+func insertFixtureRows(tx *gorm.DB, s YAMLSeed, rows []map[string]any, chunk int) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
 	}
+	conflict := conflictClauses(s, rows)
 
-	// 3) нормализация под драйвер/типы колонок
-	if err := normalizeRows(tx, s.Table, rows); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("normalize rows failed: %w", err)
+	var affected int64
+	for i := 0; i < len(rows); i += chunk {
+		end := min(endIndex(i, chunk, len(rows)), len(rows))
+		res := tx.Table(s.Table).Clauses(conflict...).Create(rows[i:end])
+		if res.Error != nil {
+			return affected, res.Error
+		}
+		affected += res.RowsAffected
 	}
+	return affected, nil
+}
 
-	// 4) вставка / апсерт (+ авто-индекс при update_all)
+// conflictClauses returns the ON CONFLICT clause of the seed for rows.
+func conflictClauses(s YAMLSeed, rows []map[string]any) []clause.Expression {
+	var conflict []clause.Expression
 	switch strings.ToLower(s.OnConflict) {
 	case "do_nothing":
-		for i := 0; i < len(rows); i += chunk {
-			end := min(endIndex(i, chunk, len(rows)), len(rows))
-			if err := tx.Table(s.Table).
-				Clauses(clause.OnConflict{DoNothing: true}).
-				Create(rows[i:end]).Error; err != nil {
-				tx.Rollback()
-				return err
-			}
-		}
+		conflict = append(conflict, clause.OnConflict{DoNothing: true})
 	case "update_all":
-		if len(s.ConflictKey) == 0 {
-			tx.Rollback()
-			return errors.New("fixture seed: conflict_key is required for update_all")
-		}
-		// авто-UNIQUE-индекс (если нет)
-		if err := ensureUniqueForConflict(tx, s.Table, s.ConflictKey); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("ensure unique index for on_conflict failed: %w", err)
-		}
-
 		cols := make([]clause.Column, 0, len(s.ConflictKey))
 		for _, k := range s.ConflictKey {
 			cols = append(cols, clause.Column{Name: k})
@@ -348,6 +525,7 @@ func runFixture(db *gorm.DB, s YAMLSeed, batch int) error {
 				}
 			}
 		}
+		sort.Strings(updateCols)
 
 		// AssignmentColumns генерит SQL под диалект (EXCLUDED.* на pg/sqlite, VALUES()/alias на mysql).
 		onConflict := clause.OnConflict{Columns: cols}
@@ -356,37 +534,23 @@ func runFixture(db *gorm.DB, s YAMLSeed, batch int) error {
 		} else {
 			onConflict.DoNothing = true
 		}
-
-		for i := 0; i < len(rows); i += chunk {
-			end := min(endIndex(i, chunk, len(rows)), len(rows))
-			if err := tx.Table(s.Table).
-				Clauses(onConflict).
-				Create(rows[i:end]).Error; err != nil {
-				tx.Rollback()
-				return err
-			}
-		}
-	default:
-		for i := 0; i < len(rows); i += chunk {
-			end := min(endIndex(i, chunk, len(rows)), len(rows))
-			if err := tx.Table(s.Table).Create(rows[i:end]).Error; err != nil {
-				tx.Rollback()
-				return err
-			}
-		}
+		conflict = append(conflict, onConflict)
 	}
+	return conflict
+}
 
-	if err := tx.Create(&Seed{Name: s.Name, Batch: batch, RanAt: time.Now()}).Error; err != nil {
-		tx.Rollback()
-		return err
+// downUndo is the undo log of sql and go seeds, which forge cannot track.
+func downUndo(s YAMLSeed) undoLog {
+	if strings.TrimSpace(s.Down) == "" {
+		return undoLog{Irreversible: fmt.Sprintf("%s seeds need a down: statement", strings.ToLower(s.Type))}
 	}
-	return tx.Commit().Error
+	return undoLog{SQL: s.Down}
 }
 
 func runGo(db *gorm.DB, s YAMLSeed, batch int) error {
 	fn := goFuncs[s.Func]
 	if fn == nil {
-		return fmt.Errorf("go seed: function %q is not registered", s.Func)
+		return runProjectGo(db, s, batch)
 	}
 	tx := db.Begin()
 	if tx.Error != nil {
@@ -396,13 +560,34 @@ func runGo(db *gorm.DB, s YAMLSeed, batch int) error {
 		tx.Rollback()
 		return err
 	}
-	if err := tx.Create(&Seed{Name: s.Name, Batch: batch, RanAt: time.Now()}).Error; err != nil {
+	if err := tx.Create(&Seed{Name: s.Name, Batch: batch, RanAt: time.Now(), Undo: downUndo(s).encode()}).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 	return tx.Commit().Error
 }
 
+// runProjectGo runs a function from the project's Go seeds and records the seed
+// once the helper has committed.
+func runProjectGo(db *gorm.DB, s YAMLSeed, batch int) error {
+	settings, err := config.CurrentSettings()
+	if err != nil {
+		return err
+	}
+	forgeDSN, err := database.SettingsDSN(settings)
+	if err != nil {
+		return err
+	}
+	driver, dsn, err := database.DriverDSN(forgeDSN)
+	if err != nil {
+		return err
+	}
+	if err := runProjectGoSeed(seedsDir, s.Func, driver, dsn); err != nil {
+		return err
+	}
+	return db.Create(&Seed{Name: s.Name, Batch: batch, RanAt: time.Now(), Undo: downUndo(s).encode()}).Error
+}
+
 // helpers
 func endIndex(i, chunk, total int) int {
 	e := i + chunk
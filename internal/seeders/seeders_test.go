@@ -3,10 +3,46 @@ package seeders
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
+// openTestDB opens a sqlite database and runs setup in it. An empty dsn gives
+// a shared in-memory database named after the test.
+func openTestDB(t *testing.T, dsn string, setup ...string) *gorm.DB {
+	t.Helper()
+	if dsn == "" {
+		dsn = "file:" + t.Name() + "?mode=memory&cache=shared"
+	}
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	for _, q := range setup {
+		if err := db.Exec(q).Error; err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+	return db
+}
+
+// openSeedDB is openTestDB with the seeds table in place.
+func openSeedDB(t *testing.T, setup ...string) *gorm.DB {
+	t.Helper()
+	db := openTestDB(t, "", setup...)
+	if err := ensureTable(db); err != nil {
+		t.Fatalf("ensure seeds table: %v", err)
+	}
+	return db
+}
+
 func TestExpandFixtureRowsFromTemplate(t *testing.T) {
 	t.Parallel()
 
@@ -42,6 +78,46 @@ func TestExpandFixtureRowsFromTemplate(t *testing.T) {
 	}
 }
 
+func TestExpandFixtureRowsWithRandomSeed(t *testing.T) {
+	t.Parallel()
+
+	seed := int64(42)
+	s := YAMLSeed{
+		Name:       "users",
+		Table:      "users",
+		Count:      5,
+		RandomSeed: &seed,
+		Template: map[string]any{
+			"name":       "fake:full_name",
+			"email":      "fake:email",
+			"uuid":       "fake:uuid",
+			"created_at": "fake:datetime",
+			"meta":       map[string]any{"age": "fake:int:18:65", "phone": "fake:phone"},
+		},
+	}
+	first, err := expandFixtureRows(s)
+	if err != nil {
+		t.Fatalf("expandFixtureRows: %v", err)
+	}
+	second, err := expandFixtureRows(s)
+	if err != nil {
+		t.Fatalf("expandFixtureRows: %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("same random seed gave different rows:\n%v\n%v", first, second)
+	}
+	if first[0]["uuid"] == first[1]["uuid"] {
+		t.Fatalf("rows of one seed should differ: %v", first[0]["uuid"])
+	}
+
+	other := s
+	other.Name = "admins"
+	third, _ := expandFixtureRows(other)
+	if reflect.DeepEqual(first, third) {
+		t.Fatal("seeds with different names should get different streams")
+	}
+}
+
 func TestCreateSeedFixtureTemplate(t *testing.T) {
 	originalWD, err := os.Getwd()
 	if err != nil {
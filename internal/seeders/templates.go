@@ -13,9 +13,54 @@ func CreateSeed(name, kind string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if strings.EqualFold(strings.TrimSpace(kind), "go") {
+		path, err := writeGoSeedStub(seedsDir, inferSeedTable(name), inferGoFuncName(name))
+		if err != nil {
+			return "", err
+		}
+		if path != "" {
+			fmt.Printf("Created %s\n", path)
+		}
+	}
 	return writeSeed(name, content)
 }
 
+// writeGoSeedStub adds the Go function of a go seed to dir as file.go, unless
+// a function of that name is already there. It returns the path of the new file.
+func writeGoSeedStub(dir, file, fn string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create seeds directory: %w", err)
+	}
+	pkg, funcs, err := goSeedFuncs(dir)
+	if err != nil {
+		return "", err
+	}
+	if strIn(funcs, fn) {
+		return "", nil
+	}
+	if pkg == "" {
+		pkg = "seeds"
+	}
+	path := filepath.Join(dir, file+".go")
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+	content := fmt.Sprintf(`package %s
+
+import "database/sql"
+
+// %s is run by forge seed up (type: go, func: %s) in its own transaction.
+func %s(tx *sql.Tx) error {
+	// _, err := tx.Exec("INSERT INTO ...")
+	return nil
+}
+`, pkg, fn, fn, fn)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return "", fmt.Errorf("write %s: %w", path, err)
+	}
+	return path, nil
+}
+
 // writeSeed writes seed YAML content to a timestamped file in the seeds dir.
 func writeSeed(name, content string) (string, error) {
 	if err := ensureSeedsDirectory(); err != nil {
@@ -54,6 +99,8 @@ template:
 type: sql
 sql: |
   -- write SQL here
+# down: |
+#   -- SQL that undoes the seed, run by forge seed rollback
 `, name), nil
 	case "go":
 		return fmt.Sprintf(`name: %s
@@ -14,12 +14,16 @@ const (
 	defaultBcryptCost = 12
 )
 
-// Учёт применённых сидов
+// Учёт применённых сидов. У Undo и Aliases намеренно нет type-тега: gorm
+// делает такую строку text в sqlite и postgres и longtext в mysql, где text
+// ограничен 64 КБ — мало для undo-лога большого сида.
 type Seed struct {
 	ID        uint   `gorm:"primaryKey"`
 	Name      string `gorm:"uniqueIndex;size:190"`
 	Batch     int    `gorm:"index"`
 	RanAt     time.Time
+	Undo      string // JSON: what the seed inserted, for seed rollback
+	Aliases   string // JSON: _alias -> primary key of the row
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
@@ -27,17 +31,30 @@ type Seed struct {
 
 // YAML формат (файл может быть списком seeds или одиночным сидом)
 type YAMLConfig struct {
-	Batch *int       `yaml:"batch,omitempty"`
-	Seeds []YAMLSeed `yaml:"seeds,omitempty"`
+	Batch      *int       `yaml:"batch,omitempty"`
+	RandomSeed *int64     `yaml:"random_seed,omitempty"` // для всех сидов файла
+	FakeNow    string     `yaml:"fake_now,omitempty"`    // для всех сидов файла
+	Seeds      []YAMLSeed `yaml:"seeds,omitempty"`
 }
 
 type YAMLSeed struct {
 	Name string `yaml:"name"`
 	Type string `yaml:"type"` // sql | fixture | go
 
+	// выбор и порядок
+	Env       []string `yaml:"env,omitempty"`        // только в этих окружениях (FORGE_ENV / --env)
+	Tags      []string `yaml:"tags,omitempty"`       // для seed up --tag
+	DependsOn []string `yaml:"depends_on,omitempty"` // имена сидов, которые должны выполниться раньше
+
+	// воспроизводимые fake-значения и их язык (en, ru, de); fake_now — "сейчас"
+	// для дат при random_seed (по умолчанию 2024-01-01)
+	RandomSeed *int64 `yaml:"random_seed,omitempty"`
+	FakeNow    string `yaml:"fake_now,omitempty"`
+	Locale     string `yaml:"locale,omitempty"`
+
 	// sql
 	SQL  string `yaml:"sql,omitempty"`
-	File string `yaml:"file,omitempty"`
+	File string `yaml:"file,omitempty"` // для fixture — файл с данными (csv, json, ndjson)
 
 	// fixture
 	Table       string           `yaml:"table,omitempty"`
@@ -47,6 +64,14 @@ type YAMLSeed struct {
 	OnConflict  string           `yaml:"on_conflict,omitempty"`  // "", "do_nothing", "update_all"
 	ConflictKey []string         `yaml:"conflict_key,omitempty"` // для update_all
 	ChunkSize   int              `yaml:"chunk_size,omitempty"`   // default 1000
+	Children    []YAMLChild      `yaml:"children,omitempty"`     // связанные строки для каждой строки
+	Bulk        bool             `yaml:"bulk,omitempty"`         // быстрая загрузка: COPY (pg) или подготовленные multi-row INSERT
+
+	// fixture из файла
+	Format    string            `yaml:"format,omitempty"`    // csv | tsv | json | ndjson; по умолчанию по расширению
+	Columns   map[string]string `yaml:"columns,omitempty"`   // заголовок/ключ файла -> колонка ("-" пропускает)
+	Null      []string          `yaml:"null,omitempty"`      // csv: значения, означающие NULL (по умолчанию пустая строка)
+	Delimiter string            `yaml:"delimiter,omitempty"` // csv: разделитель (по умолчанию ",")
 
 	// bcrypt
 	PasswordFields []string `yaml:"password_fields,omitempty"`
@@ -54,6 +79,21 @@ type YAMLSeed struct {
 
 	// go
 	Func string `yaml:"func,omitempty"`
+
+	// down: SQL run by seed rollback instead of deleting the recorded rows
+	Down string `yaml:"down,omitempty"`
+
+	// aliases the other seeds declare, set by linkAliasDeps
+	declared map[string]bool
+}
+
+// Дочерняя фабрика fixture: строки, создаваемые для каждой строки родителя
+type YAMLChild struct {
+	Table      string         `yaml:"table"`
+	Count      *CountRange    `yaml:"count,omitempty"` // 3 или "1-5"; по умолчанию 1
+	Template   map[string]any `yaml:"template,omitempty"`
+	ForeignKey string         `yaml:"foreign_key,omitempty"` // колонка-ссылка на родителя, если FK в схеме нет или их несколько
+	Children   []YAMLChild    `yaml:"children,omitempty"`
 }
 
 // Для type:go
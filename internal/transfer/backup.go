@@ -0,0 +1,306 @@
+// Package transfer moves data in and out of the configured database: portable
+// SQL backups and restores built on schema introspection.
+package transfer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"forge/internal/schema"
+
+	"gorm.io/gorm"
+)
+
+const defaultBatchSize = 500
+
+// BackupOptions tune Backup.
+type BackupOptions struct {
+	// BatchSize is the number of rows per INSERT statement (default 500).
+	BatchSize int
+}
+
+// Stats summarizes a backup.
+type Stats struct {
+	Tables int
+	Rows   int64
+}
+
+// Backup writes a portable SQL dump of the whole database — schema DDL followed
+// by the data of every table (Forge's bookkeeping tables included) as INSERT
+// batches. Rows are streamed, so tables of any size are dumped in constant
+// memory. Tables are written in foreign-key dependency order, rows in primary
+// key order. Triggers come last, so restoring the rows does not fire them
+// again. postgres cannot switch foreign keys off, so there the tables are
+// created without them and the keys are added after the data.
+func Backup(db *gorm.DB, w io.Writer, opts BackupOptions) (Stats, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	var st Stats
+
+	m, err := schema.Introspect(db)
+	if err != nil {
+		return st, err
+	}
+	m.Tables = schema.DependencyOrder(m.Tables)
+
+	dump := *m
+	dump.Triggers = nil
+	if m.Driver == "postgres" {
+		dump.Tables = withoutForeignKeys(m.Tables)
+	}
+	ddl, err := schema.DumpSQL(db, &dump)
+	if err != nil {
+		return st, err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "-- Forge backup\n-- driver: %s\n-- created: %s\n\n", m.Driver, time.Now().UTC().Format(time.RFC3339))
+	for _, stmt := range disableFKStatements(m.Driver) {
+		fmt.Fprintf(bw, "%s;\n", stmt)
+	}
+	bw.WriteString("\n")
+	bw.WriteString(strings.TrimSpace(ddl))
+	bw.WriteString("\n\n")
+
+	for _, t := range m.Tables {
+		n, err := dumpTableData(db, bw, m.Driver, t, opts.BatchSize)
+		if err != nil {
+			return st, fmt.Errorf("dump %s: %w", t.Name, err)
+		}
+		st.Tables++
+		st.Rows += n
+	}
+
+	for _, stmt := range resetSequenceStatements(m.Driver, m.Tables) {
+		fmt.Fprintf(bw, "%s;\n", stmt)
+	}
+	seqs, err := sequenceValueStatements(db, m)
+	if err != nil {
+		return st, err
+	}
+	for _, stmt := range seqs {
+		fmt.Fprintf(bw, "%s;\n", stmt)
+	}
+	for _, stmt := range addForeignKeyStatements(m.Driver, m.Tables) {
+		fmt.Fprintf(bw, "%s;\n", stmt)
+	}
+	// Plain statements, no DELIMITER: the restore splitter keeps BEGIN … END
+	// trigger bodies together.
+	for _, tr := range m.Triggers {
+		fmt.Fprintf(bw, "\n%s;\n", strings.TrimRight(strings.TrimSpace(tr.Definition), ";"))
+	}
+	for _, stmt := range enableFKStatements(m.Driver) {
+		fmt.Fprintf(bw, "%s;\n", stmt)
+	}
+	return st, bw.Flush()
+}
+
+// BackupToDir writes a timestamped backup file into dir and returns its path.
+func BackupToDir(db *gorm.DB, dir string) (string, Stats, error) {
+	path := defaultBackupPath(dir, db.Dialector.Name(), ".sql")
+	st, err := BackupToFile(db, path, BackupOptions{})
+	return path, st, err
+}
+
+// BackupToFile writes a backup to path. A partially written file is removed
+// when the backup fails.
+func BackupToFile(db *gorm.DB, path string, opts BackupOptions) (Stats, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return Stats{}, fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return Stats{}, fmt.Errorf("create %s: %w", path, err)
+	}
+	st, err := Backup(db, f, opts)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return st, err
+	}
+	return st, nil
+}
+
+func defaultBackupPath(dir, driver, ext string) string {
+	return filepath.Join(dir, time.Now().Format("20060102_150405")+"_"+driver+ext)
+}
+
+// dumpTableData streams the rows of one table as multi-row INSERT statements.
+func dumpTableData(db *gorm.DB, w *bufio.Writer, driver string, t schema.Table, batch int) (int64, error) {
+	q := schema.Quoter(driver)
+	query := "SELECT * FROM " + q(t.Name)
+	if len(t.PrimaryKey) > 0 {
+		// Parents before children when a table references itself.
+		query += " ORDER BY " + quoteNames(q, t.PrimaryKey)
+	}
+	rows, err := db.Raw(query).Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	head := fmt.Sprintf("INSERT INTO %s (%s) VALUES\n", q(t.Name), quoteNames(q, cols))
+
+	vals := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+
+	var total int64
+	inBatch := 0
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return total, err
+		}
+		if inBatch == 0 {
+			w.WriteString(head)
+		} else {
+			w.WriteString(",\n")
+		}
+		w.WriteString("  (")
+		for i, v := range vals {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			w.WriteString(sqlLiteral(driver, v, types[i].DatabaseTypeName()))
+		}
+		w.WriteString(")")
+		inBatch++
+		total++
+		if inBatch == batch {
+			w.WriteString(";\n")
+			inBatch = 0
+		}
+	}
+	if inBatch > 0 {
+		w.WriteString(";\n")
+	}
+	if total > 0 {
+		w.WriteString("\n")
+	}
+	return total, rows.Err()
+}
+
+func quoteNames(q func(string) string, names []string) string {
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = q(n)
+	}
+	return strings.Join(out, ", ")
+}
+
+func disableFKStatements(driver string) []string {
+	switch driver {
+	case "sqlite":
+		return []string{"PRAGMA foreign_keys = OFF"}
+	case "mysql":
+		return []string{"SET FOREIGN_KEY_CHECKS = 0"}
+	}
+	return nil
+}
+
+func enableFKStatements(driver string) []string {
+	switch driver {
+	case "sqlite":
+		return []string{"PRAGMA foreign_keys = ON"}
+	case "mysql":
+		return []string{"SET FOREIGN_KEY_CHECKS = 1"}
+	}
+	return nil
+}
+
+// withoutForeignKeys returns copies of the tables without their foreign keys.
+func withoutForeignKeys(tables []schema.Table) []schema.Table {
+	out := make([]schema.Table, len(tables))
+	for i, t := range tables {
+		t.ForeignKeys = nil
+		out[i] = t
+	}
+	return out
+}
+
+// addForeignKeyStatements adds the foreign keys Backup leaves out of the
+// postgres CREATE TABLE statements.
+func addForeignKeyStatements(driver string, tables []schema.Table) []string {
+	if driver != "postgres" {
+		return nil
+	}
+	var out []string
+	for _, t := range tables {
+		for _, fk := range t.ForeignKeys {
+			out = append(out, schema.AddForeignKeySQL(t.Name, fk, driver))
+		}
+	}
+	return out
+}
+
+// resetSequenceStatements moves postgres sequences past the restored ids. mysql
+// and sqlite derive the next auto-increment value from the data themselves.
+func resetSequenceStatements(driver string, tables []schema.Table) []string {
+	if driver != "postgres" {
+		return nil
+	}
+	q := schema.Quoter(driver)
+	var out []string
+	for _, t := range tables {
+		if len(t.PrimaryKey) != 1 {
+			continue
+		}
+		pk := t.PrimaryKey[0]
+		if !isIntegerColumn(t, pk) {
+			continue
+		}
+		// pg_get_serial_sequence returns NULL (and setval is a no-op) when the
+		// column has no sequence.
+		out = append(out, fmt.Sprintf(
+			"SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE((SELECT MAX(%s) FROM %s), 0) + 1, false)",
+			strings.ReplaceAll(q(t.Name), "'", "''"), strings.ReplaceAll(pk, "'", "''"), q(pk), q(t.Name)))
+	}
+	return out
+}
+
+// sequenceValueStatements carries the current value of standalone postgres
+// sequences over; the DDL alone would restart them at START WITH.
+func sequenceValueStatements(db *gorm.DB, m *schema.Model) ([]string, error) {
+	q := schema.Quoter(m.Driver)
+	var out []string
+	for _, s := range m.Sequences {
+		if s.OwnedBy != "" {
+			continue
+		}
+		var last int64
+		var called bool
+		if err := db.Raw("SELECT last_value, is_called FROM "+q(s.Name)).Row().Scan(&last, &called); err != nil {
+			return nil, fmt.Errorf("sequence %s: %w", s.Name, err)
+		}
+		out = append(out, fmt.Sprintf("SELECT setval('%s', %d, %t)", strings.ReplaceAll(q(s.Name), "'", "''"), last, called))
+	}
+	return out, nil
+}
+
+func isIntegerColumn(t schema.Table, name string) bool {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			typ := strings.ToLower(c.Type)
+			return strings.Contains(typ, "int") || strings.Contains(typ, "serial")
+		}
+	}
+	return false
+}
@@ -0,0 +1,254 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"forge/internal/schema"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T, name string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+name+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	return db
+}
+
+func TestBackupSQLite(t *testing.T) {
+	db := openTestDB(t, t.Name())
+	for _, s := range []string{
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, avatar BLOB)`,
+		`CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users (id), title TEXT)`,
+		`INSERT INTO users (id, name, avatar) VALUES (1, 'O''Brien', X'CAFE'), (2, NULL, NULL)`,
+		`INSERT INTO posts (id, user_id, title) VALUES (1, 1, 'hello')`,
+	} {
+		if err := db.Exec(s).Error; err != nil {
+			t.Fatalf("exec %q: %v", s, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	st, err := Backup(db, &buf, BackupOptions{BatchSize: 1})
+	if err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if st.Tables != 2 || st.Rows != 3 {
+		t.Fatalf("stats = %+v", st)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"PRAGMA foreign_keys = OFF;",
+		"CREATE TABLE users",
+		`(1, 'O''Brien', X'cafe')`,
+		"(2, NULL, NULL);",
+		`INSERT INTO "posts" ("id", "user_id", "title") VALUES`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("backup missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, `INSERT INTO "users"`) > strings.Index(out, `INSERT INTO "posts"`) {
+		t.Fatalf("users must be dumped before posts:\n%s", out)
+	}
+
+	// The dump restores into an empty database.
+	restored := openTestDB(t, t.Name()+"_restored")
+	if _, err := Restore(restored, strings.NewReader(out), RestoreOptions{}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if _, err := Restore(restored, strings.NewReader(out), RestoreOptions{}); err == nil {
+		t.Fatal("expected restoring into a non-empty database to fail without Clean")
+	}
+	if _, err := Restore(restored, strings.NewReader(out), RestoreOptions{Clean: true}); err != nil {
+		t.Fatalf("restore --clean: %v", err)
+	}
+	var name string
+	restored.Raw(`SELECT name FROM users WHERE id = 1`).Scan(&name)
+	if name != "O'Brien" {
+		t.Fatalf("restored name = %q", name)
+	}
+}
+
+func TestBackupRestoreTrigger(t *testing.T) {
+	db := openTestDB(t, t.Name())
+	for _, s := range []string{
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)`,
+		`CREATE TABLE audit (id INTEGER PRIMARY KEY, note TEXT)`,
+		`CREATE TRIGGER users_audit AFTER INSERT ON users BEGIN
+			INSERT INTO audit (note) VALUES (CASE WHEN NEW.name IS NULL THEN 'anonymous; user' ELSE NEW.name END);
+		END`,
+		`INSERT INTO users (id, name) VALUES (1, 'ann'), (2, NULL)`,
+	} {
+		if err := db.Exec(s).Error; err != nil {
+			t.Fatalf("exec %q: %v", s, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := Backup(db, &buf, BackupOptions{}); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	out := buf.String()
+	if strings.Index(out, "CREATE TRIGGER") < strings.Index(out, `INSERT INTO "users"`) {
+		t.Fatalf("the trigger must come after the data:\n%s", out)
+	}
+
+	restored := openTestDB(t, t.Name()+"_restored")
+	if _, err := Restore(restored, strings.NewReader(out), RestoreOptions{}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	var audits int64
+	restored.Raw(`SELECT COUNT(*) FROM audit`).Scan(&audits)
+	if audits != 2 {
+		t.Fatalf("audit rows = %d, want 2 (restoring must not fire the trigger)", audits)
+	}
+	// The trigger itself was restored.
+	if err := restored.Exec(`INSERT INTO users (id, name) VALUES (3, 'bob')`).Error; err != nil {
+		t.Fatal(err)
+	}
+	restored.Raw(`SELECT COUNT(*) FROM audit`).Scan(&audits)
+	if audits != 3 {
+		t.Fatalf("audit rows = %d after a new insert, want 3", audits)
+	}
+}
+
+func TestBackupRowOrderAndForeignKeys(t *testing.T) {
+	db := openTestDB(t, t.Name())
+	for _, s := range []string{
+		`CREATE TABLE staff (id INTEGER PRIMARY KEY, boss_id INTEGER REFERENCES staff (id))`,
+		`INSERT INTO staff (id, boss_id) VALUES (2, NULL)`,
+		`INSERT INTO staff (id, boss_id) VALUES (1, 2)`,
+	} {
+		if err := db.Exec(s).Error; err != nil {
+			t.Fatalf("exec %q: %v", s, err)
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := Backup(db, &buf, BackupOptions{}); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "(1, 2),\n  (2, NULL);") {
+		t.Fatalf("rows must be in primary key order:\n%s", out)
+	}
+
+	// postgres gets its foreign keys after the data, cycles included.
+	tables := []schema.Table{
+		{Name: "a", ForeignKeys: []schema.ForeignKey{{Name: "a_b", Columns: []string{"b_id"}, RefTable: "b", RefColumns: []string{"id"}}}},
+		{Name: "b", ForeignKeys: []schema.ForeignKey{{Columns: []string{"a_id"}, RefTable: "a", RefColumns: []string{"id"}}}},
+	}
+	stmts := addForeignKeyStatements("postgres", tables)
+	if len(stmts) != 2 || stmts[0] != `ALTER TABLE "a" ADD CONSTRAINT "a_b" FOREIGN KEY ("b_id") REFERENCES "b" ("id")` {
+		t.Fatalf("statements = %q", stmts)
+	}
+	if len(withoutForeignKeys(tables)[0].ForeignKeys) != 0 || len(tables[0].ForeignKeys) != 1 {
+		t.Fatal("withoutForeignKeys must copy the tables")
+	}
+	if addForeignKeyStatements("sqlite", tables) != nil {
+		t.Fatal("only postgres defers foreign keys")
+	}
+}
+
+func TestStatementReaderTriggerBodies(t *testing.T) {
+	script := `CREATE TRIGGER t1 BEFORE INSERT ON t FOR EACH ROW BEGIN
+  IF NEW.a < 0 THEN SET NEW.a = 0; END IF;
+  CASE NEW.b WHEN 1 THEN SET NEW.c = 'one;'; ELSE SET NEW.c = 'other'; END CASE;
+END;
+CREATE TRIGGER t2 BEFORE UPDATE ON t FOR EACH ROW SET NEW.a = 1;
+BEGIN;
+SELECT 1;
+`
+	sr := newStatementReader(bufio.NewReader(strings.NewReader(script)), "mysql")
+	var got []string
+	for {
+		stmt, err := sr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		got = append(got, stmt)
+	}
+	if len(got) != 4 || !strings.HasSuffix(got[0], "END CASE;\nEND") || got[2] != "BEGIN" {
+		t.Fatalf("statements = %q", got)
+	}
+}
+
+func TestStatementReader(t *testing.T) {
+	script := `-- header; with a semicolon
+CREATE TABLE t (a TEXT); /* block; comment */
+INSERT INTO t VALUES ('x;y'), ('it''s'), ("q;"), ($$not; here$$);
+SELECT $1;;
+`
+	sr := newStatementReader(bufio.NewReader(strings.NewReader(script)), "postgres")
+	var got []string
+	for {
+		stmt, err := sr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		got = append(got, stmt)
+	}
+	want := []string{
+		"CREATE TABLE t (a TEXT)",
+		`INSERT INTO t VALUES ('x;y'), ('it''s'), ("q;"), ($$not; here$$)`,
+		"SELECT $1",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("statements = %q", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("statement %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSQLiteCopyMode(t *testing.T) {
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "src.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE t (id INTEGER PRIMARY KEY); INSERT INTO t VALUES (7)`).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	backup := filepath.Join(dir, "backups", "copy.sqlite")
+	if err := BackupSQLiteFile(db, backup); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if ok, _ := IsSQLiteFile(backup); !ok {
+		t.Fatal("expected a sqlite file")
+	}
+
+	dst := filepath.Join(dir, "dst.db")
+	if err := RestoreSQLiteFile(backup, dst); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	restored, err := gorm.Open(sqlite.Open(dst), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open restored: %v", err)
+	}
+	var id int
+	restored.Raw(`SELECT id FROM t`).Scan(&id)
+	if id != 7 {
+		t.Fatalf("id = %d, want 7", id)
+	}
+}
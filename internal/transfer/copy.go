@@ -0,0 +1,371 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"forge/internal/schema"
+	"forge/internal/seeders"
+
+	"gorm.io/gorm"
+)
+
+// maxBindVars keeps a batch under every driver's bind-parameter limit
+// (sqlite 32766, postgres and mysql 65535).
+const maxBindVars = 30000
+
+// CopyOptions tune Copy.
+type CopyOptions struct {
+	// Tables limits the copy to these tables (default: every table).
+	Tables []string
+	// ChunkSize is the number of rows per INSERT (default 500).
+	ChunkSize int
+	// Truncate empties tables that already exist on the target first.
+	Truncate bool
+	// Progress, when set, is called after each table is copied.
+	Progress func(table string, rows int64)
+	// Anonymize, when set, rewrites personal data on the way. Tables it
+	// truncates are created but receive no rows.
+	Anonymize *seeders.Anonymizer
+}
+
+// CopyStats summarizes a copy.
+type CopyStats struct {
+	Tables  int
+	Rows    int64
+	Created []string // tables created on the target
+	// SkippedFKs lists foreign keys that could not be created on the target.
+	SkippedFKs []string
+}
+
+// Copy copies schema and data from src to dst, which may use different
+// drivers. Missing tables are created on dst with their types mapped to its
+// dialect; existing ones are validated to have every source column. Rows are
+// streamed in foreign-key dependency order with chunked inserts, and postgres
+// sequences are moved past the copied ids afterwards.
+func Copy(src, dst *gorm.DB, opts CopyOptions) (CopyStats, error) {
+	var st CopyStats
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultBatchSize
+	}
+	from, to := src.Dialector.Name(), dst.Dialector.Name()
+
+	srcModel, err := schema.Introspect(src)
+	if err != nil {
+		return st, fmt.Errorf("introspect source: %w", err)
+	}
+	tables, err := selectTables(srcModel, opts.Tables)
+	if err != nil {
+		return st, err
+	}
+	tables = schema.DependencyOrder(tables)
+
+	dstModel, err := schema.Introspect(dst)
+	if err != nil {
+		return st, fmt.Errorf("introspect target: %w", err)
+	}
+
+	// Kinds of the target columns, used to convert values on the way.
+	kinds := map[string]map[string]schema.Kind{}
+	copying := map[string]bool{}
+	for _, t := range tables {
+		copying[t.Name] = true
+	}
+
+	// 1. Schema: validate existing tables, create the missing ones.
+	exists := map[string]bool{}
+	for _, t := range dstModel.Tables {
+		exists[t.Name] = true
+	}
+	var deferred []struct {
+		table string
+		fk    schema.ForeignKey
+	}
+	for _, t := range tables {
+		if dt := dstModel.Table(t.Name); dt != nil {
+			if err := validateTarget(t, *dt); err != nil {
+				return st, err
+			}
+			kinds[t.Name] = columnKinds(*dt, to)
+			continue
+		}
+
+		var inline []schema.ForeignKey
+		for _, fk := range t.ForeignKeys {
+			switch {
+			case fk.RefTable == t.Name || exists[fk.RefTable]:
+				inline = append(inline, fk)
+			case copying[fk.RefTable] && to != "sqlite":
+				// Part of a reference cycle: add it once both tables exist.
+				deferred = append(deferred, struct {
+					table string
+					fk    schema.ForeignKey
+				}{t.Name, fk})
+			default:
+				st.SkippedFKs = append(st.SkippedFKs, fmt.Sprintf("%s(%s) -> %s", t.Name, strings.Join(fk.Columns, ", "), fk.RefTable))
+			}
+		}
+		for _, stmt := range schema.CreateTableSQL(t, from, to, inline) {
+			if err := dst.Exec(stmt).Error; err != nil {
+				return st, fmt.Errorf("create %s: %w\n%s", t.Name, err, stmt)
+			}
+		}
+		exists[t.Name] = true
+		st.Created = append(st.Created, t.Name)
+		kinds[t.Name] = columnKinds(t, from)
+	}
+
+	// 2. Data, on one connection so the foreign-key pragmas hold throughout.
+	err = dst.Connection(func(conn *gorm.DB) error {
+		for _, stmt := range disableFKStatements(to) {
+			if err := conn.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		defer func() {
+			for _, stmt := range enableFKStatements(to) {
+				conn.Exec(stmt)
+			}
+		}()
+
+		if opts.Truncate {
+			for i := len(tables) - 1; i >= 0; i-- {
+				if err := conn.Exec("DELETE FROM " + schema.Quoter(to)(tables[i].Name)).Error; err != nil {
+					return fmt.Errorf("truncate %s: %w", tables[i].Name, err)
+				}
+			}
+		}
+
+		for _, t := range tables {
+			var n int64
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				var err error
+				n, err = copyTableRows(src, tx, t, kinds[t.Name], opts)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("copy %s: %w", t.Name, err)
+			}
+			st.Tables++
+			st.Rows += n
+			if opts.Progress != nil {
+				opts.Progress(t.Name, n)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return st, err
+	}
+
+	// 3. Constraints that had to wait, and sequences.
+	for _, d := range deferred {
+		if err := dst.Exec(schema.AddForeignKeySQL(d.table, d.fk, to)).Error; err != nil {
+			return st, fmt.Errorf("add foreign key on %s: %w", d.table, err)
+		}
+	}
+	for _, stmt := range resetSequenceStatements(to, tables) {
+		if err := dst.Exec(stmt).Error; err != nil {
+			return st, fmt.Errorf("reset sequences: %w", err)
+		}
+	}
+	return st, nil
+}
+
+func selectTables(m *schema.Model, names []string) ([]schema.Table, error) {
+	if len(names) == 0 {
+		return m.Tables, nil
+	}
+	var out []schema.Table
+	for _, n := range names {
+		t := m.Table(n)
+		if t == nil {
+			return nil, fmt.Errorf("table %q not found in the source database", n)
+		}
+		out = append(out, *t)
+	}
+	return out, nil
+}
+
+// validateTarget checks that an existing target table can receive every
+// source column.
+func validateTarget(src, dst schema.Table) error {
+	have := map[string]bool{}
+	for _, c := range dst.Columns {
+		have[c.Name] = true
+	}
+	var missing []string
+	for _, c := range src.Columns {
+		if !have[c.Name] {
+			missing = append(missing, c.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("target table %s is missing column(s): %s", src.Name, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func columnKinds(t schema.Table, driver string) map[string]schema.Kind {
+	out := make(map[string]schema.Kind, len(t.Columns))
+	for _, c := range t.Columns {
+		out[c.Name] = schema.KindOf(c.Type, driver)
+	}
+	return out
+}
+
+// copyTableRows streams the rows of t from src and inserts them into tx in
+// chunks.
+func copyTableRows(src, tx *gorm.DB, t schema.Table, kinds map[string]schema.Kind, opts CopyOptions) (int64, error) {
+	if opts.Anonymize.SkipTable(t.Name) {
+		return 0, nil
+	}
+	chunk := opts.ChunkSize
+	from, to := src.Dialector.Name(), tx.Dialector.Name()
+	cols := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		cols[i] = c.Name
+	}
+	if len(cols) == 0 {
+		return 0, nil
+	}
+	if max := maxBindVars / len(cols); chunk > max {
+		chunk = max
+	}
+
+	qs, qd := schema.Quoter(from), schema.Quoter(to)
+	srcCols := make([]string, len(cols))
+	dstCols := make([]string, len(cols))
+	for i, c := range cols {
+		srcCols[i] = qs(c)
+		dstCols[i] = qd(c)
+	}
+	scan := "FROM " + qs(t.Name)
+	if len(t.PrimaryKey) > 0 {
+		// A stable order keeps shuffled columns reproducible.
+		pk := make([]string, len(t.PrimaryKey))
+		for i, c := range t.PrimaryKey {
+			pk[i] = qs(c)
+		}
+		scan += " ORDER BY " + strings.Join(pk, ", ")
+	}
+	if err := opts.Anonymize.Prepare(src, t.Name, scan); err != nil {
+		return 0, err
+	}
+	rows, err := src.Raw(fmt.Sprintf("SELECT %s %s", strings.Join(srcCols, ", "), scan)).Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	head := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", qd(t.Name), strings.Join(dstCols, ", "))
+	flush := func(args []any, n int) error {
+		if n == 0 {
+			return nil
+		}
+		_, err := tx.Statement.ConnPool.ExecContext(context.Background(), head+placeholders(to, len(cols), n), args...)
+		return err
+	}
+
+	vals := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	args := make([]any, 0, chunk*len(cols))
+	var total int64
+	n := 0
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return total, err
+		}
+		if err := opts.Anonymize.Apply(t.Name, cols, vals, total); err != nil {
+			return total, err
+		}
+		for i, v := range vals {
+			args = append(args, convertValue(v, kinds[cols[i]]))
+		}
+		n++
+		total++
+		if n == chunk {
+			if err := flush(args, n); err != nil {
+				return total, err
+			}
+			args, n = args[:0], 0
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return total, err
+	}
+	return total, flush(args, n)
+}
+
+// placeholders renders n row tuples of width bind parameters.
+func placeholders(driver string, width, n int) string {
+	var b strings.Builder
+	arg := 1
+	for r := 0; r < n; r++ {
+		if r > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for c := 0; c < width; c++ {
+			if c > 0 {
+				b.WriteString(", ")
+			}
+			if driver == "postgres" {
+				b.WriteString("$" + strconv.Itoa(arg))
+			} else {
+				b.WriteByte('?')
+			}
+			arg++
+		}
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// convertValue adapts a scanned source value to the target column's kind.
+// Drivers disagree on representations: mysql returns most values as []byte,
+// sqlite stores booleans as integers.
+func convertValue(v any, kind schema.Kind) any {
+	if b, ok := v.([]byte); ok {
+		if kind == schema.KindBinary {
+			return b
+		}
+		v = string(b)
+	}
+	switch x := v.(type) {
+	case string:
+		switch kind {
+		case schema.KindInt:
+			if n, err := strconv.ParseInt(x, 10, 64); err == nil {
+				return n
+			}
+		case schema.KindBool:
+			if b, err := strconv.ParseBool(x); err == nil {
+				return b
+			}
+		case schema.KindFloat:
+			if f, err := strconv.ParseFloat(x, 64); err == nil {
+				return f
+			}
+		case schema.KindBinary:
+			return []byte(x)
+		}
+	case int64:
+		if kind == schema.KindBool {
+			return x != 0
+		}
+	case bool:
+		if kind == schema.KindInt {
+			if x {
+				return int64(1)
+			}
+			return int64(0)
+		}
+	}
+	return v
+}
@@ -0,0 +1,107 @@
+package transfer
+
+import (
+	"strings"
+	"testing"
+
+	"forge/internal/schema"
+	"forge/internal/seeders"
+)
+
+func TestCopy(t *testing.T) {
+	src := openTestDB(t, t.Name()+"_src")
+	for _, s := range []string{
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, email VARCHAR(100) NOT NULL, active BOOLEAN DEFAULT 1)`,
+		`CREATE UNIQUE INDEX ux_users_email ON users (email)`,
+		`CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL REFERENCES users (id), title TEXT)`,
+		`INSERT INTO users (id, email, active) VALUES (1, 'a@x.io', 1), (2, 'b@x.io', 0), (3, 'c@x.io', 1)`,
+		`INSERT INTO posts (id, user_id, title) VALUES (10, 1, 'one'), (11, 3, 'two')`,
+	} {
+		if err := src.Exec(s).Error; err != nil {
+			t.Fatalf("exec %q: %v", s, err)
+		}
+	}
+
+	dst := openTestDB(t, t.Name()+"_dst")
+	copied := map[string]int64{}
+	st, err := Copy(src, dst, CopyOptions{ChunkSize: 2, Progress: func(table string, n int64) { copied[table] = n }})
+	if err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if st.Rows != 5 || len(st.Created) != 2 || copied["users"] != 3 || copied["posts"] != 2 {
+		t.Fatalf("stats = %+v, progress = %v", st, copied)
+	}
+
+	m, err := schema.Introspect(dst)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	posts := m.Table("posts")
+	if posts == nil || len(posts.ForeignKeys) != 1 || posts.ForeignKeys[0].RefTable != "users" {
+		t.Fatalf("posts foreign key not recreated: %+v", posts)
+	}
+	if users := m.Table("users"); users == nil || len(users.Indexes) != 1 || !users.Indexes[0].Unique {
+		t.Fatalf("users index not recreated: %+v", users)
+	}
+
+	// Copying again conflicts unless the target is truncated first.
+	if _, err := Copy(src, dst, CopyOptions{}); err == nil {
+		t.Fatal("expected duplicate keys without Truncate")
+	}
+	if _, err := Copy(src, dst, CopyOptions{Truncate: true, Tables: []string{"users", "posts"}}); err != nil {
+		t.Fatalf("copy --truncate: %v", err)
+	}
+	var count int64
+	dst.Raw(`SELECT COUNT(*) FROM posts`).Scan(&count)
+	if count != 2 {
+		t.Fatalf("posts = %d, want 2", count)
+	}
+
+	if _, err := Copy(src, dst, CopyOptions{Tables: []string{"missing"}}); err == nil {
+		t.Fatal("expected an error for an unknown table")
+	}
+}
+
+func TestCopyAnonymized(t *testing.T) {
+	src := openTestDB(t, t.Name()+"_src")
+	for _, s := range []string{
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT, city TEXT)`,
+		`CREATE TABLE sessions (id INTEGER PRIMARY KEY, token TEXT)`,
+		`INSERT INTO users VALUES (1, 'a@corp.com', 'Oslo'), (2, 'b@corp.com', 'Rome'), (3, NULL, 'Lima')`,
+		`INSERT INTO sessions VALUES (1, 'secret')`,
+	} {
+		if err := src.Exec(s).Error; err != nil {
+			t.Fatalf("exec %q: %v", s, err)
+		}
+	}
+	anon, err := seeders.ParseAnonymizer([]byte("salt: x\ntables:\n  users: {email: 'fake:email', city: shuffle}\n  sessions: truncate\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := openTestDB(t, t.Name()+"_dst")
+	if _, err := Copy(src, dst, CopyOptions{Anonymize: anon}); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	var sessions int64
+	dst.Raw(`SELECT COUNT(*) FROM sessions`).Scan(&sessions)
+	if sessions != 0 {
+		t.Fatalf("sessions = %d, want 0", sessions)
+	}
+	type user struct {
+		Email *string
+		City  string
+	}
+	var users []user
+	dst.Raw(`SELECT email, city FROM users ORDER BY id`).Scan(&users)
+	cities := map[string]bool{}
+	for _, u := range users {
+		if u.Email != nil && strings.HasSuffix(*u.Email, "@corp.com") {
+			t.Fatalf("email leaked: %s", *u.Email)
+		}
+		cities[u.City] = true
+	}
+	if len(users) != 3 || users[2].Email != nil || !cities["Oslo"] || !cities["Rome"] || !cities["Lima"] {
+		t.Fatalf("unexpected rows: %+v", users)
+	}
+}
@@ -0,0 +1,84 @@
+package transfer
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sqlLiteral renders a scanned value as a SQL literal for driver. dbType is
+// the column's database type name, used to tell binary columns from text ones
+// (drivers return both as []byte).
+func sqlLiteral(driver string, v any, dbType string) string {
+	switch x := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if driver == "postgres" {
+			return strconv.FormatBool(x)
+		}
+		if x {
+			return "1"
+		}
+		return "0"
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case int32:
+		return strconv.FormatInt(int64(x), 10)
+	case int:
+		return strconv.Itoa(x)
+	case uint64:
+		return strconv.FormatUint(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'g', -1, 32)
+	case time.Time:
+		if driver == "mysql" {
+			// DATETIME literals carry no zone on older servers.
+			return quoteString(driver, x.UTC().Format("2006-01-02 15:04:05.999999"))
+		}
+		return quoteString(driver, x.Format("2006-01-02 15:04:05.999999999-07:00"))
+	case []byte:
+		if isBinaryType(dbType) {
+			return binaryLiteral(driver, x)
+		}
+		return quoteString(driver, string(x))
+	case string:
+		return quoteString(driver, x)
+	default:
+		return quoteString(driver, fmt.Sprint(x))
+	}
+}
+
+func isBinaryType(dbType string) bool {
+	t := strings.ToUpper(dbType)
+	return t == "BLOB" || t == "BYTEA" || strings.HasSuffix(t, "BINARY") || strings.HasSuffix(t, "BLOB")
+}
+
+func binaryLiteral(driver string, b []byte) string {
+	h := hex.EncodeToString(b)
+	switch driver {
+	case "postgres":
+		return "'\\x" + h + "'::bytea"
+	case "mysql":
+		if len(b) == 0 {
+			return "''"
+		}
+		return "0x" + h
+	default:
+		return "X'" + h + "'"
+	}
+}
+
+// quoteString quotes s as a string literal. mysql also treats backslashes as
+// escapes inside literals.
+func quoteString(driver, s string) string {
+	s = strings.ReplaceAll(s, "'", "''")
+	if driver == "mysql" {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + s + "'"
+}
@@ -0,0 +1,184 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"forge/internal/schema"
+
+	"gorm.io/gorm"
+)
+
+// sqliteMagic starts every sqlite database file.
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+// RestoreOptions tune Restore.
+type RestoreOptions struct {
+	// Clean drops every table before restoring. Without it the target
+	// database must be empty.
+	Clean bool
+}
+
+// RestoreStats summarizes a restore.
+type RestoreStats struct {
+	Driver     string // driver recorded in the backup header, if any
+	Statements int
+}
+
+// Restore replays a backup written by Backup. Statements are read one at a
+// time, so the file is never loaded into memory. On postgres the whole restore
+// runs in one transaction; sqlite and mysql use a single connection so the
+// foreign-key pragmas of the backup apply to every statement.
+func Restore(db *gorm.DB, r io.Reader, opts RestoreOptions) (RestoreStats, error) {
+	var st RestoreStats
+	driver := db.Dialector.Name()
+
+	br := bufio.NewReaderSize(r, 64*1024)
+	st.Driver = headerDriver(br)
+	if st.Driver != "" && st.Driver != driver {
+		return st, fmt.Errorf("backup was taken from %s and cannot be restored into %s (use `forge db copy` to move data across drivers)", st.Driver, driver)
+	}
+
+	if err := prepareTarget(db, opts.Clean); err != nil {
+		return st, err
+	}
+
+	run := func(tx *gorm.DB) error {
+		sr := newStatementReader(br, driver)
+		for {
+			stmt, err := sr.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("statement %d: %w\n%s", st.Statements+1, err, abbreviate(stmt))
+			}
+			st.Statements++
+		}
+	}
+	if driver == "postgres" {
+		return st, db.Transaction(run)
+	}
+	return st, db.Connection(run)
+}
+
+// RestoreFile restores the backup at path.
+func RestoreFile(db *gorm.DB, path string, opts RestoreOptions) (RestoreStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return RestoreStats{}, err
+	}
+	defer f.Close()
+	return Restore(db, f, opts)
+}
+
+// prepareTarget drops every table (clean) or makes sure there is nothing a
+// restore would collide with.
+func prepareTarget(db *gorm.DB, clean bool) error {
+	if clean {
+		return schema.DropAllTables(db)
+	}
+	m, err := schema.Introspect(db)
+	if err != nil {
+		return err
+	}
+	if len(m.Tables) > 0 {
+		return fmt.Errorf("target database is not empty (%d tables); pass --clean to drop them first", len(m.Tables))
+	}
+	return nil
+}
+
+// headerDriver reads the "-- driver:" line of a Forge backup header without
+// consuming the input.
+func headerDriver(br *bufio.Reader) string {
+	head, _ := br.Peek(512)
+	for _, line := range strings.Split(string(head), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "--") {
+			break
+		}
+		if v, ok := strings.CutPrefix(line, "-- driver:"); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+func abbreviate(stmt string) string {
+	if len(stmt) > 200 {
+		return stmt[:200] + "..."
+	}
+	return stmt
+}
+
+// IsSQLiteFile reports whether path is a raw sqlite database file (a copy-mode
+// backup) rather than an SQL script.
+func IsSQLiteFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	head := make([]byte, len(sqliteMagic))
+	if _, err := io.ReadFull(f, head); err != nil {
+		return false, nil
+	}
+	return bytes.Equal(head, sqliteMagic), nil
+}
+
+// BackupSQLiteFile writes a consistent copy of a sqlite database to path with
+// VACUUM INTO. It is much faster than an SQL backup and keeps everything
+// sqlite knows about (triggers, views, pragmas).
+func BackupSQLiteFile(db *gorm.DB, path string) error {
+	if db.Dialector.Name() != "sqlite" {
+		return fmt.Errorf("copy mode is only available for sqlite (got %s)", db.Dialector.Name())
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return db.Exec("VACUUM INTO ?", path).Error
+}
+
+// RestoreSQLiteFile replaces the sqlite database file dst with the copy-mode
+// backup src. The copy goes through a temporary file so dst is never left
+// half-written. Close every connection to dst before calling it.
+func RestoreSQLiteFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".forge-restore-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// Stale journal files would be replayed on top of the restored file.
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		_ = os.Remove(dst + suffix)
+	}
+	return os.Rename(tmp.Name(), dst)
+}
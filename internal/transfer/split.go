@@ -0,0 +1,247 @@
+package transfer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+// statementReader splits an SQL script into statements on top-level
+// semicolons. It understands quoted strings and identifiers, line and block
+// comments, postgres dollar quoting and the BEGIN … END body of a CREATE
+// TRIGGER (sqlite, mysql), so semicolons inside them do not end a statement.
+// Comments outside statements are dropped.
+type statementReader struct {
+	r      *bufio.Reader
+	driver string
+
+	// Bare words of the current statement, to recognize CREATE TRIGGER and
+	// track the nesting of its body.
+	word    strings.Builder
+	words   int
+	create  bool
+	trigger bool
+	depth   int
+	prevEnd bool // the last word was END; END IF/LOOP/... close nothing
+}
+
+func newStatementReader(r *bufio.Reader, driver string) *statementReader {
+	return &statementReader{r: r, driver: driver}
+}
+
+// Next returns the next non-empty statement without its trailing semicolon, or
+// io.EOF when the script is exhausted.
+func (s *statementReader) Next() (string, error) {
+	var b strings.Builder
+	s.words, s.create, s.trigger, s.depth, s.prevEnd = 0, false, false, 0, false
+	s.word.Reset()
+	for {
+		c, _, err := s.r.ReadRune()
+		if errors.Is(err, io.EOF) {
+			if stmt := strings.TrimSpace(b.String()); stmt != "" {
+				return stmt, nil
+			}
+			return "", io.EOF
+		}
+		if err != nil {
+			return "", err
+		}
+		if isWordRune(c) {
+			s.word.WriteRune(c)
+			b.WriteRune(c)
+			continue
+		}
+		s.endWord()
+
+		switch c {
+		case ';':
+			if s.depth > 0 {
+				b.WriteRune(c)
+				s.prevEnd = false
+				continue
+			}
+			if stmt := strings.TrimSpace(b.String()); stmt != "" {
+				return stmt, nil
+			}
+			b.Reset()
+			s.words, s.create, s.trigger = 0, false, false
+		case '\'', '"', '`':
+			b.WriteRune(c)
+			if err := s.copyQuoted(&b, c); err != nil {
+				return "", err
+			}
+		case '-':
+			if s.peekIs('-') {
+				if err := s.skipLine(); err != nil {
+					return "", err
+				}
+				b.WriteByte('\n')
+				continue
+			}
+			b.WriteRune(c)
+		case '/':
+			if s.peekIs('*') {
+				if err := s.skipBlockComment(); err != nil {
+					return "", err
+				}
+				b.WriteByte(' ')
+				continue
+			}
+			b.WriteRune(c)
+		case '$':
+			b.WriteRune(c)
+			if s.driver == "postgres" {
+				if err := s.copyDollarQuoted(&b); err != nil {
+					return "", err
+				}
+			}
+		default:
+			b.WriteRune(c)
+		}
+	}
+}
+
+func isWordRune(c rune) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// endWord handles the bare word just read. A statement starting with CREATE
+// and naming TRIGGER among its first words is a trigger; in its body BEGIN and
+// CASE open a block and END closes one, except END IF/LOOP/WHILE/REPEAT,
+// which close mysql control flow opened without a counted word.
+func (s *statementReader) endWord() {
+	if s.word.Len() == 0 {
+		return
+	}
+	w := strings.ToUpper(s.word.String())
+	s.word.Reset()
+	s.words++
+	if !s.trigger {
+		if s.words == 1 {
+			s.create = w == "CREATE"
+		} else if s.create && s.words <= 6 && w == "TRIGGER" {
+			s.trigger = true
+		}
+		return
+	}
+
+	prevEnd := s.prevEnd
+	s.prevEnd = false
+	switch w {
+	case "BEGIN":
+		s.depth++
+	case "CASE":
+		if !prevEnd {
+			s.depth++
+		}
+	case "IF", "LOOP", "WHILE", "REPEAT":
+		if prevEnd {
+			s.depth++ // undo the END: it closed a control-flow block
+		}
+	case "END":
+		s.depth--
+		s.prevEnd = true
+	}
+}
+
+func (s *statementReader) peekIs(c byte) bool {
+	next, err := s.r.Peek(1)
+	return err == nil && next[0] == c
+}
+
+// copyQuoted copies up to and including the closing quote. A doubled quote is
+// an escaped quote; mysql also escapes with backslashes inside strings.
+func (s *statementReader) copyQuoted(b *strings.Builder, quote rune) error {
+	for {
+		c, _, err := s.r.ReadRune()
+		if err != nil {
+			return unterminated(err)
+		}
+		b.WriteRune(c)
+		if c == '\\' && quote != '`' && s.driver == "mysql" {
+			n, _, err := s.r.ReadRune()
+			if err != nil {
+				return unterminated(err)
+			}
+			b.WriteRune(n)
+			continue
+		}
+		if c == quote {
+			if s.peekIs(byte(quote)) {
+				n, _, _ := s.r.ReadRune()
+				b.WriteRune(n)
+				continue
+			}
+			return nil
+		}
+	}
+}
+
+// copyDollarQuoted handles $tag$ ... $tag$ bodies. The leading '$' has already
+// been written; positional parameters like $1 are copied unchanged.
+func (s *statementReader) copyDollarQuoted(b *strings.Builder) error {
+	var tag strings.Builder
+	for {
+		next, err := s.r.Peek(1)
+		if err != nil {
+			return nil
+		}
+		c := next[0]
+		if c == '$' {
+			s.r.ReadByte()
+			break
+		}
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || tag.Len() > 0 && c >= '0' && c <= '9') {
+			return nil
+		}
+		s.r.ReadByte()
+		tag.WriteByte(c)
+		b.WriteByte(c)
+	}
+	b.WriteByte('$')
+
+	end := "$" + tag.String() + "$"
+	var body strings.Builder
+	for {
+		c, _, err := s.r.ReadRune()
+		if err != nil {
+			return unterminated(err)
+		}
+		b.WriteRune(c)
+		body.WriteRune(c)
+		if c == '$' && strings.HasSuffix(body.String(), end) {
+			return nil
+		}
+	}
+}
+
+func (s *statementReader) skipLine() error {
+	_, err := s.r.ReadString('\n')
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+func (s *statementReader) skipBlockComment() error {
+	s.r.ReadByte() // '*'
+	prev := rune(0)
+	for {
+		c, _, err := s.r.ReadRune()
+		if err != nil {
+			return unterminated(err)
+		}
+		if prev == '*' && c == '/' {
+			return nil
+		}
+		prev = c
+	}
+}
+
+func unterminated(err error) error {
+	if errors.Is(err, io.EOF) {
+		return errors.New("unexpected end of script inside a quoted string or comment")
+	}
+	return err
+}